@@ -33,6 +33,7 @@ import (
 
 	"github.com/haakenlabs/forge/internal/engine"
 
+	"github.com/haakenlabs/forge/cmd/forge/pack"
 	"github.com/haakenlabs/forge/cmd/forge/scene"
 )
 
@@ -101,6 +102,14 @@ func main() {
 	// Parse cli arguments.
 	parseArgs()
 
+	// Run the pack command instead of the app if requested.
+	if flag.Arg(0) == "pack" {
+		if err := pack.Run(flag.Args()[1:]); err != nil {
+			logrus.Fatal(err)
+		}
+		return
+	}
+
 	// Make the app.
 	app := makeApp()
 
@@ -0,0 +1,315 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+// Package pack implements the forge pack command, which builds, lists and
+// verifies asset packages.
+package pack
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/haakenlabs/forge/internal/engine"
+)
+
+const usage = `usage: forge pack <command> [arguments]
+
+commands:
+  build [-manifest file] [-o file] <dir>   build a package from a directory
+  list <file>                              list the contents of a package
+  verify [-manifest file] <file>           verify the assets of a package`
+
+// ErrUsage reports that the command was invoked incorrectly.
+var ErrUsage = errors.New(usage)
+
+// packageTime is the modification time recorded for every file in a package,
+// so that building the same directory always produces the same package.
+var packageTime = time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Run runs the pack command with the given arguments.
+func Run(args []string) error {
+	if len(args) == 0 {
+		return ErrUsage
+	}
+
+	switch args[0] {
+	case "build":
+		return runBuild(args[1:])
+	case "list":
+		return runList(args[1:], os.Stdout)
+	case "verify":
+		return runVerify(args[1:], os.Stdout)
+	default:
+		return ErrUsage
+	}
+}
+
+func runBuild(args []string) error {
+	fs := flag.NewFlagSet("build", flag.ContinueOnError)
+	manifest := fs.String("manifest", "manifest.json", "manifest file, relative to the directory")
+	output := fs.String("o", "", "output file (default assets/<dir>.pkg)")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return ErrUsage
+	}
+
+	dir := fs.Arg(0)
+	if *output == "" {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return err
+		}
+
+		*output = filepath.Join("assets", filepath.Base(abs)+".pkg")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(*output), 0755); err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(*output), ".pack")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := Build(tmp, dir, *manifest); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), *output)
+}
+
+// Build writes a package containing every file in dir. The manifest, given
+// relative to dir, must exist and every asset it lists must be present. Files
+// are written in sorted order with fixed metadata, so the output only depends
+// on the contents of dir.
+func Build(w io.Writer, dir, manifest string) error {
+	m, err := readManifest(filepath.Join(dir, manifest))
+	if err != nil {
+		return err
+	}
+
+	files, err := packageFiles(dir)
+	if err != nil {
+		return err
+	}
+
+	present := make(map[string]bool, len(files))
+	for _, f := range files {
+		present[f] = true
+	}
+
+	manifestDir := filepath.ToSlash(filepath.Dir(filepath.Clean(manifest)))
+	for kind, names := range m.Assets {
+		for _, name := range names {
+			p := filepath.ToSlash(filepath.Clean(filepath.Join(manifestDir, name)))
+			if !present[p] {
+				return fmt.Errorf("pack: %s asset not found: %s", kind, name)
+			}
+		}
+	}
+
+	zw := zip.NewWriter(w)
+
+	for _, f := range files {
+		fw, err := zw.CreateHeader(&zip.FileHeader{
+			Name:     f,
+			Method:   zip.Deflate,
+			Modified: packageTime,
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := copyFile(fw, filepath.Join(dir, filepath.FromSlash(f))); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+// packageFiles returns the sorted, slash separated paths of all regular files
+// in dir. Hidden files and directories are skipped.
+func packageFiles(dir string) ([]string, error) {
+	var files []string
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		if rel != "." && strings.HasPrefix(info.Name(), ".") {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if info.Mode().IsRegular() {
+			files = append(files, filepath.ToSlash(rel))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(files)
+
+	return files, nil
+}
+
+func copyFile(w io.Writer, filename string) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(w, f)
+
+	return err
+}
+
+func readManifest(filename string) (*engine.AssetManifest, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	m := engine.NewAssetManifest()
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("pack: %s: %v", filename, err)
+	}
+
+	return m, nil
+}
+
+func runList(args []string, out io.Writer) error {
+	if len(args) != 1 {
+		return ErrUsage
+	}
+
+	r, err := zip.OpenReader(args[0])
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	tw := tabwriter.NewWriter(out, 0, 8, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "Size\tCompressed\tCRC32\t Name")
+
+	var size, compressed uint64
+	for _, f := range r.File {
+		fmt.Fprintf(tw, "%d\t%d\t%08x\t %s\n", f.UncompressedSize64, f.CompressedSize64, f.CRC32, f.Name)
+
+		size += f.UncompressedSize64
+		compressed += f.CompressedSize64
+	}
+
+	fmt.Fprintf(tw, "%d\t%d\t\t %d files\n", size, compressed, len(r.File))
+
+	return tw.Flush()
+}
+
+func runVerify(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("verify", flag.ContinueOnError)
+	manifest := fs.String("manifest", "manifest.json", "manifest file within the package")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return ErrUsage
+	}
+
+	failed, err := Verify(fs.Arg(0), *manifest, out)
+	if err != nil {
+		return err
+	}
+	if failed != 0 {
+		return fmt.Errorf("pack: %d assets failed verification", failed)
+	}
+
+	return nil
+}
+
+// Verify mounts the package and checks that every asset in its manifest can
+// be read and decoded by its registered asset handler. The result for each
+// asset is written to out, and the number of failures is returned.
+func Verify(filename, manifest string, out io.Writer) (int, error) {
+	a := engine.NewApp(&engine.AppConfig{
+		Name:     "forge-pack",
+		Headless: true,
+	})
+
+	if err := a.Setup(); err != nil {
+		return 0, err
+	}
+	defer a.Teardown()
+
+	asset := engine.GetAsset()
+	if err := asset.MountPackageFile(filename); err != nil {
+		return 0, err
+	}
+
+	name := strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+	failed := 0
+
+	err := asset.VerifyManifest(name+":"+manifest, func(kind, file string, err error) {
+		if err != nil {
+			failed++
+			fmt.Fprintf(out, "FAIL\t%s\t%s: %v\n", kind, file, err)
+			return
+		}
+
+		fmt.Fprintf(out, "ok\t%s\t%s\n", kind, file)
+	})
+
+	return failed, err
+}
@@ -0,0 +1,147 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package pack
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTestPackageDir(t *testing.T, manifest string) string {
+	dir, err := ioutil.TempDir("", "pack")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img := &bytes.Buffer{}
+	if err := png.Encode(img, image.NewRGBA(image.Rect(0, 0, 2, 2))); err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string][]byte{
+		"manifest.json":       []byte(manifest),
+		"textures/good.png":   img.Bytes(),
+		"textures/broken.png": []byte("not an image"),
+		".hidden":             []byte("skipped"),
+	}
+
+	for name, data := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(p, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+func TestBuild(t *testing.T) {
+	dir := writeTestPackageDir(t, `{"assets": {"image": ["textures/good.png"]}}`)
+	defer os.RemoveAll(dir)
+
+	a := &bytes.Buffer{}
+	if err := Build(a, dir, "manifest.json"); err != nil {
+		t.Fatal(err)
+	}
+
+	b := &bytes.Buffer{}
+	if err := Build(b, dir, "manifest.json"); err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(a.Bytes(), b.Bytes()) {
+		t.Error("expected identical packages")
+	}
+
+	files, err := packageFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"manifest.json", "textures/broken.png", "textures/good.png"}
+	if len(files) != len(expected) {
+		t.Fatalf("expected files %v, got: %v", expected, files)
+	}
+	for i := range expected {
+		if files[i] != expected[i] {
+			t.Errorf("expected files %v, got: %v", expected, files)
+		}
+	}
+}
+
+func TestBuild_MissingAsset(t *testing.T) {
+	dir := writeTestPackageDir(t, `{"assets": {"image": ["textures/missing.png"]}}`)
+	defer os.RemoveAll(dir)
+
+	if err := Build(ioutil.Discard, dir, "manifest.json"); err == nil {
+		t.Error("expected error for missing asset")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	dir := writeTestPackageDir(t, `{"assets": {"image": ["textures/good.png", "textures/broken.png"]}}`)
+	defer os.RemoveAll(dir)
+
+	out, err := ioutil.TempDir("", "pack")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(out)
+
+	pkg := filepath.Join(out, "testpkg.pkg")
+
+	f, err := os.Create(pkg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Build(f, dir, "manifest.json"); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	list := &bytes.Buffer{}
+	if err := runList([]string{pkg}, list); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(list.Bytes(), []byte("textures/good.png")) {
+		t.Errorf("list missing textures/good.png:\n%s", list)
+	}
+
+	report := &bytes.Buffer{}
+	failed, err := Verify(pkg, "manifest.json", report)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if failed != 1 {
+		t.Errorf("expected 1 failure, got: %d\n%s", failed, report)
+	}
+}
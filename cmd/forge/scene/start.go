@@ -52,6 +52,11 @@ type Inspector struct {
 	show bool
 }
 
+// Awake defines the action which toggles the inspector.
+func (i *Inspector) Awake() {
+	input.DefineAction("ToggleInspector", engine.KeyBinding(glfw.KeyF1))
+}
+
 func (i *Inspector) LateUpdate() {
 	if i.show {
 		i.labelStartLifetime.SetValue(fmt.Sprintf("Start Lifetime: %.0f", i.psys.Core.StartLifetime))
@@ -61,7 +66,7 @@ func (i *Inspector) LateUpdate() {
 		i.labelCurParticles.SetValue(fmt.Sprintf("Particle Count: %d", i.psys.Core.ParticleCount()))
 	}
 
-	if input.KeyDown(glfw.KeyF1) {
+	if input.Pressed("ToggleInspector") {
 		if i.show {
 			i.uiObject.SetActive(false)
 			i.show = false
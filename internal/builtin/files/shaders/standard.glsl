@@ -2,6 +2,10 @@
 layout(location = 0) in vec3 vertex;
 layout(location = 1) in vec3 normal;
 layout(location = 2) in vec2 uv;
+#ifdef SKINNED
+layout(location = 3) in uvec4 joints;
+layout(location = 4) in vec4 weights;
+#endif
 
 out vec3 vo_position;
 out vec3 vo_normal;
@@ -16,15 +20,34 @@ uniform mat4 v_view_matrix;
 uniform mat4 v_model_matrix;
 uniform mat3 v_normal_matrix;
 
+#ifdef SKINNED
+#define MAX_SKIN_JOINTS 64
+
+uniform mat4 v_joint_matrices[MAX_SKIN_JOINTS];
+#endif
+
 void main()
 {
+    vec3 position = vertex;
+    vec3 n = normal;
+
+#ifdef SKINNED
+    mat4 skin = weights.x * v_joint_matrices[joints.x]
+              + weights.y * v_joint_matrices[joints.y]
+              + weights.z * v_joint_matrices[joints.z]
+              + weights.w * v_joint_matrices[joints.w];
+
+    position = vec3(skin * vec4(vertex, 1.0));
+    n = normalize(mat3(skin) * normal);
+#endif
+
     vo_texture = uv;
-    vo_normal = normal;// normalize(v_normal_matrix * normal);
-    vo_position = vertex;
-    vo_ws_position = vec3(v_model_matrix * vec4(vertex, 1.0));
-    vo_ws_normal = vec3(v_model_matrix * vec4(normal, 1.0));
+    vo_normal = n;// normalize(v_normal_matrix * n);
+    vo_position = position;
+    vo_ws_position = vec3(v_model_matrix * vec4(position, 1.0));
+    vo_ws_normal = vec3(v_model_matrix * vec4(n, 1.0));
 
-    gl_Position = v_projection_matrix * v_view_matrix * v_model_matrix * vec4(vertex, 1.0);
+    gl_Position = v_projection_matrix * v_view_matrix * v_model_matrix * vec4(position, 1.0);
 }
 
 #endif
@@ -3,5 +3,8 @@
     "deferred": true,
     "files": [
         "standard.glsl"
-    ]
+    ],
+    "variants": {
+        "skinned": ["SKINNED"]
+    }
 }
@@ -0,0 +1,271 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"sort"
+
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/haakenlabs/forge/internal/math"
+)
+
+// Interpolation is the way a curve moves from a keyframe to the next one.
+// Besides the constants, the name of any easing function of the math package
+// may be used, such as "InOutQuad", which eases between the key values.
+type Interpolation string
+
+const (
+	InterpolationLinear Interpolation = "linear"
+	InterpolationStep   Interpolation = "step"
+	InterpolationBezier Interpolation = "bezier"
+)
+
+// ErrAnimationInterpolation reports an unknown interpolation name.
+type ErrAnimationInterpolation string
+
+func (e ErrAnimationInterpolation) Error() string {
+	return "animation: unknown interpolation: " + string(e)
+}
+
+// Valid reports if the interpolation is empty, one of the constants, or the
+// name of an easing function.
+func (i Interpolation) Valid() bool {
+	switch i {
+	case "", InterpolationLinear, InterpolationStep, InterpolationBezier:
+		return true
+	}
+
+	_, ok := math.Easing(string(i))
+
+	return ok
+}
+
+// Transform properties which may be animated. Positions and scales have three
+// values, and rotations are quaternions stored as x, y, z, w.
+const (
+	AnimationPosition = "position"
+	AnimationRotation = "rotation"
+	AnimationScale    = "scale"
+)
+
+// Keyframe is the value of a curve at a point in time, in seconds.
+// Interpolation applies to the segment up to the next keyframe, and defaults
+// to linear. Bezier segments are shaped by the out tangent of the keyframe and
+// the in tangent of the next keyframe, in units per second; missing tangents
+// are zero.
+type Keyframe struct {
+	Time          float64       `json:"time" yaml:"time"`
+	Value         []float32     `json:"value" yaml:"value"`
+	Interpolation Interpolation `json:"interpolation,omitempty" yaml:"interpolation,omitempty"`
+	InTangent     []float32     `json:"inTangent,omitempty" yaml:"inTangent,omitempty"`
+	OutTangent    []float32     `json:"outTangent,omitempty" yaml:"outTangent,omitempty"`
+}
+
+// AnimationCurve animates a property of a game object. Path is the slash
+// separated path of names from the animated game object to the target, or
+// empty for the animated game object itself. Component is the registered
+// component type name, or empty for the transform. Property is a transform
+// property, or the name of a float or float array field of the component.
+type AnimationCurve struct {
+	Path      string     `json:"path,omitempty" yaml:"path,omitempty"`
+	Component string     `json:"component,omitempty" yaml:"component,omitempty"`
+	Property  string     `json:"property" yaml:"property"`
+	Keys      []Keyframe `json:"keys" yaml:"keys"`
+}
+
+// AnimationClip is a set of curves played together by an Animator.
+type AnimationClip struct {
+	BaseObject
+
+	curves []AnimationCurve
+	length float64
+}
+
+// AnimationClipDocument is the serialized form of an AnimationClip.
+type AnimationClipDocument struct {
+	Curves []AnimationCurve `json:"curves" yaml:"curves"`
+}
+
+// Validate checks that the interpolations of the keyframes are known.
+func (d *AnimationClipDocument) Validate() error {
+	for _, c := range d.Curves {
+		for _, k := range c.Keys {
+			if !k.Interpolation.Valid() {
+				return ErrAnimationInterpolation(k.Interpolation)
+			}
+		}
+	}
+
+	return nil
+}
+
+// NewAnimationClip creates a new clip from the curves. The keys of each curve
+// are sorted by time.
+func NewAnimationClip(curves []AnimationCurve) *AnimationClip {
+	c := &AnimationClip{}
+
+	c.SetName("AnimationClip")
+	GetInstance().MustAssign(c)
+
+	c.SetCurves(curves)
+
+	return c
+}
+
+// Curves returns the curves of the clip.
+func (c *AnimationClip) Curves() []AnimationCurve {
+	return c.curves
+}
+
+// SetCurves replaces the curves of the clip.
+func (c *AnimationClip) SetCurves(curves []AnimationCurve) {
+	c.curves = curves
+	c.length = 0
+
+	for i := range c.curves {
+		keys := c.curves[i].Keys
+		sort.SliceStable(keys, func(a, b int) bool {
+			return keys[a].Time < keys[b].Time
+		})
+
+		if n := len(keys); n > 0 && keys[n-1].Time > c.length {
+			c.length = keys[n-1].Time
+		}
+	}
+}
+
+// Length returns the time of the last keyframe of the clip, in seconds.
+func (c *AnimationClip) Length() float64 {
+	return c.length
+}
+
+// Evaluate returns the value of the curve at time t, written into out, which
+// is grown as required. Before the first and after the last keyframe, the
+// curve keeps the value of that keyframe.
+func (c *AnimationCurve) Evaluate(t float64, out []float32) []float32 {
+	if len(c.Keys) == 0 {
+		return out[:0]
+	}
+
+	a, b, u := c.segment(t)
+	out = append(out[:0], a.Value...)
+	if b == nil {
+		return out
+	}
+
+	if a.Interpolation == InterpolationBezier {
+		dt := float32(b.Time - a.Time)
+		for i := range out {
+			if i >= len(b.Value) {
+				break
+			}
+
+			p0, p3 := a.Value[i], b.Value[i]
+			p1 := p0 + tangent(a.OutTangent, i)*dt/3
+			p2 := p3 - tangent(b.InTangent, i)*dt/3
+
+			out[i] = bezier(p0, p1, p2, p3, float32(u))
+		}
+
+		return out
+	}
+
+	for i := range out {
+		if i < len(b.Value) {
+			out[i] = a.Value[i] + float32(u)*(b.Value[i]-a.Value[i])
+		}
+	}
+
+	return out
+}
+
+// evaluateRotation is like Evaluate, but spherically interpolates the
+// quaternion keys.
+func (c *AnimationCurve) evaluateRotation(t float64) mgl32.Quat {
+	if len(c.Keys) == 0 {
+		return mgl32.QuatIdent()
+	}
+
+	a, b, u := c.segment(t)
+	if b == nil || a.Interpolation == InterpolationBezier {
+		return quatFromValues(c.Evaluate(t, nil)).Normalize()
+	}
+
+	return mgl32.QuatSlerp(quatFromValues(a.Value), quatFromValues(b.Value), float32(u))
+}
+
+// segment returns the keyframes before and after time t, and the eased
+// position of t between them. If t is outside the keys, or the segment is a
+// step, the second keyframe is nil.
+func (c *AnimationCurve) segment(t float64) (*Keyframe, *Keyframe, float64) {
+	keys := c.Keys
+
+	i := sort.Search(len(keys), func(i int) bool {
+		return keys[i].Time > t
+	})
+	if i == 0 {
+		return &keys[0], nil, 0
+	}
+	if i == len(keys) {
+		return &keys[i-1], nil, 0
+	}
+
+	a, b := &keys[i-1], &keys[i]
+	if a.Interpolation == InterpolationStep || b.Time <= a.Time {
+		return a, nil, 0
+	}
+
+	u := (t - a.Time) / (b.Time - a.Time)
+
+	switch a.Interpolation {
+	case "", InterpolationLinear, InterpolationBezier:
+	default:
+		if ease, ok := math.Easing(string(a.Interpolation)); ok {
+			u = ease(u)
+		}
+	}
+
+	return a, b, u
+}
+
+func tangent(tangents []float32, i int) float32 {
+	if i < len(tangents) {
+		return tangents[i]
+	}
+
+	return 0
+}
+
+func bezier(p0, p1, p2, p3, t float32) float32 {
+	s := 1 - t
+
+	return s*s*s*p0 + 3*s*s*t*p1 + 3*s*t*t*p2 + t*t*t*p3
+}
+
+func quatFromValues(v []float32) mgl32.Quat {
+	var q [4]float32
+	copy(q[:], v)
+
+	return mgl32.Quat{V: mgl32.Vec3{q[0], q[1], q[2]}, W: q[3]}
+}
@@ -0,0 +1,265 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func nearFloat(a, b float32) bool {
+	return a-b < 1e-4 && b-a < 1e-4
+}
+
+func TestAnimationCurve_Evaluate(t *testing.T) {
+	curve := func(interpolation Interpolation) *AnimationCurve {
+		return &AnimationCurve{Keys: []Keyframe{
+			{Time: 0, Value: []float32{0}, Interpolation: interpolation},
+			{Time: 2, Value: []float32{4}},
+		}}
+	}
+
+	tests := []struct {
+		interpolation Interpolation
+		t             float64
+		want          float32
+	}{
+		{InterpolationLinear, -1, 0},
+		{InterpolationLinear, 1, 2},
+		{InterpolationLinear, 3, 4},
+		{"", 0.5, 1},
+		{InterpolationStep, 1.9, 0},
+		{InterpolationStep, 2, 4},
+		{"InQuad", 1, 1},
+		{"EaseOutQuad", 1, 3},
+		{InterpolationBezier, 1, 2},
+		{InterpolationBezier, 0.5, 4 * (0.25*0.25*3 - 2*0.25*0.25*0.25)},
+	}
+
+	for _, tt := range tests {
+		v := curve(tt.interpolation).Evaluate(tt.t, nil)
+		if len(v) != 1 || !nearFloat(v[0], tt.want) {
+			t.Errorf("%q at %v: expected %v, got: %v", tt.interpolation, tt.t, tt.want, v)
+		}
+	}
+
+	// Tangents shape bezier segments.
+	c := &AnimationCurve{Keys: []Keyframe{
+		{Time: 0, Value: []float32{0}, Interpolation: InterpolationBezier, OutTangent: []float32{1}},
+		{Time: 1, Value: []float32{1}, InTangent: []float32{1}},
+	}}
+	if v := c.Evaluate(0.25, nil); !nearFloat(v[0], 0.25) {
+		t.Errorf("expected straight bezier at 0.25, got: %v", v)
+	}
+}
+
+func TestAnimator(t *testing.T) {
+	app := newHeadlessApp(t)
+	defer app.Teardown()
+
+	s := NewScene("test")
+	s.Setup()
+
+	o := NewGameObject("o")
+	child := NewGameObject("child")
+	c := &docComponent{}
+	GetInstance().MustAssign(c)
+	child.AddComponent(c)
+	o.AddChild(child)
+
+	animator := NewAnimator()
+	o.AddComponent(animator)
+
+	if err := s.Graph().AddGameObject(o, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	move := NewAnimationClip([]AnimationCurve{
+		{Property: AnimationPosition, Keys: []Keyframe{
+			{Time: 0, Value: []float32{0, 0, 0}},
+			{Time: 1, Value: []float32{10, 0, 0}},
+		}},
+		{Path: "child", Component: "docComponent", Property: "Speed", Keys: []Keyframe{
+			{Time: 0, Value: []float32{0}},
+			{Time: 1, Value: []float32{1}},
+		}},
+	})
+	lift := NewAnimationClip([]AnimationCurve{
+		{Property: AnimationPosition, Keys: []Keyframe{
+			{Time: 0, Value: []float32{0, 10, 0}},
+		}},
+		{Property: AnimationRotation, Keys: []Keyframe{
+			{Time: 0, Value: []float32{0, 0, 0, 1}},
+			{Time: 1, Value: []float32{0, 1, 0, 0}},
+		}},
+		{Path: "child", Component: "docComponent", Property: "Offset", Keys: []Keyframe{
+			{Time: 0, Value: []float32{1, 2, 3}},
+		}},
+	})
+
+	animator.Play(move, true)
+	animator.Advance(0.25)
+	if p := o.Transform().Position(); !nearVec3(p, mgl32.Vec3{2.5, 0, 0}) {
+		t.Errorf("expected position [2.5 0 0], got: %v", p)
+	}
+	if !nearFloat(c.Speed, 0.25) {
+		t.Errorf("expected speed 0.25, got: %v", c.Speed)
+	}
+
+	// Looping clips wrap around.
+	animator.Advance(1)
+	if p := o.Transform().Position(); !nearVec3(p, mgl32.Vec3{2.5, 0, 0}) {
+		t.Errorf("expected wrapped position [2.5 0 0], got: %v", p)
+	}
+
+	// Cross fading blends the clips by weight until the fade ends.
+	animator.CrossFade(lift, 1, false)
+	animator.Advance(0.5)
+	if w := animator.Weight(move); w != 0.5 {
+		t.Errorf("expected weight 0.5, got: %v", w)
+	}
+	if p := o.Transform().Position(); !nearVec3(p, mgl32.Vec3{3.75, 5, 0}) {
+		t.Errorf("expected blended position [3.75 5 0], got: %v", p)
+	}
+	// Properties only lift animates are blended by its weight alone.
+	if r := o.Transform().Rotation(); !r.ApproxEqualThreshold(mgl32.QuatRotate(mgl32.DegToRad(45), mgl32.Vec3{0, 1, 0}), 1e-4) {
+		t.Errorf("expected rotation of 45 degrees, got: %v", r)
+	}
+	if !nearVec3(c.Offset, mgl32.Vec3{0.5, 1, 1.5}) {
+		t.Errorf("expected offset [0.5 1 1.5], got: %v", c.Offset)
+	}
+
+	animator.Advance(0.5)
+	if animator.Playing(move) || !animator.Playing(lift) {
+		t.Error("expected only lift to be playing")
+	}
+	if p := o.Transform().Position(); !nearVec3(p, mgl32.Vec3{0, 10, 0}) {
+		t.Errorf("expected position [0 10 0], got: %v", p)
+	}
+
+	// Clips which do not loop stop at their last keyframe.
+	animator.Advance(5)
+	if tm := animator.Time(lift); tm != 1 {
+		t.Errorf("expected time 1, got: %v", tm)
+	}
+
+	// Weights below 1 blend with the bind pose, which is at the origin with
+	// the identity rotation.
+	animator.Blend(lift, 0.5, false)
+	animator.Advance(0)
+	if p := o.Transform().Position(); !nearVec3(p, mgl32.Vec3{0, 5, 0}) {
+		t.Errorf("expected half weighted position [0 5 0], got: %v", p)
+	}
+	if r := o.Transform().Rotation(); !r.ApproxEqualThreshold(mgl32.QuatRotate(mgl32.DegToRad(90), mgl32.Vec3{0, 1, 0}), 1e-4) {
+		t.Errorf("expected half weighted rotation of 90 degrees, got: %v", r)
+	}
+}
+
+func TestAnimator_BindPose(t *testing.T) {
+	app := newHeadlessApp(t)
+	defer app.Teardown()
+
+	s := NewScene("test")
+	s.Setup()
+
+	o := NewGameObject("o")
+	o.Transform().SetPosition(mgl32.Vec3{0, 0, 4})
+
+	animator := NewAnimator()
+	o.AddComponent(animator)
+
+	if err := s.Graph().AddGameObject(o, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	clip := NewAnimationClip([]AnimationCurve{
+		{Property: AnimationScale, Keys: []Keyframe{
+			{Time: 0, Value: []float32{1, 1, 1}},
+		}},
+		{Property: AnimationPosition, Keys: []Keyframe{
+			{Time: 0, Value: []float32{10, 0, 0}},
+		}},
+	})
+
+	// The remaining weight of a single clip is blended with the values the
+	// properties had when they were bound.
+	animator.Blend(clip, 0.5, false)
+	animator.Advance(0)
+	if sc := o.Transform().Scale(); !nearVec3(sc, mgl32.Vec3{1, 1, 1}) {
+		t.Errorf("expected scale [1 1 1], got: %v", sc)
+	}
+	if p := o.Transform().Position(); !nearVec3(p, mgl32.Vec3{5, 0, 2}) {
+		t.Errorf("expected position [5 0 2], got: %v", p)
+	}
+}
+
+func TestAnimationHandler(t *testing.T) {
+	app := newHeadlessApp(t)
+	defer app.Teardown()
+
+	dir, err := ioutil.TempDir("", "animation")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	data := `
+curves:
+- property: scale
+  keys:
+  - {time: 1, value: [2, 2, 2], interpolation: OutCubic}
+  - {time: 0, value: [1, 1, 1]}
+`
+	filename := filepath.Join(dir, "grow.yaml")
+	if err := ioutil.WriteFile(filename, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := GetAsset().Load(AssetNameAnimation, filename); err != nil {
+		t.Fatal(err)
+	}
+
+	clip := GetAsset().MustGet(AssetNameAnimation, "grow.yaml").(*AnimationClip)
+	if clip.Length() != 1 {
+		t.Errorf("expected length 1, got: %v", clip.Length())
+	}
+	if v := clip.Curves()[0].Evaluate(0, nil); !nearFloat(v[0], 1) {
+		t.Errorf("expected sorted keys, got: %v", v)
+	}
+
+	// Unknown interpolations are rejected rather than treated as linear.
+	typo := filepath.Join(dir, "typo.yaml")
+	data = strings.Replace(data, "OutCubic", "OutCubik", 1)
+	if err := ioutil.WriteFile(typo, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := GetAsset().Load(AssetNameAnimation, typo); err == nil || !strings.Contains(err.Error(), "OutCubik") {
+		t.Errorf("expected unknown interpolation error, got: %v", err)
+	}
+}
@@ -0,0 +1,521 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"math"
+	"reflect"
+	"strings"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrAnimationTarget reports that the target of an animation curve cannot be
+// found.
+type ErrAnimationTarget string
+
+func (e ErrAnimationTarget) Error() string {
+	return "animation: target not found: " + string(e)
+}
+
+// Animator is a component which plays animation clips on its game object and
+// its descendants. Several clips may play at once; their values are blended
+// by weight.
+type Animator struct {
+	BaseScriptComponent
+
+	states   []*animatorState
+	bindings map[animatorTarget]*animatorBinding
+	speed    float64
+}
+
+// animatorState is a clip played by an animator.
+type animatorState struct {
+	clip   *AnimationClip
+	time   float64
+	loop   bool
+	weight float64
+	target float64
+	fade   float64
+}
+
+// animatorTarget identifies the property animated by a curve.
+type animatorTarget struct {
+	path      string
+	component string
+	property  string
+}
+
+// animatorBinding accumulates the blended value of a property during an
+// update, and sets it. The value of the property when it was bound is its
+// bind pose.
+type animatorBinding struct {
+	set    func([]float32)
+	value  []float32
+	bind   []float32
+	sample []float32
+	weight float64
+	rotate bool
+}
+
+// NewAnimator creates a new Animator component.
+func NewAnimator() *Animator {
+	a := &Animator{
+		bindings: make(map[animatorTarget]*animatorBinding),
+		speed:    1,
+	}
+
+	a.SetName("Animator")
+	GetInstance().MustAssign(a)
+
+	return a
+}
+
+// animatorDocument is the serialized form of an Animator. Clips are recorded
+// by the names of their animation assets.
+type animatorDocument struct {
+	Speed float64
+	Clips []animatorStateDocument
+}
+
+// animatorStateDocument is the serialized form of a playing clip.
+type animatorStateDocument struct {
+	Clip   string
+	Time   float64
+	Loop   bool
+	Weight float64
+	Target float64
+	Fade   float64
+}
+
+// SceneDocument returns the speed and the playing clips of the animator.
+func (a *Animator) SceneDocument() (interface{}, error) {
+	doc := &animatorDocument{Speed: a.speed}
+
+	for _, s := range a.states {
+		name, err := GetAsset().AssetName(AssetNameAnimation, s.clip)
+		if err != nil {
+			return nil, err
+		}
+
+		doc.Clips = append(doc.Clips, animatorStateDocument{
+			Clip:   name,
+			Time:   s.time,
+			Loop:   s.loop,
+			Weight: s.weight,
+			Target: s.target,
+			Fade:   s.fade,
+		})
+	}
+
+	return doc, nil
+}
+
+// ApplySceneDocument sets the speed of the animator, and plays the clips of
+// the document in place of the playing clips.
+func (a *Animator) ApplySceneDocument(doc interface{}) error {
+	d := doc.(*animatorDocument)
+
+	h, err := GetAsset().GetHandler(AssetNameAnimation)
+	if err != nil {
+		return err
+	}
+
+	states := make([]*animatorState, 0, len(d.Clips))
+	for _, v := range d.Clips {
+		clip, err := h.(*AnimationHandler).Get(v.Clip)
+		if err != nil {
+			return err
+		}
+
+		states = append(states, &animatorState{
+			clip:   clip,
+			time:   v.Time,
+			loop:   v.Loop,
+			weight: v.Weight,
+			target: v.Target,
+			fade:   v.Fade,
+		})
+	}
+
+	a.speed = d.Speed
+	a.states = states
+
+	return nil
+}
+
+// AnimatorComponent gets the first occurrence of Animator from the game
+// object.
+func AnimatorComponent(g *GameObject) *Animator {
+	return GetComponent[*Animator](g)
+}
+
+// Play plays the clip from the start with full weight, and stops all other
+// clips.
+func (a *Animator) Play(clip *AnimationClip, loop bool) {
+	a.StopAll()
+	a.Blend(clip, 1, loop)
+}
+
+// Blend plays the clip with the weight, alongside the clips already playing.
+// If the clip is playing, only its weight and looping are changed.
+func (a *Animator) Blend(clip *AnimationClip, weight float64, loop bool) {
+	s := a.state(clip)
+	if s == nil {
+		s = &animatorState{clip: clip}
+		a.states = append(a.states, s)
+	}
+
+	s.loop = loop
+	s.weight = weight
+	s.target = weight
+	s.fade = 0
+}
+
+// CrossFade fades the clip in to full weight over the duration in seconds,
+// while fading out all other clips. Clips are stopped once faded out.
+func (a *Animator) CrossFade(clip *AnimationClip, duration float64, loop bool) {
+	if duration <= 0 {
+		a.Play(clip, loop)
+		return
+	}
+
+	s := a.state(clip)
+	if s == nil {
+		s = &animatorState{clip: clip}
+		a.states = append(a.states, s)
+	}
+	s.loop = loop
+
+	for _, v := range a.states {
+		v.target = 0
+		if v == s {
+			v.target = 1
+		}
+		v.fade = 1 / duration
+	}
+}
+
+// Stop stops the clip.
+func (a *Animator) Stop(clip *AnimationClip) {
+	for i := range a.states {
+		if a.states[i].clip == clip {
+			a.states = append(a.states[:i], a.states[i+1:]...)
+			return
+		}
+	}
+}
+
+// StopAll stops all clips.
+func (a *Animator) StopAll() {
+	a.states = a.states[:0]
+}
+
+// Playing reports whether the clip is playing.
+func (a *Animator) Playing(clip *AnimationClip) bool {
+	return a.state(clip) != nil
+}
+
+// Weight returns the blend weight of the clip, or zero if it is not playing.
+func (a *Animator) Weight(clip *AnimationClip) float64 {
+	if s := a.state(clip); s != nil {
+		return s.weight
+	}
+
+	return 0
+}
+
+// Time returns the play time of the clip, in seconds.
+func (a *Animator) Time(clip *AnimationClip) float64 {
+	if s := a.state(clip); s != nil {
+		return s.time
+	}
+
+	return 0
+}
+
+// Speed returns the playback speed multiplier.
+func (a *Animator) Speed() float64 {
+	return a.speed
+}
+
+// SetSpeed sets the playback speed multiplier.
+func (a *Animator) SetSpeed(speed float64) {
+	a.speed = speed
+}
+
+// Rebind resolves the animated properties again. It must be called after
+// game objects or components targeted by the clips are replaced.
+func (a *Animator) Rebind() {
+	a.bindings = make(map[animatorTarget]*animatorBinding)
+}
+
+// Update advances the animation by the frame time.
+func (a *Animator) Update() {
+	a.Advance(GetTime().DeltaTime())
+}
+
+// Advance moves the clips forward by dt seconds, and sets the animated
+// properties to their blended values.
+func (a *Animator) Advance(dt float64) {
+	dt *= a.speed
+
+	states := a.states[:0]
+	for _, s := range a.states {
+		s.time += dt
+		if length := s.clip.Length(); s.time > length {
+			if s.loop && length > 0 {
+				for s.time > length {
+					s.time -= length
+				}
+			} else {
+				s.time = length
+			}
+		}
+
+		if s.fade > 0 {
+			if s.weight < s.target {
+				s.weight = math.Min(s.weight+s.fade*dt, s.target)
+			} else {
+				s.weight = math.Max(s.weight-s.fade*dt, s.target)
+			}
+			if s.weight == s.target {
+				s.fade = 0
+			}
+		}
+
+		if s.weight <= 0 && s.target <= 0 {
+			continue
+		}
+		states = append(states, s)
+	}
+	a.states = states
+
+	a.apply()
+}
+
+// apply blends the values of the playing clips, and sets the properties.
+func (a *Animator) apply() {
+	for _, b := range a.bindings {
+		if b != nil {
+			b.weight = 0
+		}
+	}
+
+	for _, s := range a.states {
+		if s.weight <= 0 {
+			continue
+		}
+
+		for i := range s.clip.curves {
+			c := &s.clip.curves[i]
+
+			b := a.binding(c)
+			if b == nil {
+				continue
+			}
+
+			if b.rotate {
+				q := c.evaluateRotation(s.time)
+				b.sample = append(b.sample[:0], q.V[0], q.V[1], q.V[2], q.W)
+			} else {
+				b.sample = c.Evaluate(s.time, b.sample)
+			}
+
+			b.add(b.sample, s.weight)
+		}
+	}
+
+	for _, b := range a.bindings {
+		if b != nil && b.weight > 0 {
+			b.apply()
+		}
+	}
+}
+
+// binding returns the binding of the curve target, resolving it if required.
+// Targets which cannot be resolved are logged once and ignored.
+func (a *Animator) binding(c *AnimationCurve) *animatorBinding {
+	t := animatorTarget{path: c.Path, component: c.Component, property: c.Property}
+
+	if b, ok := a.bindings[t]; ok {
+		return b
+	}
+
+	b, err := a.bind(t)
+	if err != nil {
+		logrus.Warnf("animator: %v", err)
+	}
+	a.bindings[t] = b
+
+	return b
+}
+
+func (a *Animator) bind(t animatorTarget) (*animatorBinding, error) {
+	object := a.GameObject()
+	if object == nil {
+		return nil, ErrSceneGraphNotFound
+	}
+
+	if t.path != "" {
+		for _, name := range strings.Split(t.path, "/") {
+			object = childByName(object, name)
+			if object == nil {
+				return nil, ErrAnimationTarget(t.path)
+			}
+		}
+	}
+
+	if t.component == "" {
+		return bindTransform(object.Transform(), t.property)
+	}
+
+	for _, c := range object.Components() {
+		if name, ok := ComponentTypeName(c); ok && name == t.component {
+			return bindField(c, t.property)
+		}
+	}
+
+	return nil, ErrAnimationTarget(t.path + ":" + t.component)
+}
+
+func bindTransform(transform Transform, property string) (*animatorBinding, error) {
+	switch property {
+	case AnimationPosition:
+		p := transform.Position()
+		return &animatorBinding{set: func(v []float32) {
+			transform.SetPosition(mgl32.Vec3{v[0], v[1], v[2]})
+		}, value: make([]float32, 3), bind: p[:]}, nil
+	case AnimationScale:
+		s := transform.Scale()
+		return &animatorBinding{set: func(v []float32) {
+			transform.SetScale(mgl32.Vec3{v[0], v[1], v[2]})
+		}, value: make([]float32, 3), bind: s[:]}, nil
+	case AnimationRotation:
+		q := transform.Rotation()
+		return &animatorBinding{set: func(v []float32) {
+			transform.SetRotation(quatFromValues(v).Normalize())
+		}, value: make([]float32, 4), bind: []float32{q.V[0], q.V[1], q.V[2], q.W}, rotate: true}, nil
+	}
+
+	return nil, ErrAnimationTarget(property)
+}
+
+// bindField binds an exported float field, or array of floats, of the
+// component.
+func bindField(c Component, property string) (*animatorBinding, error) {
+	v := reflect.Indirect(reflect.ValueOf(c))
+	if v.Kind() != reflect.Struct {
+		return nil, ErrAnimationTarget(property)
+	}
+
+	f, ok := v.Type().FieldByName(property)
+	if !ok || f.PkgPath != "" {
+		return nil, ErrAnimationTarget(property)
+	}
+	field := v.FieldByIndex(f.Index)
+
+	switch field.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return &animatorBinding{set: func(v []float32) {
+			field.SetFloat(float64(v[0]))
+		}, value: make([]float32, 1), bind: []float32{float32(field.Float())}}, nil
+	case reflect.Array:
+		if k := field.Type().Elem().Kind(); k != reflect.Float32 && k != reflect.Float64 {
+			break
+		}
+		bind := make([]float32, field.Len())
+		for i := range bind {
+			bind[i] = float32(field.Index(i).Float())
+		}
+		return &animatorBinding{set: func(v []float32) {
+			for i := 0; i < field.Len() && i < len(v); i++ {
+				field.Index(i).SetFloat(float64(v[i]))
+			}
+		}, value: make([]float32, field.Len()), bind: bind}, nil
+	}
+
+	return nil, ErrAnimationTarget(property)
+}
+
+// add blends a sampled value into the binding.
+func (b *animatorBinding) add(sample []float32, weight float64) {
+	if b.weight == 0 {
+		for i := range b.value {
+			b.value[i] = 0
+		}
+	}
+
+	// Keep quaternions in the same hemisphere, so they blend the short way.
+	w := float32(weight)
+	if b.rotate && b.weight > 0 && dot(b.value, sample) < 0 {
+		w = -w
+	}
+
+	for i := range b.value {
+		if i < len(sample) {
+			b.value[i] += sample[i] * w
+		}
+	}
+	b.weight += weight
+}
+
+// apply sets the property to the weighted sum of the samples, normalized if
+// the weights add up to more than 1. Below that, the samples are blended with
+// the bind pose.
+func (b *animatorBinding) apply() {
+	if b.weight < 1 {
+		b.add(b.bind, 1-b.weight)
+	}
+
+	if !b.rotate && b.weight > 1 {
+		inv := float32(1 / b.weight)
+		for i := range b.value {
+			b.value[i] *= inv
+		}
+	}
+
+	b.set(b.value)
+}
+
+func (a *Animator) state(clip *AnimationClip) *animatorState {
+	for _, s := range a.states {
+		if s.clip == clip {
+			return s
+		}
+	}
+
+	return nil
+}
+
+func dot(a, b []float32) float32 {
+	var d float32
+	for i := range a {
+		if i < len(b) {
+			d += a[i] * b[i]
+		}
+	}
+
+	return d
+}
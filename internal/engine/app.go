@@ -37,7 +37,9 @@ const (
 	fixedTime    = float64(0.05)
 	maxFrameSkip = 5
 
-	builtinAssets = "<builtin>:builtin.json"
+	defaultFrameStep = float64(1.0 / 60.0)
+
+	builtinAssets = "builtin.json"
 )
 
 var (
@@ -48,6 +50,26 @@ var (
 // AppConfig provides options for configuring an App.
 type AppConfig struct {
 	Name string
+
+	// Headless replaces the window system with a null window that never
+	// creates a GL context. Built-in assets are not loaded in this mode.
+	Headless bool
+
+	// Clock is the time source for the Time system. If nil, a GLFW clock is
+	// used, or a ManualClock if Headless is set.
+	Clock Clock
+
+	// FrameStep is the amount a ManualClock is advanced at the end of every
+	// frame. If zero, headless apps default to 1/60th of a second.
+	FrameStep float64
+
+	// FixedStep is the interval of fixed updates, in seconds. If zero, it
+	// defaults to 0.05.
+	FixedStep float64
+
+	// MaxFrameSkip is the maximum number of fixed updates in a frame. If
+	// zero, it defaults to 5.
+	MaxFrameSkip int
 }
 
 // App is the backbone of any Apex application.
@@ -55,18 +77,40 @@ type App struct {
 	scenes           map[string]*Scene
 	systems          []System
 	activeScenes     []string
+	clock            Clock
 	preStartFunc     func() error
 	postStartFunc    func() error
 	preTeardownFunc  func()
 	postTeardownFunc func()
 	name             string
+	frameStep        float64
+	fixedStep        float64
+	maxFrameSkip     int
+	headless         bool
 	running          bool
 }
 
 // NewApp creates a new App using the provided AppConfig for customization.
 func NewApp(cfg *AppConfig) *App {
 	a := &App{
-		name: cfg.Name,
+		name:         cfg.Name,
+		headless:     cfg.Headless,
+		clock:        cfg.Clock,
+		frameStep:    cfg.FrameStep,
+		fixedStep:    cfg.FixedStep,
+		maxFrameSkip: cfg.MaxFrameSkip,
+	}
+
+	if a.clock == nil {
+		if a.headless {
+			a.clock = NewManualClock()
+		} else {
+			a.clock = &glfwClock{}
+		}
+	}
+
+	if a.headless && a.frameStep == 0 {
+		a.frameStep = defaultFrameStep
 	}
 
 	return a
@@ -84,6 +128,15 @@ func setApp(a *App) {
 	})
 }
 
+// clearApp unsets the App if it is the current one, allowing another App to
+// be set up after it has been torn down.
+func clearApp(a *App) {
+	if app == a {
+		app = nil
+		appOnce = sync.Once{}
+	}
+}
+
 // Setup sets up the App.
 func (a *App) Setup() error {
 	setApp(a)
@@ -95,18 +148,33 @@ func (a *App) Setup() error {
 	a.activeScenes = []string{}
 
 	// Register required systems.
-	a.RegisterSystem(NewWindow())
+	if a.headless {
+		a.RegisterSystem(NewNullWindow())
+	} else {
+		a.RegisterSystem(NewWindow())
+	}
 	a.RegisterSystem(NewInstance())
 	a.RegisterSystem(NewAsset())
-	a.RegisterSystem(NewTime())
+	time := NewTimeWithClock(a.clock)
+	time.SetFixedTime(a.fixedStep)
+	time.SetMaxFrameSkip(a.maxFrameSkip)
+	a.RegisterSystem(time)
+	a.RegisterSystem(NewEvents())
+	a.RegisterSystem(NewTweens())
+	a.RegisterSystem(NewScheduler())
+	a.RegisterSystem(NewInput())
 
 	// Register asset handlers.
 	asset := GetAsset()
 	asset.RegisterHandler(NewImageHandler())
+	asset.RegisterHandler(NewMaterialHandler())
 	asset.RegisterHandler(NewMeshHandler())
+	asset.RegisterHandler(NewModelHandler())
 	asset.RegisterHandler(NewShaderHandler())
 	asset.RegisterHandler(NewSkyboxHandler())
 	asset.RegisterHandler(NewFontHandler())
+	asset.RegisterHandler(NewPrefabHandler())
+	asset.RegisterHandler(NewAnimationHandler())
 
 	if a.preStartFunc != nil {
 		if err := a.preStartFunc(); err != nil {
@@ -122,9 +190,11 @@ func (a *App) Setup() error {
 		}
 	}
 
-	// Load base assets.
-	if err := asset.LoadManifest(builtinAssets); err != nil {
-		return err
+	// Load base assets. These require a GL context.
+	if !a.headless {
+		if err := asset.LoadManifest(builtinAssets); err != nil {
+			return err
+		}
 	}
 
 	if a.postStartFunc != nil {
@@ -151,6 +221,8 @@ func (a *App) Teardown() {
 	if a.postTeardownFunc != nil {
 		a.postTeardownFunc()
 	}
+
+	clearApp(a)
 }
 
 // Run starts the main loop of the app.
@@ -159,41 +231,61 @@ func (a *App) Run() error {
 
 	a.setupSignalHandler()
 
-	frame := 0
-	loops := 0
-
-	time := a.MustSystem(SysNameTime).(*Time)
 	window := a.MustSystem(SysNameWindow).(*Window)
 
 	for a.running {
 		a.running = !window.ShouldClose()
 
-		time.FrameStart()
+		a.frame()
+	}
 
-		frame++
+	return nil
+}
 
-		if window.KeyDown(glfw.KeyF9) {
-			a.debugInfo()
-		}
+// Step runs n iterations of the main loop and then returns. It is intended
+// for driving headless apps, where each step advances a ManualClock by the
+// configured FrameStep.
+func (a *App) Step(n int) {
+	for i := 0; i < n; i++ {
+		a.frame()
+	}
+}
+
+// frame runs a single iteration of the main loop.
+func (a *App) frame() {
+	time := a.MustSystem(SysNameTime).(*Time)
+	window := a.MustSystem(SysNameWindow).(*Window)
 
-		a.onUpdate()
+	time.FrameStart()
 
-		loops = 0
-		for time.LogicUpdate() && loops < maxFrameSkip {
-			time.LogicTick()
-			a.onFixedUpdate()
-			loops++
-		}
+	asset := a.MustSystem(SysNameAsset).(*Asset)
+	asset.Poll()
+	asset.Upload()
 
-		window.ClearBuffers()
-		a.onDisplay()
-		window.SwapBuffers()
+	a.MustSystem(SysNameInput).(*Input).Update()
 
-		window.HandleEvents()
-		time.FrameEnd()
+	if window.KeyDown(glfw.KeyF9) {
+		a.debugInfo()
 	}
 
-	return nil
+	a.onUpdate()
+
+	for time.LogicUpdate() {
+		time.LogicTick()
+		a.onFixedUpdate()
+	}
+
+	window.ClearBuffers()
+	a.onDisplay()
+	window.SwapBuffers()
+
+	window.HandleEvents()
+
+	if c, ok := a.clock.(*ManualClock); ok {
+		c.Advance(a.frameStep)
+	}
+
+	time.FrameEnd()
 }
 
 // Quit instructs the App to shutdown by setting the running variable to false.
@@ -205,6 +297,11 @@ func (a *App) Name() string {
 	return a.name
 }
 
+// Headless reports if the App is running without a window or GL context.
+func (a *App) Headless() bool {
+	return a.headless
+}
+
 // RegisterSystem registers a system with the App. A system can only be added
 // once, it is an error to add a system more than once. Systems are initialized
 // in the order they are added and torn down in the reverse order.
@@ -412,6 +509,12 @@ func (a *App) onDisplay() {
 		if sg.Dirty() {
 			sg.Update()
 		}
+		sg.ResolveTransforms()
+
+		// Headless apps have no context to draw to.
+		if a.Headless() {
+			return
+		}
 
 		cameras := s.cameras
 		for i := range cameras {
@@ -423,20 +526,25 @@ func (a *App) onDisplay() {
 }
 
 func (a *App) onUpdate() {
+	time := GetTime()
+
+	a.MustSystem(SysNameTween).(*Tweens).Update(time.DeltaTime())
+	if !time.Paused() {
+		a.MustSystem(SysNameScheduler).(*Scheduler).Update(time.DeltaTime())
+	}
+
 	if s := a.ActiveScene(); s != nil {
 		sg := s.Graph()
 		if sg.Dirty() {
 			sg.Update()
 		}
 
-		if !s.started {
-			s.started = true
-			sg.SendMessage(MessageStart)
-		}
-
+		sg.SendMessage(MessageStart)
 		sg.SendMessage(MessageUpdate)
 		sg.SendMessage(MessageLateUpdate)
 	}
+
+	GetEvents().Flush()
 }
 
 func (a *App) onFixedUpdate() {
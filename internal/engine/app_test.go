@@ -0,0 +1,161 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"math"
+	"testing"
+)
+
+type counterComponent struct {
+	BaseScriptComponent
+
+	awake       int
+	start       int
+	update      int
+	lateUpdate  int
+	fixedUpdate int
+}
+
+func (c *counterComponent) Awake()       { c.awake++ }
+func (c *counterComponent) Start()       { c.start++ }
+func (c *counterComponent) Update()      { c.update++ }
+func (c *counterComponent) LateUpdate()  { c.lateUpdate++ }
+func (c *counterComponent) FixedUpdate() { c.fixedUpdate++ }
+
+func newHeadlessApp(t testing.TB) *App {
+	a := NewApp(&AppConfig{
+		Name:      "test",
+		Headless:  true,
+		FrameStep: fixedTime,
+	})
+
+	if err := a.Setup(); err != nil {
+		t.Fatal(err)
+	}
+
+	return a
+}
+
+func TestApp_Headless(t *testing.T) {
+	a := newHeadlessApp(t)
+	defer a.Teardown()
+
+	if !a.Headless() {
+		t.Error("a.Headless() expected true")
+	}
+	if !GetWindow().Null() {
+		t.Error("GetWindow().Null() expected true")
+	}
+	if _, ok := GetTime().Clock().(*ManualClock); !ok {
+		t.Errorf("GetTime().Clock() expected *ManualClock, got: %T", GetTime().Clock())
+	}
+}
+
+func TestApp_Step(t *testing.T) {
+	a := newHeadlessApp(t)
+	defer a.Teardown()
+
+	c := &counterComponent{}
+	GetInstance().MustAssign(c)
+
+	s := NewScene("test")
+	s.SetLoadFunc(func() error {
+		o := NewGameObject("counter")
+		o.AddComponent(c)
+
+		return s.Graph().AddGameObject(o, nil)
+	})
+
+	if err := a.RegisterScene(s); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.PushScene("test"); err != nil {
+		t.Fatal(err)
+	}
+
+	a.Step(10)
+
+	if c.awake != 1 {
+		t.Error("c.awake expected 1, got:", c.awake)
+	}
+	if c.start != 1 {
+		t.Error("c.start expected 1, got:", c.start)
+	}
+	if c.update != 10 {
+		t.Error("c.update expected 10, got:", c.update)
+	}
+	if c.lateUpdate != 10 {
+		t.Error("c.lateUpdate expected 10, got:", c.lateUpdate)
+	}
+
+	// The first frame starts at zero, so no fixed update is due until the
+	// clock has advanced by one step.
+	if c.fixedUpdate != 9 {
+		t.Error("c.fixedUpdate expected 9, got:", c.fixedUpdate)
+	}
+
+	if f := GetTime().Frame(); f != 10 {
+		t.Error("GetTime().Frame() expected 10, got:", f)
+	}
+	if d := GetTime().DeltaTime(); math.Abs(d-fixedTime) > 1e-9 {
+		t.Errorf("GetTime().DeltaTime() expected %f, got: %f", fixedTime, d)
+	}
+}
+
+func TestApp_FixedStep(t *testing.T) {
+	a := NewApp(&AppConfig{
+		Name:      "test",
+		Headless:  true,
+		FrameStep: fixedTime,
+		FixedStep: fixedTime / 2,
+	})
+	if err := a.Setup(); err != nil {
+		t.Fatal(err)
+	}
+	defer a.Teardown()
+
+	c := &counterComponent{}
+	GetInstance().MustAssign(c)
+
+	s := NewScene("test")
+	s.SetLoadFunc(func() error {
+		o := NewGameObject("counter")
+		o.AddComponent(c)
+
+		return s.Graph().AddGameObject(o, nil)
+	})
+
+	if err := a.RegisterScene(s); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.PushScene("test"); err != nil {
+		t.Fatal(err)
+	}
+
+	a.Step(10)
+
+	if c.fixedUpdate != 18 {
+		t.Error("c.fixedUpdate expected 18, got:", c.fixedUpdate)
+	}
+}
@@ -28,9 +28,14 @@ import (
 	"io"
 	"os"
 	"path"
+	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
 
 	"github.com/haakenlabs/forge/internal/builtin"
 )
@@ -58,6 +63,29 @@ func (e ErrAssetType) Error() string {
 	return "asset: type assertion error for asset: " + string(e)
 }
 
+// ErrAssetNotReloadable reports that the handler cannot reload assets.
+type ErrAssetNotReloadable string
+
+func (e ErrAssetNotReloadable) Error() string {
+	return "asset: handler cannot reload assets: " + string(e)
+}
+
+// ErrAssetReferenced reports that the asset is referenced and cannot be
+// unloaded.
+type ErrAssetReferenced string
+
+func (e ErrAssetReferenced) Error() string {
+	return "asset: asset is referenced: " + string(e)
+}
+
+// ErrAssetNotTracked reports that an object is not an asset of the handler,
+// so it has no name to be referred to by.
+type ErrAssetNotTracked string
+
+func (e ErrAssetNotTracked) Error() string {
+	return "asset: object is not a tracked asset: " + string(e)
+}
+
 // ErrAssetNotFound reports that the handler is not registered.
 type ErrHandlerNotFound string
 
@@ -95,6 +123,30 @@ type AssetHandler interface {
 
 	// Count returns the number of assets tracked by this handler.
 	Count() int
+
+	// Names returns the names of the assets tracked by this handler.
+	Names() []string
+
+	// Remove stops tracking an asset and returns its instance ID. The asset
+	// is not released.
+	Remove(string) (uint32, error)
+}
+
+// AssetVerifier is implemented by asset handlers which can check that a
+// resource decodes without loading it. Verification must not require a GL
+// context.
+type AssetVerifier interface {
+	// Verify checks that the resource can be loaded by the handler.
+	Verify(*Resource) error
+}
+
+// AssetDecoder is implemented by asset handlers which can decode a resource
+// off the main thread. Decode must not require a GL context. It returns a
+// function which allocates and adds the decoded asset, which is called on the
+// main thread.
+type AssetDecoder interface {
+	// Decode decodes the resource and returns the function uploading it.
+	Decode(*Resource) (func() error, error)
 }
 
 type BaseAssetHandler struct {
@@ -104,19 +156,63 @@ type BaseAssetHandler struct {
 
 type Asset struct {
 	handlers map[string]AssetHandler
-	packages map[string]*Package
+	vfs      *VFS
+	reload   *assetReload
+	loads    []*AssetLoad
+	workers  int
+	budget   time.Duration
+	refs     *assetRefs
 	mu       *sync.RWMutex
 }
 
+// MountConfig describes a file source to mount in the virtual filesystem.
+// Mounts are read from the configuration key asset.mounts. Paths ending in
+// .pkg are mounted as packages, other paths as directories.
+type MountConfig struct {
+	Path     string `mapstructure:"path"`
+	Priority int    `mapstructure:"priority"`
+}
+
 // Setup sets up the System.
 func (a *Asset) Setup() error {
+	a.SetHotReload(viper.GetBool("asset.hotreload"))
+
+	if viper.IsSet("asset.workers") {
+		a.SetLoadWorkers(viper.GetInt("asset.workers"))
+	}
+	if viper.IsSet("asset.uploadbudget") {
+		a.SetUploadBudget(viper.GetDuration("asset.uploadbudget"))
+	}
+
+	var mounts []MountConfig
+
+	if err := viper.UnmarshalKey("asset.mounts", &mounts); err != nil {
+		return err
+	}
+
+	for _, m := range mounts {
+		var source FileSource
+
+		if strings.EqualFold(filepath.Ext(m.Path), pkgExtension) {
+			source = NewPackageFile(m.Path)
+		} else {
+			source = NewDirSource(m.Path)
+		}
+
+		if err := a.Mount(source, m.Priority); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
 // Teardown tears down the System.
 func (a *Asset) Teardown() {
+	a.cancelLoads()
+	a.reportLeaks()
 	a.ReleaseAll()
-	a.UnmountAllPackages()
+	a.UnmountAll()
 }
 
 // Name returns the name of the System.
@@ -124,57 +220,139 @@ func (a *Asset) Name() string {
 	return SysNameAsset
 }
 
-// MountPackage mounts a new package by name.
+// MountPackage mounts a new package by name in the virtual filesystem with
+// the default priority.
 func (a *Asset) MountPackage(name string) error {
-	a.mu.Lock()
-	defer a.mu.Unlock()
+	return a.mountPackage(NewPackage(name))
+}
 
-	if _, dup := a.packages[name]; dup {
-		return ErrPackageMounted(name)
+// MountPackageFile mounts a package from a file in the virtual filesystem
+// with the default priority. The package is named by the filename without its
+// extension.
+func (a *Asset) MountPackageFile(filename string) error {
+	return a.mountPackage(NewPackageFile(filename))
+}
+
+func (a *Asset) mountPackage(p *Package) error {
+	if _, dup := a.vfs.Source(p.Name()); dup {
+		return ErrPackageMounted(p.Name())
 	}
 
-	p := NewPackage(name)
-	if err := p.Mount(); err != nil {
+	return a.Mount(p, MountPriorityDefault)
+}
+
+// Mount mounts a file source in the virtual filesystem with the given
+// priority. Packages which have not been opened are mounted first.
+func (a *Asset) Mount(source FileSource, priority int) error {
+	p, ok := source.(*Package)
+	if ok && p.reader == nil {
+		if err := p.Mount(); err != nil {
+			return err
+		}
+	}
+
+	if err := a.vfs.Mount(source, priority); err != nil {
+		if ok {
+			p.Unmount()
+		}
 		return err
 	}
 
-	a.packages[name] = p
+	logrus.Debugf("Mounted %s with priority %d", source.Name(), priority)
 
 	return nil
 }
 
-// UnmountPackage unmounts a mounted package given by name.
-func (a *Asset) UnmountPackage(name string) error {
-	a.mu.Lock()
-	defer a.mu.Unlock()
-
-	if _, ok := a.packages[name]; !ok {
-		return ErrPackageNotMounted(name)
-	}
+// MountDir mounts a directory in the virtual filesystem with the given
+// priority.
+func (a *Asset) MountDir(dir string, priority int) error {
+	return a.Mount(NewDirSource(dir), priority)
+}
 
-	if err := a.packages[name].Unmount(); err != nil {
+// Unmount unmounts a file source from the virtual filesystem by name. Assets
+// loaded from the source are unloaded, unless they are still referenced.
+func (a *Asset) Unmount(name string) error {
+	source, err := a.vfs.Unmount(name)
+	if err != nil {
 		return err
 	}
 
-	delete(a.packages, name)
+	a.unloadSource(name)
+
+	if p, ok := source.(*Package); ok {
+		return p.Unmount()
+	}
 
 	return nil
 }
 
+// UnmountAll unmounts all file sources from the virtual filesystem.
+func (a *Asset) UnmountAll() {
+	for _, name := range a.vfs.Mounts() {
+		if err := a.Unmount(name); err != nil {
+			logrus.Error(err)
+		}
+	}
+}
+
+// VFS returns the virtual filesystem used to resolve resources.
+func (a *Asset) VFS() *VFS {
+	return a.vfs
+}
+
+// UnmountPackage unmounts a mounted package given by name. Assets loaded from
+// the package are unloaded, unless they are still referenced.
+func (a *Asset) UnmountPackage(name string) error {
+	if a.mountedPackage(name) == nil {
+		return ErrPackageNotMounted(name)
+	}
+
+	return a.Unmount(name)
+}
+
 // UnmountAllPackages unmounts all mounted packages.
 func (a *Asset) UnmountAllPackages() {
-	for p := range a.packages {
-		if err := a.UnmountPackage(p); err != nil {
+	for _, name := range a.vfs.Mounts() {
+		if a.mountedPackage(name) == nil {
+			continue
+		}
+
+		if err := a.Unmount(name); err != nil {
 			logrus.Error(err)
 		}
 	}
 }
 
+// mountedPackage returns the package mounted with the name, or nil if none is.
+func (a *Asset) mountedPackage(name string) *Package {
+	source, _ := a.vfs.Source(name)
+	p, _ := source.(*Package)
+
+	return p
+}
+
 // Get gets an asset by name from a handler by kind.
 func (a *Asset) Get(kind, name string) (Object, error) {
 	return a.GetAsset(kind, name)
 }
 
+// AssetName returns the name the object is tracked by in the handler of the
+// kind.
+func (a *Asset) AssetName(kind string, object Object) (string, error) {
+	h, err := a.GetHandler(kind)
+	if err != nil {
+		return "", err
+	}
+
+	for _, name := range h.Names() {
+		if o, err := h.GetAsset(name); err == nil && o.ID() == object.ID() {
+			return name, nil
+		}
+	}
+
+	return "", ErrAssetNotTracked(kind + ": " + object.Name())
+}
+
 // MustGet is like Get, but panics if an error is encountered.
 func (a *Asset) MustGet(kind, name string) Object {
 	if a, err := a.Get(kind, name); err != nil {
@@ -217,30 +395,130 @@ func (a *Asset) LoadManifest(files ...string) error {
 
 			// Read and load assets.
 			for n := range m.Assets[t] {
-				ar, err := NewResource(path.Join(r.DirPrefix(), m.Assets[t][n]))
-				if err != nil {
+				if err := a.loadAsset(h, path.Join(r.DirPrefix(), m.Assets[t][n])); err != nil {
 					return err
 				}
 
-				if err := a.ReadResource(ar); err != nil {
-					return err
-				}
+				logrus.Debug("Loaded asset: ", m.Assets[t][n])
+			}
+		}
+	}
 
-				logrus.Debug("Read asset: ", m.Assets[t][n])
+	return nil
+}
 
-				if err := h.Load(ar); err != nil {
-					return err
-				}
+// VerifyManifest checks that every asset in a manifest can be read and
+// decoded by its handler, without loading it. The function fn is called for
+// each asset with the result of its verification. An error is returned only
+// if the manifest itself cannot be read.
+func (a *Asset) VerifyManifest(file string, fn func(kind, name string, err error)) error {
+	m, r, err := a.readManifest(file)
+	if err != nil {
+		return err
+	}
 
-				logrus.Debug("Loaded asset: ", m.Assets[t][n])
+	kinds := make([]string, 0, len(m.Assets))
+	for t := range m.Assets {
+		kinds = append(kinds, t)
+	}
+	sort.Strings(kinds)
+
+	for _, t := range kinds {
+		h, herr := a.GetHandler(t)
+
+		for _, name := range m.Assets[t] {
+			if herr != nil {
+				fn(t, name, herr)
+				continue
 			}
+
+			ar, err := NewResource(path.Join(r.DirPrefix(), name))
+			if err == nil {
+				err = a.ReadResource(ar)
+			}
+			if err == nil {
+				if v, ok := h.(AssetVerifier); ok {
+					err = v.Verify(ar)
+				}
+			}
+
+			fn(t, name, err)
 		}
 	}
 
 	return nil
 }
 
+// readManifest reads and decodes a manifest.
+func (a *Asset) readManifest(file string) (*AssetManifest, *Resource, error) {
+	m := NewAssetManifest()
+
+	r, err := NewResource(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := a.ReadResource(r); err != nil {
+		return nil, nil, err
+	}
+
+	if err := json.Unmarshal(r.Bytes(), m); err != nil {
+		return nil, nil, err
+	}
+
+	return m, r, nil
+}
+
+// Load loads a single asset from a file with the handler of the given kind.
+func (a *Asset) Load(kind, filename string) error {
+	h, err := a.GetHandler(kind)
+	if err != nil {
+		return err
+	}
+
+	return a.loadAsset(h, filename)
+}
+
+// loadAsset reads the resource and loads it with the handler. The files read
+// while loading are recorded so that the asset can be reloaded when they
+// change.
+func (a *Asset) loadAsset(h AssetHandler, filename string) error {
+	r, err := NewResource(filename)
+	if err != nil {
+		return err
+	}
+
+	t := a.beginTracking()
+
+	err = a.ReadResource(r)
+	if err == nil {
+		a.refs.beginLoad(a.sourceName(r))
+		err = h.Load(r)
+		a.refs.endLoad()
+	}
+
+	files := a.endTracking(t)
+
+	if err != nil {
+		return err
+	}
+
+	a.watch(h.Name(), filename, files)
+
+	return nil
+}
+
+// ReadResource reads the contents of the resource into its buffer.
 func (a *Asset) ReadResource(r *Resource) error {
+	if err := a.readResource(r); err != nil {
+		return err
+	}
+
+	a.track(r)
+
+	return nil
+}
+
+func (a *Asset) readResource(r *Resource) error {
 	if r == nil {
 		return nil
 	}
@@ -257,12 +535,26 @@ func (a *Asset) ReadResource(r *Resource) error {
 
 		return err
 	case ResourcePackage:
-		p, ok := a.packages[r.container]
-		if !ok {
+		p := a.mountedPackage(r.container)
+		if p == nil {
 			return ErrPackageNotMounted(r.container)
 		}
 
 		return p.Read(r.location, r.buffer)
+	case ResourceVFS:
+		err := a.vfs.Read(r.location, r.buffer)
+
+		// Relative paths which no mounted source has are read from the
+		// working directory.
+		switch err.(type) {
+		case ErrVFSFileNotFound, ErrVFSInvalidPath:
+			if info, serr := os.Stat(r.location); serr == nil && !info.IsDir() {
+				r.resType = ResourceFile
+				return a.readResource(r)
+			}
+		}
+
+		return err
 	case ResourceBindata:
 		data, err := builtin.Asset(r.location)
 		if err != nil {
@@ -346,9 +638,28 @@ func (a *Asset) MustGetAsset(kind, name string) Object {
 	return asset
 }
 
-// ReleaseAll releases all builtin managed by this asset store.
+// ReleaseAll releases all assets managed by this asset store, whether they
+// are referenced or not.
 func (a *Asset) ReleaseAll() {
+	a.mu.RLock()
+	handlers := make([]AssetHandler, 0, len(a.handlers))
+	for _, h := range a.handlers {
+		handlers = append(handlers, h)
+	}
+	a.mu.RUnlock()
+
+	var ids []uint32
 
+	for _, h := range handlers {
+		for _, name := range h.Names() {
+			if id, err := h.Remove(name); err == nil {
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	a.refs.forget(ids...)
+	GetInstance().Release(ids...)
 }
 
 // Count reports the total number of assets managed by this asset store.
@@ -394,6 +705,16 @@ func (h *BaseAssetHandler) GetAsset(name string) (Object, error) {
 	}
 }
 
+// has reports if an asset is tracked by name.
+func (h *BaseAssetHandler) has(name string) bool {
+	h.Mu.RLock()
+	defer h.Mu.RUnlock()
+
+	_, ok := h.Items[name]
+
+	return ok
+}
+
 // MustGetAsset is like GetAsset, but panics if an error occurs.
 func (h *BaseAssetHandler) MustGetAsset(name string) Object {
 	h.Mu.RLock()
@@ -415,12 +736,73 @@ func (h *BaseAssetHandler) Count() int {
 	return len(h.Items)
 }
 
+// Names returns the names of the assets tracked by this handler, sorted.
+func (h *BaseAssetHandler) Names() []string {
+	h.Mu.RLock()
+	defer h.Mu.RUnlock()
+
+	names := make([]string, 0, len(h.Items))
+	for name := range h.Items {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Remove stops tracking an asset and returns its instance ID. The asset is
+// not released.
+func (h *BaseAssetHandler) Remove(name string) (uint32, error) {
+	h.Mu.Lock()
+	defer h.Mu.Unlock()
+
+	id, ok := h.Items[name]
+	if !ok {
+		return 0, ErrAssetNotFound(name)
+	}
+
+	delete(h.Items, name)
+
+	return id, nil
+}
+
+// addPart tracks the ID of an object belonging to another asset by name, such
+// as a mesh of a model. Parts record no source, so they are not unloaded on
+// their own.
+func (h *BaseAssetHandler) addPart(name string, id uint32) error {
+	h.Mu.Lock()
+	defer h.Mu.Unlock()
+
+	if _, dup := h.Items[name]; dup {
+		return ErrAssetExists(name)
+	}
+
+	h.Items[name] = id
+
+	return nil
+}
+
+// addItem tracks the asset ID by name, and records where it was loaded from.
+// Handlers call it from Add, after allocating the asset.
+func (h *BaseAssetHandler) addItem(name string, id uint32) {
+	h.Items[name] = id
+
+	GetAsset().refs.added(id)
+}
+
 func NewAsset() *Asset {
-	return &Asset{
+	a := &Asset{
 		handlers: make(map[string]AssetHandler),
-		packages: make(map[string]*Package),
+		vfs:      NewVFS(),
+		reload:   newAssetReload(),
+		budget:   defaultUploadBudget,
+		refs:     newAssetRefs(),
 		mu:       &sync.RWMutex{},
 	}
+
+	a.vfs.Mount(NewBindataSource(), MountPriorityBuiltin)
+
+	return a
 }
 
 // GetAsset gets the asset system from the current app.
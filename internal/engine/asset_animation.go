@@ -0,0 +1,167 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"fmt"
+	"sync"
+)
+
+const (
+	AssetNameAnimation = "animation"
+)
+
+var _ AssetHandler = &AnimationHandler{}
+var _ AssetDecoder = &AnimationHandler{}
+var _ AssetReloader = &AnimationHandler{}
+var _ AssetVerifier = &AnimationHandler{}
+
+// AnimationHandler manages animation clips. Clips are loaded from animation
+// clip documents in JSON or YAML, chosen by the file extension, and are named
+// by their filename.
+type AnimationHandler struct {
+	BaseAssetHandler
+}
+
+// Load will load data from the reader.
+func (h *AnimationHandler) Load(r *Resource) error {
+	upload, err := h.Decode(r)
+	if err != nil {
+		return err
+	}
+
+	return upload()
+}
+
+// Decode parses the clip document, and returns a function creating the clip.
+func (h *AnimationHandler) Decode(r *Resource) (func() error, error) {
+	name := r.Base()
+
+	doc, err := decodeAnimationDocument(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return func() error {
+		if h.has(name) {
+			return ErrAssetExists(name)
+		}
+
+		c := NewAnimationClip(doc.Curves)
+		c.SetName(name)
+
+		return h.Add(name, c)
+	}, nil
+}
+
+// Reload replaces the curves of the clip.
+func (h *AnimationHandler) Reload(r *Resource) error {
+	c, err := h.Get(r.Base())
+	if err != nil {
+		return err
+	}
+
+	doc, err := decodeAnimationDocument(r)
+	if err != nil {
+		return err
+	}
+
+	c.SetCurves(doc.Curves)
+
+	return nil
+}
+
+// Verify checks that the resource parses as an animation clip document.
+func (h *AnimationHandler) Verify(r *Resource) error {
+	_, err := decodeAnimationDocument(r)
+
+	return err
+}
+
+func (h *AnimationHandler) Add(name string, clip *AnimationClip) error {
+	h.Mu.Lock()
+	defer h.Mu.Unlock()
+
+	if _, dup := h.Items[name]; dup {
+		return ErrAssetExists(name)
+	}
+
+	h.addItem(name, clip.ID())
+
+	return nil
+}
+
+// Get gets an asset by name.
+func (h *AnimationHandler) Get(name string) (*AnimationClip, error) {
+	a, err := h.GetAsset(name)
+	if err != nil {
+		return nil, err
+	}
+
+	a2, ok := a.(*AnimationClip)
+	if !ok {
+		return nil, ErrAssetType(name)
+	}
+
+	return a2, nil
+}
+
+// MustGet is like GetAsset, but panics if an error occurs.
+func (h *AnimationHandler) MustGet(name string) *AnimationClip {
+	a, err := h.Get(name)
+	if err != nil {
+		panic(err)
+	}
+
+	return a
+}
+
+func (h *AnimationHandler) Name() string {
+	return AssetNameAnimation
+}
+
+func NewAnimationHandler() *AnimationHandler {
+	h := &AnimationHandler{}
+	h.Items = make(map[string]uint32)
+	h.Mu = &sync.RWMutex{}
+
+	return h
+}
+
+// decodeAnimationDocument reads an animation clip document.
+func decodeAnimationDocument(r *Resource) (*AnimationClipDocument, error) {
+	format, err := SceneFormatFromFilename(r.Base())
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &AnimationClipDocument{}
+	if err := decodeDocument(r.Reader(), format, doc); err != nil {
+		return nil, fmt.Errorf("%s: %v", r.Base(), err)
+	}
+	if err := doc.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %v", r.Base(), err)
+	}
+
+	return doc, nil
+}
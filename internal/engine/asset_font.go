@@ -33,6 +33,8 @@ const (
 )
 
 var _ AssetHandler = &FontHandler{}
+var _ AssetVerifier = &FontHandler{}
+var _ AssetDecoder = &FontHandler{}
 
 type FontHandler struct {
 	BaseAssetHandler
@@ -40,21 +42,40 @@ type FontHandler struct {
 
 // Load will load data from the reader.
 func (h *FontHandler) Load(r *Resource) error {
-	name := r.Base()
-
-	if _, dup := h.Items[name]; dup {
-		return ErrAssetExists(name)
+	upload, err := h.Decode(r)
+	if err != nil {
+		return err
 	}
 
+	return upload()
+}
+
+// Decode parses the font, and returns a function creating it.
+func (h *FontHandler) Decode(r *Resource) (func() error, error) {
+	name := r.Base()
+
 	ttf, err := truetype.Parse(r.Bytes())
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	f := NewFont(ttf, ASCII)
-	f.SetName(name)
+	return func() error {
+		if h.has(name) {
+			return ErrAssetExists(name)
+		}
+
+		f := NewFont(ttf, ASCII)
+		f.SetName(name)
+
+		return h.Add(name, f)
+	}, nil
+}
+
+// Verify checks that the resource parses as a TrueType font.
+func (h *FontHandler) Verify(r *Resource) error {
+	_, err := truetype.Parse(r.Bytes())
 
-	return h.Add(name, f)
+	return err
 }
 
 func (h *FontHandler) Add(name string, font *Font) error {
@@ -66,7 +87,7 @@ func (h *FontHandler) Add(name string, font *Font) error {
 		return err
 	}
 
-	h.Items[name] = font.ID()
+	h.addItem(name, font.ID())
 
 	return nil
 }
@@ -0,0 +1,513 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	AssetNameModel = "model"
+)
+
+var _ AssetHandler = &ModelHandler{}
+var _ AssetVerifier = &ModelHandler{}
+
+// ModelHandler manages models loaded from glTF 2.0 files (.gltf and .glb).
+type ModelHandler struct {
+	BaseAssetHandler
+}
+
+// Load will load data from the reader.
+func (h *ModelHandler) Load(r *Resource) error {
+	name := r.Base()
+
+	if h.has(name) {
+		return ErrAssetExists(name)
+	}
+
+	doc, err := decodeGLTF(r.Bytes())
+	if err != nil {
+		return fmt.Errorf("%s: %v", name, err)
+	}
+
+	readFile := gltfFileReader(r.DirPrefix())
+
+	if err := doc.loadBuffers(readFile); err != nil {
+		return fmt.Errorf("%s: %v", name, err)
+	}
+
+	b := &gltfBuilder{
+		name:     name,
+		doc:      doc,
+		readFile: readFile,
+		textures: make(map[int]*Texture2D),
+	}
+
+	model, err := b.build()
+	if err != nil {
+		return fmt.Errorf("%s: %v", name, err)
+	}
+
+	return h.Add(name, model)
+}
+
+// Verify checks that the resource decodes as a glTF document, and that its
+// buffers, primitives and images can be read.
+func (h *ModelHandler) Verify(r *Resource) error {
+	doc, err := decodeGLTF(r.Bytes())
+	if err != nil {
+		return fmt.Errorf("%s: %v", r.Base(), err)
+	}
+
+	readFile := gltfFileReader(r.DirPrefix())
+
+	if err := doc.loadBuffers(readFile); err != nil {
+		return fmt.Errorf("%s: %v", r.Base(), err)
+	}
+
+	for i := range doc.Meshes {
+		for j := range doc.Meshes[i].Primitives {
+			if _, _, _, _, err := doc.primitive(&doc.Meshes[i].Primitives[j]); err != nil {
+				return fmt.Errorf("%s: mesh %d primitive %d: %v", r.Base(), i, j, err)
+			}
+		}
+	}
+
+	for i := range doc.Images {
+		data, err := doc.imageData(i, readFile)
+		if err != nil {
+			return fmt.Errorf("%s: image %d: %v", r.Base(), i, err)
+		}
+		if _, _, err := image.Decode(bytes.NewReader(data)); err != nil {
+			return fmt.Errorf("%s: image %d: %v", r.Base(), i, err)
+		}
+	}
+
+	return nil
+}
+
+// Add tracks the model by name. Its meshes and materials are tracked by the
+// mesh and material handlers under names derived from the model name.
+func (h *ModelHandler) Add(name string, model *Model) error {
+	h.Mu.Lock()
+	defer h.Mu.Unlock()
+
+	if _, dup := h.Items[name]; dup {
+		return ErrAssetExists(name)
+	}
+
+	if err := model.addParts(name); err != nil {
+		return err
+	}
+
+	h.addItem(name, model.ID())
+
+	return nil
+}
+
+// Get gets an asset by name.
+func (h *ModelHandler) Get(name string) (*Model, error) {
+	a, err := h.GetAsset(name)
+	if err != nil {
+		return nil, err
+	}
+
+	a2, ok := a.(*Model)
+	if !ok {
+		return nil, ErrAssetType(name)
+	}
+
+	return a2, nil
+}
+
+// MustGet is like GetAsset, but panics if an error occurs.
+func (h *ModelHandler) MustGet(name string) *Model {
+	a, err := h.Get(name)
+	if err != nil {
+		panic(err)
+	}
+
+	return a
+}
+
+func (h *ModelHandler) Name() string {
+	return AssetNameModel
+}
+
+func NewModelHandler() *ModelHandler {
+	h := &ModelHandler{}
+	h.Items = make(map[string]uint32)
+	h.Mu = &sync.RWMutex{}
+
+	return h
+}
+
+// gltfFileReader returns a function which reads files referenced by URI,
+// relative to dir.
+func gltfFileReader(dir string) func(uri string) ([]byte, error) {
+	return func(uri string) ([]byte, error) {
+		if u, err := url.PathUnescape(uri); err == nil {
+			uri = u
+		}
+
+		r, err := NewResource(filepath.Join(dir, uri))
+		if err != nil {
+			return nil, err
+		}
+		if err := GetAsset().ReadResource(r); err != nil {
+			return nil, err
+		}
+
+		return r.Bytes(), nil
+	}
+}
+
+// gltfBuilder creates the meshes, materials, textures and skeletons of a glTF
+// document.
+type gltfBuilder struct {
+	name      string
+	doc       *gltfDocument
+	readFile  func(uri string) ([]byte, error)
+	meshes    [][]*Mesh
+	materials [][]*Material
+	textures  map[int]*Texture2D
+	skeletons []*Skeleton
+}
+
+func (b *gltfBuilder) build() (*Model, error) {
+	materials := make([]*Material, len(b.doc.Materials))
+	for i := range b.doc.Materials {
+		m, err := b.material(i)
+		if err != nil {
+			return nil, err
+		}
+
+		materials[i] = m
+	}
+
+	roots, err := b.doc.rootNodes()
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(b.doc.Nodes))
+	for i := range b.doc.Nodes {
+		names[i] = b.doc.Nodes[i].Name
+		if names[i] == "" {
+			names[i] = fmt.Sprintf("node%d", i)
+		}
+	}
+
+	orders, err := b.skins(names)
+	if err != nil {
+		return nil, err
+	}
+
+	meshOrders, err := b.meshJointOrders(orders)
+	if err != nil {
+		return nil, err
+	}
+
+	b.meshes = make([][]*Mesh, len(b.doc.Meshes))
+	b.materials = make([][]*Material, len(b.doc.Meshes))
+
+	for i := range b.doc.Meshes {
+		for j := range b.doc.Meshes[i].Primitives {
+			p := &b.doc.Meshes[i].Primitives[j]
+
+			if p.Mode != nil && *p.Mode != gltfModeTriangles {
+				logrus.Warnf("%s: mesh %d primitive %d: unsupported mode %d", b.name, i, j, *p.Mode)
+				continue
+			}
+
+			mesh, err := b.mesh(p, meshOrders[i])
+			if err != nil {
+				return nil, fmt.Errorf("mesh %d primitive %d: %v", i, j, err)
+			}
+
+			material := NewMaterialPBR()
+			if p.Material != nil {
+				if *p.Material < 0 || *p.Material >= len(materials) {
+					return nil, ErrGLTFInvalid
+				}
+				material = materials[*p.Material]
+			}
+
+			b.meshes[i] = append(b.meshes[i], mesh)
+			b.materials[i] = append(b.materials[i], material)
+		}
+	}
+
+	nodes := make([]ModelNode, len(b.doc.Nodes))
+	for i := range b.doc.Nodes {
+		n := &b.doc.Nodes[i]
+
+		nodes[i].Name = names[i]
+		nodes[i].Position, nodes[i].Rotation, nodes[i].Scale = gltfNodeTransform(n)
+
+		nodes[i].Children = n.Children
+
+		if n.Mesh != nil {
+			if *n.Mesh < 0 || *n.Mesh >= len(b.meshes) {
+				return nil, ErrGLTFInvalid
+			}
+			nodes[i].Meshes = b.meshes[*n.Mesh]
+			nodes[i].Materials = b.materials[*n.Mesh]
+
+			if n.Skin != nil {
+				nodes[i].Skeleton = b.skeletons[*n.Skin]
+			}
+		}
+	}
+
+	model := NewModel()
+	model.SetName(b.name)
+	model.SetNodes(nodes, roots)
+
+	return model, nil
+}
+
+// skins creates the skeletons of the skins, and returns the order of the
+// joints of each skin in its skeleton.
+func (b *gltfBuilder) skins(names []string) ([][]int, error) {
+	b.skeletons = make([]*Skeleton, len(b.doc.Skins))
+	orders := make([][]int, len(b.doc.Skins))
+
+	for i := range b.doc.Skins {
+		joints, order, err := b.doc.skinJoints(i, names)
+		if err != nil {
+			return nil, fmt.Errorf("skin %d: %v", i, err)
+		}
+
+		s, err := NewSkeleton(joints)
+		if err != nil {
+			return nil, fmt.Errorf("skin %d: %v", i, err)
+		}
+		if name := b.doc.Skins[i].Name; name != "" {
+			s.SetName(name)
+		}
+
+		b.skeletons[i] = s
+		orders[i] = order
+	}
+
+	return orders, nil
+}
+
+// meshJointOrders returns the order of the joints of the skin used by each
+// mesh, or nil for meshes which are not skinned by any node. Meshes shared by
+// nodes with different skins must order their joints the same way.
+func (b *gltfBuilder) meshJointOrders(orders [][]int) ([][]int, error) {
+	meshOrders := make([][]int, len(b.doc.Meshes))
+
+	for i := range b.doc.Nodes {
+		n := &b.doc.Nodes[i]
+		if n.Skin == nil {
+			continue
+		}
+		if *n.Skin < 0 || *n.Skin >= len(orders) {
+			return nil, ErrGLTFInvalid
+		}
+		if n.Mesh == nil || *n.Mesh < 0 || *n.Mesh >= len(meshOrders) {
+			continue
+		}
+
+		order := orders[*n.Skin]
+		if prev := meshOrders[*n.Mesh]; prev != nil && !equalInts(prev, order) {
+			return nil, fmt.Errorf("gltf: mesh %d is used by skins with different joints", *n.Mesh)
+		}
+		meshOrders[*n.Mesh] = order
+	}
+
+	return meshOrders, nil
+}
+
+// mesh creates and allocates an indexed mesh for a primitive. The joint
+// indices of skinned primitives are mapped to the skeleton with order, unless
+// it is nil.
+func (b *gltfBuilder) mesh(p *gltfPrimitive, order []int) (*Mesh, error) {
+	v, n, t, indices, err := b.doc.primitive(p)
+	if err != nil {
+		return nil, err
+	}
+
+	joints, weights, err := b.doc.primitiveSkin(p, len(v))
+	if err != nil {
+		return nil, err
+	}
+	if order != nil {
+		for i := range joints {
+			for k, j := range joints[i] {
+				if int(j) >= len(order) {
+					return nil, ErrGLTFInvalid
+				}
+				joints[i][k] = uint16(order[j])
+			}
+		}
+	}
+
+	m := NewMesh()
+	m.SetVertices(v)
+	m.SetNormals(n)
+	m.SetUvs(t)
+	m.SetTriangles(indices)
+	m.SetJoints(joints)
+	m.SetWeights(weights)
+
+	if err := m.Alloc(); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// material creates a PBR material for a glTF metallic-roughness material.
+func (b *gltfBuilder) material(index int) (*Material, error) {
+	g := &b.doc.Materials[index]
+
+	m := NewMaterialPBR()
+	if g.Name != "" {
+		m.SetName(g.Name)
+	}
+
+	albedo := mgl32.Vec3{1, 1, 1}
+	metallic := float32(1)
+	roughness := float32(1)
+
+	if pbr := g.PBRMetallicRoughness; pbr != nil {
+		if pbr.BaseColorFactor != nil {
+			albedo = pbr.BaseColorFactor.Vec3()
+		}
+		if pbr.MetallicFactor != nil {
+			metallic = *pbr.MetallicFactor
+		}
+		if pbr.RoughnessFactor != nil {
+			roughness = *pbr.RoughnessFactor
+		}
+
+		if err := b.setTexture(m, MaterialTextureAlbedo, pbr.BaseColorTexture); err != nil {
+			return nil, err
+		}
+		if err := b.setTexture(m, MaterialTextureMetallic, pbr.MetallicRoughnessTexture); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := b.setTexture(m, MaterialTextureNormal, g.NormalTexture); err != nil {
+		return nil, err
+	}
+
+	m.SetProperty("f_albedo", albedo)
+	m.SetProperty("f_metallic", metallic)
+	m.SetProperty("f_roughness", roughness)
+
+	return m, nil
+}
+
+func (b *gltfBuilder) setTexture(m *Material, id MaterialTexture, info *gltfTextureInfo) error {
+	if info == nil {
+		return nil
+	}
+
+	texture, err := b.texture(info.Index)
+	if err != nil {
+		return err
+	}
+
+	m.SetTexture(id, texture)
+
+	return nil
+}
+
+// texture loads the image of a glTF texture through the image handler.
+// External images are named by their filename, and embedded images by the
+// model name and image index.
+func (b *gltfBuilder) texture(index int) (*Texture2D, error) {
+	if t, ok := b.textures[index]; ok {
+		return t, nil
+	}
+
+	if index < 0 || index >= len(b.doc.Textures) || b.doc.Textures[index].Source == nil {
+		return nil, ErrGLTFInvalid
+	}
+
+	source := *b.doc.Textures[index].Source
+	if source < 0 || source >= len(b.doc.Images) {
+		return nil, ErrGLTFInvalid
+	}
+
+	ah, err := GetAsset().GetHandler(AssetNameImage)
+	if err != nil {
+		return nil, err
+	}
+	images := ah.(*ImageHandler)
+
+	img := b.doc.Images[source]
+
+	name := fmt.Sprintf("%s#image%d", b.name, source)
+	if img.BufferView == nil && img.URI != "" && !strings.HasPrefix(img.URI, "data:") {
+		name = filepath.Base(img.URI)
+	}
+
+	t, err := images.Get(name)
+	if err != nil {
+		data, err := b.doc.imageData(source, b.readFile)
+		if err != nil {
+			return nil, err
+		}
+		if err := images.LoadReader(name, bytes.NewReader(data)); err != nil {
+			return nil, err
+		}
+		if t, err = images.Get(name); err != nil {
+			return nil, err
+		}
+	}
+
+	b.textures[index] = t
+
+	return t, nil
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -27,6 +27,7 @@ import (
 	"image"
 	"image/color"
 	"image/draw"
+	"io"
 	"sync"
 
 	"github.com/haakenlabs/forge/internal/math"
@@ -41,6 +42,9 @@ const (
 )
 
 var _ AssetHandler = &ImageHandler{}
+var _ AssetVerifier = &ImageHandler{}
+var _ AssetReloader = &ImageHandler{}
+var _ AssetDecoder = &ImageHandler{}
 
 type ImageHandler struct {
 	BaseAssetHandler
@@ -48,77 +52,136 @@ type ImageHandler struct {
 
 // Load will load data from the reader.
 func (h *ImageHandler) Load(r *Resource) error {
-	var texture *Texture2D
-	var img image.Image
-
-	name := r.Base()
+	return h.LoadReader(r.Base(), r.Reader())
+}
 
-	if _, dup := h.Items[r.Base()]; dup {
-		return ErrAssetExists(name)
+// LoadReader decodes an image from the reader and adds it by name. This is
+// used for images which are embedded in other assets.
+func (h *ImageHandler) LoadReader(name string, reader io.Reader) error {
+	upload, err := h.decodeReader(name, reader)
+	if err != nil {
+		return err
 	}
 
-	img, _, err := image.Decode(r.Reader())
+	return upload()
+}
+
+// Decode decodes the image, and returns a function creating its texture.
+func (h *ImageHandler) Decode(r *Resource) (func() error, error) {
+	return h.decodeReader(r.Base(), r.Reader())
+}
+
+func (h *ImageHandler) decodeReader(name string, reader io.Reader) (func() error, error) {
+	size, format, data, err := decodeImageData(reader)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	x := int32(img.Bounds().Dx())
-	y := int32(img.Bounds().Dy())
+	return func() error {
+		if h.has(name) {
+			return ErrAssetExists(name)
+		}
+
+		texture := NewTexture2D(size, TextureFormatDefaultColor)
+		texture.SetTexFormat(format)
+		texture.SetData(data)
+
+		return h.Add(name, texture)
+	}, nil
+}
+
+// decodeImageData decodes an image from the reader, returning its size, the
+// texture format matching its color model and its pixel data.
+func decodeImageData(reader io.Reader) (math.IVec2, TextureFormat, []uint8, error) {
+	img, _, err := image.Decode(reader)
+	if err != nil {
+		return math.IVec2{}, 0, nil, err
+	}
 
-	texture = NewTexture2D(math.IVec2{x, y}, TextureFormatDefaultColor)
+	size := math.IVec2{int32(img.Bounds().Dx()), int32(img.Bounds().Dy())}
 
 	switch img.ColorModel() {
 	// 4 channels, 16 bits per channel
 	case color.RGBA64Model:
 		rgba := image.NewRGBA64(img.Bounds())
 		draw.Draw(rgba, rgba.Bounds(), img, image.Point{}, draw.Src)
-		texture.SetTexFormat(TextureFormatRGBA16)
-		texture.SetData(rgba.Pix)
+		return size, TextureFormatRGBA16, rgba.Pix, nil
 		// 4 channels, 8 bits per channel
 	case color.RGBAModel:
 		rgba := image.NewRGBA(img.Bounds())
 		draw.Draw(rgba, rgba.Bounds(), img, image.Point{}, draw.Src)
-		texture.SetTexFormat(TextureFormatRGBA8)
-		texture.SetData(rgba.Pix)
+		return size, TextureFormatRGBA8, rgba.Pix, nil
 		// 2 channels, 16 bits per channel
 	case color.Alpha16Model:
 		alpha := image.NewAlpha16(img.Bounds())
 		draw.Draw(alpha, alpha.Bounds(), img, image.Point{}, draw.Src)
-		texture.SetTexFormat(TextureFormatRG16)
-		texture.SetData(alpha.Pix)
+		return size, TextureFormatRG16, alpha.Pix, nil
 		// 2 channels, 8 bits per channel
 	case color.AlphaModel:
 		alpha := image.NewAlpha(img.Bounds())
 		draw.Draw(alpha, alpha.Bounds(), img, image.Point{}, draw.Src)
-		texture.SetTexFormat(TextureFormatRG8)
-		texture.SetData(alpha.Pix)
+		return size, TextureFormatRG8, alpha.Pix, nil
 		// 1 channel, 16 bits per channel
 	case color.Gray16Model:
 		gray := image.NewGray16(img.Bounds())
 		draw.Draw(gray, gray.Bounds(), img, image.Point{}, draw.Src)
-		texture.SetTexFormat(TextureFormatR16)
-		texture.SetData(gray.Pix)
+		return size, TextureFormatR16, gray.Pix, nil
 		// 1 channel, 16 bits per channel
 	case color.GrayModel:
 		gray := image.NewGray(img.Bounds())
 		draw.Draw(gray, gray.Bounds(), img, image.Point{}, draw.Src)
-		texture.SetTexFormat(TextureFormatR8)
-		texture.SetData(gray.Pix)
+		return size, TextureFormatR8, gray.Pix, nil
 	case color.NRGBA64Model:
 		rgba := image.NewNRGBA64(img.Bounds())
 		draw.Draw(rgba, rgba.Bounds(), img, image.Point{}, draw.Src)
-		texture.SetTexFormat(TextureFormatRGBA16)
-		texture.SetData(rgba.Pix)
+		return size, TextureFormatRGBA16, rgba.Pix, nil
 	case color.NRGBAModel:
 		rgba := image.NewNRGBA(img.Bounds())
 		draw.Draw(rgba, rgba.Bounds(), img, image.Point{}, draw.Src)
-		texture.SetTexFormat(TextureFormatRGBA8)
-		texture.SetData(rgba.Pix)
+		return size, TextureFormatRGBA8, rgba.Pix, nil
 	default:
-		return fmt.Errorf("invalid color format: %v", img.ColorModel())
+		return math.IVec2{}, 0, nil, fmt.Errorf("invalid color format: %v", img.ColorModel())
 	}
+}
+
+// Reload decodes the resource into the existing texture of the same name.
+func (h *ImageHandler) Reload(r *Resource) error {
+	texture, err := h.Get(r.Base())
+	if err != nil {
+		return err
+	}
+
+	size, format, data, err := decodeImageData(r.Reader())
+	if err != nil {
+		return err
+	}
+
+	texture.SetTexFormat(format)
+	texture.SetData(data)
+
+	return texture.SetSize(size)
+}
+
+// Verify checks that the resource decodes as an image.
+func (h *ImageHandler) Verify(r *Resource) error {
+	_, _, err := image.Decode(r.Reader())
+
+	return err
+}
+
+// verifyImageFile checks that the file can be read and decodes as an image.
+func verifyImageFile(filename string) error {
+	r, err := NewResource(filename)
+	if err != nil {
+		return err
+	}
+	if err := GetAsset().ReadResource(r); err != nil {
+		return err
+	}
+
+	_, _, err = image.Decode(r.Reader())
 
-	return h.Add(name, texture)
+	return err
 }
 
 func (h *ImageHandler) Add(name string, texture *Texture2D) error {
@@ -130,7 +193,7 @@ func (h *ImageHandler) Add(name string, texture *Texture2D) error {
 		return err
 	}
 
-	h.Items[name] = texture.ID()
+	h.addItem(name, texture.ID())
 
 	return nil
 }
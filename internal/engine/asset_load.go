@@ -0,0 +1,360 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package engine
+
+import (
+	"path"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// defaultUploadBudget is the time spent uploading decoded assets per frame.
+const defaultUploadBudget = 8 * time.Millisecond
+
+// assetJob is a single asset of an asynchronous load.
+type assetJob struct {
+	handler  AssetHandler
+	filename string
+	source   string
+	upload   func() error
+	files    []string
+	err      error
+	decoded  bool
+}
+
+// AssetLoad is a handle to an asynchronous load of assets. Assets are read
+// and decoded by a pool of goroutines, then uploaded on the main thread by
+// Asset.Upload in the order they are listed in their manifests. Assets which
+// fail to load are logged and skipped; the load continues with the rest.
+type AssetLoad struct {
+	jobs     []*assetJob
+	next     int
+	decoded  int
+	uploaded int
+	errs     []error
+	canceled bool
+	wg       *sync.WaitGroup
+	mu       *sync.Mutex
+}
+
+func newAssetLoad() *AssetLoad {
+	return &AssetLoad{
+		wg: &sync.WaitGroup{},
+		mu: &sync.Mutex{},
+	}
+}
+
+// Progress returns the progress of the load, from 0 to 1. Decoding and
+// uploading each account for half of the progress of an asset.
+func (l *AssetLoad) Progress() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.jobs) == 0 {
+		return 1
+	}
+
+	return float64(l.decoded+l.uploaded) / float64(2*len(l.jobs))
+}
+
+// Total returns the number of assets in the load.
+func (l *AssetLoad) Total() int {
+	return len(l.jobs)
+}
+
+// Loaded returns the number of assets which have finished loading, including
+// those which failed.
+func (l *AssetLoad) Loaded() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.uploaded
+}
+
+// Done reports if all assets have finished loading.
+func (l *AssetLoad) Done() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.uploaded == len(l.jobs)
+}
+
+// Err returns the first error encountered by the load, if any.
+func (l *AssetLoad) Err() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.errs) == 0 {
+		return nil
+	}
+
+	return l.errs[0]
+}
+
+// Errors returns all errors encountered by the load.
+func (l *AssetLoad) Errors() []error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return append([]error(nil), l.errs...)
+}
+
+// decode reads and decodes the asset of the job. Handlers which do not
+// implement AssetDecoder are loaded entirely in the upload phase.
+func (l *AssetLoad) decode(a *Asset, job *assetJob, track bool) {
+	r, err := NewResource(job.filename)
+
+	var t *assetTracker
+	if track {
+		t = a.beginTracking()
+	}
+
+	if err == nil {
+		err = a.ReadResource(r)
+	}
+	if err == nil {
+		job.source = a.sourceName(r)
+
+		if d, ok := job.handler.(AssetDecoder); ok {
+			job.upload, err = d.Decode(r)
+		} else {
+			job.upload = func() error {
+				return job.handler.Load(r)
+			}
+		}
+	}
+
+	if track {
+		job.files = a.endTracking(t)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	job.err = err
+	job.decoded = true
+	l.decoded++
+}
+
+// pending returns the next job to upload, or nil if it is not decoded yet.
+func (l *AssetLoad) pending() *assetJob {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.next == len(l.jobs) || !l.jobs[l.next].decoded {
+		return nil
+	}
+
+	job := l.jobs[l.next]
+	l.next++
+
+	return job
+}
+
+func (l *AssetLoad) isCanceled() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.canceled
+}
+
+func (l *AssetLoad) finish(err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err != nil {
+		l.errs = append(l.errs, err)
+	}
+
+	l.uploaded++
+}
+
+// SetLoadWorkers sets the number of goroutines decoding assets for each
+// asynchronous load. If n is less than one, the number of CPUs is used. It can
+// also be set with the configuration key asset.workers.
+func (a *Asset) SetLoadWorkers(n int) {
+	a.workers = n
+}
+
+// SetUploadBudget sets the time spent uploading decoded assets per frame. It
+// can also be set with the configuration key asset.uploadbudget.
+func (a *Asset) SetUploadBudget(budget time.Duration) {
+	a.budget = budget
+}
+
+// LoadManifestAsync starts loading the assets of the manifests, and returns a
+// handle to follow its progress. Asset kinds are loaded in name order. The
+// manifests themselves are read before returning.
+//
+// While hot reloading is enabled, assets are decoded by a single goroutine so
+// the files read by each asset can be recorded.
+func (a *Asset) LoadManifestAsync(files ...string) (*AssetLoad, error) {
+	l := newAssetLoad()
+
+	for _, file := range files {
+		m, r, err := a.readManifest(file)
+		if err != nil {
+			return nil, err
+		}
+
+		kinds := make([]string, 0, len(m.Assets))
+		for t := range m.Assets {
+			kinds = append(kinds, t)
+		}
+		sort.Strings(kinds)
+
+		for _, t := range kinds {
+			h, err := a.GetHandler(t)
+			if err != nil {
+				logrus.Error(err)
+				continue
+			}
+
+			for _, name := range m.Assets[t] {
+				l.jobs = append(l.jobs, &assetJob{
+					handler:  h,
+					filename: path.Join(r.DirPrefix(), name),
+				})
+			}
+		}
+	}
+
+	a.startDecode(l)
+	a.loads = append(a.loads, l)
+
+	return l, nil
+}
+
+// startDecode starts the goroutines decoding the assets of the load.
+func (a *Asset) startDecode(l *AssetLoad) {
+	track := a.HotReload()
+
+	workers := a.workers
+	if workers < 1 {
+		workers = runtime.NumCPU()
+	}
+	if track {
+		workers = 1
+	}
+	if workers > len(l.jobs) {
+		workers = len(l.jobs)
+	}
+
+	queue := make(chan *assetJob, len(l.jobs))
+	for _, job := range l.jobs {
+		queue <- job
+	}
+	close(queue)
+
+	l.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer l.wg.Done()
+
+			for job := range queue {
+				if l.isCanceled() {
+					return
+				}
+
+				l.decode(a, job, track)
+			}
+		}()
+	}
+}
+
+// Upload uploads decoded assets of asynchronous loads until the upload budget
+// is spent. At least one asset is uploaded per call when one is ready. It is
+// called by the App once per frame, and must be called on the thread owning
+// the GL context.
+func (a *Asset) Upload() {
+	if len(a.loads) == 0 {
+		return
+	}
+
+	start := time.Now()
+	count := 0
+
+	loads := a.loads[:0]
+	for _, l := range a.loads {
+		for count == 0 || time.Since(start) < a.budget {
+			job := l.pending()
+			if job == nil {
+				break
+			}
+
+			l.finish(a.uploadJob(job))
+			count++
+		}
+
+		if !l.Done() {
+			loads = append(loads, l)
+		}
+	}
+
+	a.loads = loads
+}
+
+// uploadJob uploads the decoded asset and records the files it was read from.
+func (a *Asset) uploadJob(job *assetJob) error {
+	err := job.err
+
+	if err == nil {
+		t := a.beginTracking()
+		a.refs.beginLoad(job.source)
+		err = job.upload()
+		a.refs.endLoad()
+		files := a.endTracking(t)
+
+		if err == nil {
+			a.watch(job.handler.Name(), job.filename, append(job.files, files...))
+		}
+	}
+
+	job.upload = nil
+
+	if err != nil {
+		logrus.Errorf("asset: failed to load %s: %v", job.filename, err)
+		return err
+	}
+
+	logrus.Debug("Loaded asset: ", job.filename)
+
+	return nil
+}
+
+// cancelLoads drops unfinished asynchronous loads, after waiting for their
+// decoding goroutines to exit.
+func (a *Asset) cancelLoads() {
+	for _, l := range a.loads {
+		l.mu.Lock()
+		l.canceled = true
+		l.mu.Unlock()
+
+		l.wg.Wait()
+	}
+
+	a.loads = nil
+}
@@ -0,0 +1,168 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package engine
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// decodingTextHandler loads text files, decoding them off the main thread.
+type decodingTextHandler struct {
+	textHandler
+
+	uploaded []string
+}
+
+func (h *decodingTextHandler) Decode(r *Resource) (func() error, error) {
+	text, err := h.decode(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return func() error {
+		t := &textAsset{text: text}
+		GetInstance().MustAssign(t)
+		h.addItem(r.Base(), t.ID())
+		h.uploaded = append(h.uploaded, r.Base())
+
+		return nil
+	}, nil
+}
+
+func (h *decodingTextHandler) Name() string {
+	return "decodingtext"
+}
+
+func TestAsset_LoadManifestAsync(t *testing.T) {
+	a := newHeadlessApp(t)
+	defer a.Teardown()
+
+	dir, err := ioutil.TempDir("", "load")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"manifest.json": `{"assets": {"text": ["a.txt", "b.txt"], "decodingtext": ["c.txt", "d.txt", "e.txt"]}}`,
+		"a.txt":         "a",
+		"b.txt":         "error",
+		"c.txt":         "c",
+		"d.txt":         "d",
+		"e.txt":         "e",
+	}
+	for name, data := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	text := &textHandler{}
+	text.Items = make(map[string]uint32)
+	text.Mu = &sync.RWMutex{}
+
+	decoding := &decodingTextHandler{}
+	decoding.Items = make(map[string]uint32)
+	decoding.Mu = &sync.RWMutex{}
+
+	asset := GetAsset()
+	if err := asset.RegisterHandler(text); err != nil {
+		t.Fatal(err)
+	}
+	if err := asset.RegisterHandler(decoding); err != nil {
+		t.Fatal(err)
+	}
+
+	asset.SetLoadWorkers(2)
+	asset.SetUploadBudget(0)
+
+	l, err := asset.LoadManifestAsync(filepath.Join(dir, "manifest.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.Total() != 5 {
+		t.Fatal("l.Total() expected 5, got:", l.Total())
+	}
+
+	// With no budget, a single asset is uploaded per call.
+	progress := 0.0
+	deadline := time.Now().Add(5 * time.Second)
+	for !l.Done() {
+		if time.Now().After(deadline) {
+			t.Fatal("load did not finish")
+		}
+
+		loaded := l.Loaded()
+		asset.Upload()
+		if n := l.Loaded(); n > loaded+1 {
+			t.Errorf("Upload() expected to load one asset, loaded: %d", n-loaded)
+		}
+
+		p := l.Progress()
+		if p < progress {
+			t.Errorf("l.Progress() decreased from %f to %f", progress, p)
+		}
+		progress = p
+
+		time.Sleep(time.Millisecond)
+	}
+
+	if p := l.Progress(); p != 1 {
+		t.Error("l.Progress() expected 1, got:", p)
+	}
+	if n := l.Loaded(); n != 5 {
+		t.Error("l.Loaded() expected 5, got:", n)
+	}
+	if errs := l.Errors(); len(errs) != 1 {
+		t.Error("l.Errors() expected 1 error, got:", errs)
+	}
+	if l.Err() == nil {
+		t.Error("l.Err() expected error")
+	}
+
+	if _, err := text.GetAsset("a.txt"); err != nil {
+		t.Error(err)
+	}
+	if _, err := text.GetAsset("b.txt"); err == nil {
+		t.Error("text.GetAsset(b.txt) expected error")
+	}
+
+	expected := []string{"c.txt", "d.txt", "e.txt"}
+	if len(decoding.uploaded) != len(expected) {
+		t.Fatalf("decoding.uploaded expected %v, got: %v", expected, decoding.uploaded)
+	}
+	for i := range expected {
+		if decoding.uploaded[i] != expected[i] {
+			t.Errorf("decoding.uploaded expected %v, got: %v", expected, decoding.uploaded)
+			break
+		}
+	}
+
+	if len(asset.loads) != 0 {
+		t.Error("asset.loads expected empty, got:", len(asset.loads))
+	}
+}
@@ -0,0 +1,342 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const (
+	AssetNameMaterial = "material"
+)
+
+var _ AssetHandler = &MaterialHandler{}
+var _ AssetVerifier = &MaterialHandler{}
+
+// MaterialHandler manages materials. Materials are loaded from Wavefront MTL
+// files, and are named by the materials they define.
+type MaterialHandler struct {
+	BaseAssetHandler
+}
+
+// mtlMaterial is a material parsed from a Wavefront MTL file.
+type mtlMaterial struct {
+	name        string
+	diffuse     mgl32.Vec3
+	shininess   float32
+	roughness   float32
+	metallic    float32
+	hasRough    bool
+	diffuseMap  string
+	normalMap   string
+	metallicMap string
+}
+
+// Load will load data from the reader.
+func (h *MaterialHandler) Load(r *Resource) error {
+	materials, err := parseMTL(r.Reader())
+	if err != nil {
+		return fmt.Errorf("%s: %v", r.Base(), err)
+	}
+
+	for i := range materials {
+		if _, err := h.loadMTLMaterial(materials[i], r.DirPrefix()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Verify checks that the MTL file parses and that its texture maps can be read
+// and decoded.
+func (h *MaterialHandler) Verify(r *Resource) error {
+	materials, err := parseMTL(r.Reader())
+	if err != nil {
+		return fmt.Errorf("%s: %v", r.Base(), err)
+	}
+
+	for _, m := range materials {
+		for _, file := range []string{m.diffuseMap, m.normalMap, m.metallicMap} {
+			if file == "" {
+				continue
+			}
+
+			if err := verifyImageFile(filepath.Join(r.DirPrefix(), file)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// loadMTLMaterial creates a material from the parsed MTL material and adds it
+// to the handler. Texture maps are loaded through the image handler, relative
+// to dir.
+func (h *MaterialHandler) loadMTLMaterial(m *mtlMaterial, dir string) (*Material, error) {
+	material := NewMaterialPBR()
+	material.SetName(m.name)
+
+	roughness := m.roughness
+	if !m.hasRough {
+		// Approximate roughness from the Phong exponent.
+		roughness = float32(math.Sqrt(2.0 / (float64(m.shininess) + 2.0)))
+	}
+
+	material.SetProperty("f_albedo", m.diffuse)
+	material.SetProperty("f_roughness", roughness)
+	material.SetProperty("f_metallic", m.metallic)
+
+	maps := []struct {
+		id   MaterialTexture
+		file string
+	}{
+		{MaterialTextureAlbedo, m.diffuseMap},
+		{MaterialTextureNormal, m.normalMap},
+		{MaterialTextureMetallic, m.metallicMap},
+	}
+
+	for _, v := range maps {
+		if v.file == "" {
+			continue
+		}
+
+		texture, err := loadMaterialTexture(filepath.Join(dir, v.file))
+		if err != nil {
+			return nil, err
+		}
+
+		material.SetTexture(v.id, texture)
+	}
+
+	return material, h.Add(m.name, material)
+}
+
+// loadMaterialTexture gets a texture from the image handler, loading it first
+// if required.
+func loadMaterialTexture(filename string) (*Texture2D, error) {
+	h, err := GetAsset().GetHandler(AssetNameImage)
+	if err != nil {
+		return nil, err
+	}
+	images := h.(*ImageHandler)
+
+	r, err := NewResource(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	if texture, err := images.Get(r.Base()); err == nil {
+		return texture, nil
+	}
+
+	if err := GetAsset().ReadResource(r); err != nil {
+		return nil, err
+	}
+	if err := images.Load(r); err != nil {
+		return nil, err
+	}
+
+	return images.Get(r.Base())
+}
+
+func (h *MaterialHandler) Add(name string, material *Material) error {
+	h.Mu.Lock()
+	defer h.Mu.Unlock()
+
+	if _, dup := h.Items[name]; dup {
+		return ErrAssetExists(name)
+	}
+
+	h.addItem(name, material.ID())
+
+	return nil
+}
+
+// Get gets an asset by name.
+func (h *MaterialHandler) Get(name string) (*Material, error) {
+	a, err := h.GetAsset(name)
+	if err != nil {
+		return nil, err
+	}
+
+	a2, ok := a.(*Material)
+	if !ok {
+		return nil, ErrAssetType(name)
+	}
+
+	return a2, nil
+}
+
+// MustGet is like GetAsset, but panics if an error occurs.
+func (h *MaterialHandler) MustGet(name string) *Material {
+	a, err := h.Get(name)
+	if err != nil {
+		panic(err)
+	}
+
+	return a
+}
+
+func (h *MaterialHandler) Name() string {
+	return AssetNameMaterial
+}
+
+func NewMaterialHandler() *MaterialHandler {
+	h := &MaterialHandler{}
+	h.Items = make(map[string]uint32)
+	h.Mu = &sync.RWMutex{}
+
+	return h
+}
+
+// parseMTL parses the materials in a Wavefront MTL file.
+func parseMTL(r io.Reader) ([]*mtlMaterial, error) {
+	var materials []*mtlMaterial
+	var current *mtlMaterial
+
+	scanner := bufio.NewScanner(r)
+	line := 0
+
+	for scanner.Scan() {
+		line++
+
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		if fields[0] == "newmtl" {
+			if len(fields) < 2 {
+				return nil, fmt.Errorf("line %d: newmtl requires a name", line)
+			}
+
+			current = &mtlMaterial{
+				name:    strings.Join(fields[1:], " "),
+				diffuse: mgl32.Vec3{1, 1, 1},
+			}
+			materials = append(materials, current)
+			continue
+		}
+
+		if current == nil {
+			return nil, fmt.Errorf("line %d: %s before newmtl", line, fields[0])
+		}
+
+		var err error
+
+		switch fields[0] {
+		case "Kd":
+			current.diffuse, err = parseVec3(fields[1:])
+		case "Ns":
+			current.shininess, err = parseFloat(fields[1:])
+		case "Pr":
+			current.roughness, err = parseFloat(fields[1:])
+			current.hasRough = true
+		case "Pm":
+			current.metallic, err = parseFloat(fields[1:])
+		case "map_Kd":
+			current.diffuseMap = mapFilename(fields[1:])
+		case "map_Bump", "map_bump", "bump", "norm":
+			current.normalMap = mapFilename(fields[1:])
+		case "map_Pm":
+			current.metallicMap = mapFilename(fields[1:])
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s: %v", line, fields[0], err)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return materials, nil
+}
+
+// mapFilename returns the filename of a texture map statement. Map options,
+// such as -bm 1.0, precede the filename and are ignored.
+func mapFilename(fields []string) string {
+	if len(fields) == 0 {
+		return ""
+	}
+
+	return fields[len(fields)-1]
+}
+
+func parseFloat(fields []string) (float32, error) {
+	if len(fields) < 1 {
+		return 0, fmt.Errorf("expected 1 value, got %d", len(fields))
+	}
+
+	f, err := strconv.ParseFloat(fields[0], 32)
+
+	return float32(f), err
+}
+
+func parseVec2(fields []string) (mgl32.Vec2, error) {
+	var v mgl32.Vec2
+
+	if len(fields) < 2 {
+		return v, fmt.Errorf("expected 2 values, got %d", len(fields))
+	}
+
+	for i := range v {
+		f, err := strconv.ParseFloat(fields[i], 32)
+		if err != nil {
+			return v, err
+		}
+		v[i] = float32(f)
+	}
+
+	return v, nil
+}
+
+func parseVec3(fields []string) (mgl32.Vec3, error) {
+	var v mgl32.Vec3
+
+	if len(fields) < 3 {
+		return v, fmt.Errorf("expected 3 values, got %d", len(fields))
+	}
+
+	for i := range v {
+		f, err := strconv.ParseFloat(fields[i], 32)
+		if err != nil {
+			return v, err
+		}
+		v[i] = float32(f)
+	}
+
+	return v, nil
+}
@@ -23,8 +23,10 @@ SOFTWARE.
 package engine
 
 import (
-	"sync"
 	"encoding/gob"
+	"path/filepath"
+	"strings"
+	"sync"
 
 	"github.com/go-gl/mathgl/mgl32"
 
@@ -72,26 +74,127 @@ type MeshHandler struct {
 }
 
 var _ AssetHandler = &MeshHandler{}
+var _ AssetVerifier = &MeshHandler{}
+var _ AssetReloader = &MeshHandler{}
+var _ AssetDecoder = &MeshHandler{}
 
-// Load will load data from the reader.
+// Load will load data from the reader. Wavefront OBJ files are imported by
+// their extension, other resources are read as encoded MeshMetadata.
 func (h *MeshHandler) Load(r *Resource) error {
-	metadata := &MeshMetadata{}
 	m := NewMesh()
 
-	dec := gob.NewDecoder(r.Reader())
-	err := dec.Decode(&metadata)
+	name, err := h.decode(r, m)
 	if err != nil {
 		return err
 	}
 
-	name := metadata.Name
+	return h.Add(name, m)
+}
 
-	if _, dup := h.Items[name]; dup {
-		return ErrAssetExists(name)
+// Decode decodes the geometry of the mesh, and returns a function creating
+// it. Materials referenced by OBJ files are loaded when the mesh is created.
+func (h *MeshHandler) Decode(r *Resource) (func() error, error) {
+	tmp := &Mesh{}
+
+	name, resolve, err := h.decodeGeometry(r, tmp)
+	if err != nil {
+		return nil, err
+	}
+
+	return func() error {
+		if h.has(name) {
+			return ErrAssetExists(name)
+		}
+
+		if err := resolve(); err != nil {
+			return err
+		}
+
+		m := NewMesh()
+		if tmp.Name() != "" {
+			m.SetName(tmp.Name())
+		}
+
+		m.vertices = tmp.vertices
+		m.normals = tmp.normals
+		m.uvs = tmp.uvs
+		m.triangles = tmp.triangles
+		m.subMeshes = tmp.subMeshes
+
+		return h.Add(name, m)
+	}, nil
+}
+
+// Reload decodes the resource into the existing mesh of the same name. If the
+// new geometry cannot be uploaded, the previous geometry is kept.
+func (h *MeshHandler) Reload(r *Resource) error {
+	tmp := &Mesh{}
+
+	name, err := h.decode(r, tmp)
+	if err != nil {
+		return err
+	}
+
+	m, err := h.Get(name)
+	if err != nil {
+		return err
+	}
+
+	v, n, t, triangles, subMeshes := m.vertices, m.normals, m.uvs, m.triangles, m.subMeshes
+
+	m.vertices = tmp.vertices
+	m.normals = tmp.normals
+	m.uvs = tmp.uvs
+	m.triangles = tmp.triangles
+	m.subMeshes = tmp.subMeshes
+
+	if err := m.Upload(); err != nil {
+		m.vertices, m.normals, m.uvs, m.triangles, m.subMeshes = v, n, t, triangles, subMeshes
+		return err
 	}
 
+	return nil
+}
+
+// decode decodes the resource into the mesh, and returns the name of the
+// asset.
+func (h *MeshHandler) decode(r *Resource, m *Mesh) (string, error) {
+	name, resolve, err := h.decodeGeometry(r, m)
+	if err != nil {
+		return "", err
+	}
+
+	return name, resolve()
+}
+
+// decodeGeometry decodes the resource into the geometry of the mesh, and
+// returns the name of the asset and a function resolving the materials of its
+// sub-meshes. Only the returned function may use the GL context.
+func (h *MeshHandler) decodeGeometry(r *Resource, m *Mesh) (string, func() error, error) {
+	switch strings.ToLower(filepath.Ext(r.Location())) {
+	case ".obj":
+		return decodeOBJ(r, m)
+	default:
+		name, err := decodeMetadata(r, m)
+
+		return name, func() error { return nil }, err
+	}
+}
+
+// decodeMetadata decodes a mesh from gob encoded MeshMetadata.
+func decodeMetadata(r *Resource, m *Mesh) (string, error) {
+	metadata := &MeshMetadata{}
+
+	dec := gob.NewDecoder(r.Reader())
+	err := dec.Decode(&metadata)
+	if err != nil {
+		return "", err
+	}
+
+	name := metadata.Name
+
 	if len(metadata.F) == 0 {
-		return ErrMeshMissingFaces
+		return "", ErrMeshMissingFaces
 	}
 
 	v := make([]mgl32.Vec3, len(metadata.F)*3)
@@ -114,7 +217,7 @@ func (h *MeshHandler) Load(r *Resource) error {
 				t[i*3+j] = metadata.T[metadata.F[i][j][FaceTexture]]
 				n[i*3+j] = metadata.N[metadata.F[i][j][FaceNormal]]
 			default:
-				return ErrMeshInvalidFaceType
+				return "", ErrMeshInvalidFaceType
 			}
 		}
 	}
@@ -122,8 +225,28 @@ func (h *MeshHandler) Load(r *Resource) error {
 	m.SetVertices(v)
 	m.SetNormals(n)
 	m.SetUvs(t)
+	m.Weld()
 
-	return h.Add(name, m)
+	return name, nil
+}
+
+// Verify checks that the resource decodes as a mesh. For OBJ files, the
+// referenced MTL files are verified too.
+func (h *MeshHandler) Verify(r *Resource) error {
+	if strings.ToLower(filepath.Ext(r.Location())) == ".obj" {
+		return verifyOBJ(r)
+	}
+
+	metadata := &MeshMetadata{}
+	if err := gob.NewDecoder(r.Reader()).Decode(&metadata); err != nil {
+		return err
+	}
+
+	if len(metadata.F) == 0 {
+		return ErrMeshMissingFaces
+	}
+
+	return nil
 }
 
 func (h *MeshHandler) Add(name string, mesh *Mesh) error {
@@ -138,7 +261,7 @@ func (h *MeshHandler) Add(name string, mesh *Mesh) error {
 		return err
 	}
 
-	h.Items[name] = mesh.ID()
+	h.addItem(name, mesh.ID())
 
 	return nil
 }
@@ -0,0 +1,358 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/sirupsen/logrus"
+
+	"github.com/haakenlabs/forge/internal/math"
+)
+
+// objModel is a model parsed from a Wavefront OBJ file. Face indices are zero
+// based, with -1 marking an absent texture coordinate or normal.
+type objModel struct {
+	v       []mgl32.Vec3
+	n       []mgl32.Vec3
+	t       []mgl32.Vec2
+	groups  []*objGroup
+	mtllibs []string
+}
+
+// objGroup is a run of faces sharing an object or group name and a material.
+type objGroup struct {
+	name     string
+	material string
+	faces    []Face
+}
+
+// decodeOBJ decodes a Wavefront OBJ model into the mesh. Each object or group
+// becomes a sub-mesh, and the returned function loads the materials from
+// referenced MTL files with the material handler. The mesh is named by the
+// filename.
+func decodeOBJ(r *Resource, m *Mesh) (string, func() error, error) {
+	name := r.Base()
+
+	model, err := parseOBJ(r.Reader())
+	if err != nil {
+		return "", nil, fmt.Errorf("%s: %v", name, err)
+	}
+
+	v, n, t, subMeshes, materials := model.build()
+	if len(v) == 0 {
+		return "", nil, ErrMeshMissingFaces
+	}
+
+	m.SetName(name)
+	m.SetVertices(v)
+	m.SetNormals(n)
+	m.SetUvs(t)
+	m.SetSubMeshes(subMeshes)
+	m.Weld()
+
+	resolve := func() error {
+		if err := loadMTLLibs(model.mtllibs, r.DirPrefix()); err != nil {
+			return err
+		}
+
+		for i := range m.subMeshes {
+			if materials[i] == "" {
+				continue
+			}
+
+			material, err := GetAsset().GetAsset(AssetNameMaterial, materials[i])
+			if err != nil {
+				logrus.Warnf("%s: material %s: %v", name, materials[i], err)
+				continue
+			}
+
+			m.subMeshes[i].Material = material.(*Material)
+		}
+
+		return nil
+	}
+
+	return name, resolve, nil
+}
+
+// loadMTLLibs loads the materials from MTL files relative to dir. Materials
+// which have already been loaded are kept.
+func loadMTLLibs(libs []string, dir string) error {
+	if len(libs) == 0 {
+		return nil
+	}
+
+	ah, err := GetAsset().GetHandler(AssetNameMaterial)
+	if err != nil {
+		return err
+	}
+	h := ah.(*MaterialHandler)
+
+	for _, lib := range libs {
+		r, err := NewResource(filepath.Join(dir, lib))
+		if err != nil {
+			return err
+		}
+		if err := GetAsset().ReadResource(r); err != nil {
+			return err
+		}
+
+		materials, err := parseMTL(r.Reader())
+		if err != nil {
+			return fmt.Errorf("%s: %v", lib, err)
+		}
+
+		for i := range materials {
+			if _, err := h.Get(materials[i].name); err == nil {
+				continue
+			}
+
+			if _, err := h.loadMTLMaterial(materials[i], r.DirPrefix()); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// verifyOBJ checks that an OBJ file parses, has faces and that its MTL files
+// can be verified.
+func verifyOBJ(r *Resource) error {
+	model, err := parseOBJ(r.Reader())
+	if err != nil {
+		return fmt.Errorf("%s: %v", r.Base(), err)
+	}
+
+	if v, _, _, _, _ := model.build(); len(v) == 0 {
+		return ErrMeshMissingFaces
+	}
+
+	materials := &MaterialHandler{}
+
+	for _, lib := range model.mtllibs {
+		lr, err := NewResource(filepath.Join(r.DirPrefix(), lib))
+		if err != nil {
+			return err
+		}
+		if err := GetAsset().ReadResource(lr); err != nil {
+			return err
+		}
+		if err := materials.Verify(lr); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// parseOBJ parses a Wavefront OBJ file. Polygons are triangulated as fans.
+func parseOBJ(r io.Reader) (*objModel, error) {
+	m := &objModel{}
+
+	var group *objGroup
+	name := "default"
+	material := ""
+
+	scanner := bufio.NewScanner(r)
+	line := 0
+
+	for scanner.Scan() {
+		line++
+
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		var err error
+
+		switch fields[0] {
+		case "v":
+			var v mgl32.Vec3
+			v, err = parseVec3(fields[1:])
+			m.v = append(m.v, v)
+		case "vn":
+			var n mgl32.Vec3
+			n, err = parseVec3(fields[1:])
+			m.n = append(m.n, n)
+		case "vt":
+			var t mgl32.Vec2
+			if len(fields) == 2 {
+				// A single texture coordinate implies v = 0.
+				fields = append(fields, "0")
+			}
+			t, err = parseVec2(fields[1:])
+			m.t = append(m.t, t)
+		case "o", "g":
+			if len(fields) > 1 {
+				name = strings.Join(fields[1:], " ")
+			} else {
+				name = "default"
+			}
+			group = nil
+		case "usemtl":
+			if len(fields) > 1 {
+				material = strings.Join(fields[1:], " ")
+			}
+			group = nil
+		case "mtllib":
+			m.mtllibs = append(m.mtllibs, fields[1:]...)
+		case "f":
+			if group == nil {
+				group = &objGroup{name: name, material: material}
+				m.groups = append(m.groups, group)
+			}
+
+			var faces []Face
+			faces, err = m.parseFace(fields[1:])
+			group.faces = append(group.faces, faces...)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s: %v", line, fields[0], err)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// parseFace parses a polygon and triangulates it.
+func (m *objModel) parseFace(fields []string) ([]Face, error) {
+	if len(fields) < 3 {
+		return nil, fmt.Errorf("expected at least 3 vertices, got %d", len(fields))
+	}
+
+	vertices := make([]math.IVec3, len(fields))
+
+	for i := range fields {
+		idx := strings.Split(fields[i], "/")
+		if len(idx) > 3 {
+			return nil, fmt.Errorf("invalid vertex: %s", fields[i])
+		}
+
+		vertices[i] = math.IVec3{-1, -1, -1}
+
+		counts := [3]int{len(m.v), len(m.t), len(m.n)}
+		for j := range idx {
+			if idx[j] == "" {
+				if j == FaceVertex {
+					return nil, fmt.Errorf("missing vertex index: %s", fields[i])
+				}
+				continue
+			}
+
+			v, err := resolveOBJIndex(idx[j], counts[j])
+			if err != nil {
+				return nil, err
+			}
+
+			vertices[i][j] = v
+		}
+	}
+
+	faces := make([]Face, 0, len(vertices)-2)
+	for i := 1; i < len(vertices)-1; i++ {
+		faces = append(faces, Face{vertices[0], vertices[i], vertices[i+1]})
+	}
+
+	return faces, nil
+}
+
+// resolveOBJIndex converts a one based, or negative relative, OBJ index into a
+// zero based index.
+func resolveOBJIndex(value string, count int) (int32, error) {
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, err
+	}
+
+	if i < 0 {
+		i += count
+	} else {
+		i--
+	}
+
+	if i < 0 || i >= count {
+		return 0, fmt.Errorf("index out of range: %s", value)
+	}
+
+	return int32(i), nil
+}
+
+// build flattens the model into vertex data. Missing normals are replaced with
+// face normals, and missing texture coordinates with zero. The material name of
+// each sub-mesh is returned alongside it.
+func (m *objModel) build() (v, n []mgl32.Vec3, t []mgl32.Vec2, subMeshes []SubMesh, materials []string) {
+	for _, g := range m.groups {
+		if len(g.faces) == 0 {
+			continue
+		}
+
+		subMeshes = append(subMeshes, SubMesh{
+			Name:   g.name,
+			Offset: int32(len(v)),
+			Count:  int32(len(g.faces) * 3),
+		})
+		materials = append(materials, g.material)
+
+		for _, f := range g.faces {
+			p0 := m.v[f[0][FaceVertex]]
+			p1 := m.v[f[1][FaceVertex]]
+			p2 := m.v[f[2][FaceVertex]]
+			faceNormal := p1.Sub(p0).Cross(p2.Sub(p0))
+			if faceNormal.Len() > 0 {
+				faceNormal = faceNormal.Normalize()
+			}
+
+			for j := range f {
+				v = append(v, m.v[f[j][FaceVertex]])
+
+				if f[j][FaceTexture] >= 0 {
+					t = append(t, m.t[f[j][FaceTexture]])
+				} else {
+					t = append(t, mgl32.Vec2{})
+				}
+
+				if f[j][FaceNormal] >= 0 {
+					n = append(n, m.n[f[j][FaceNormal]])
+				} else {
+					n = append(n, faceNormal)
+				}
+			}
+		}
+	}
+
+	return v, n, t, subMeshes, materials
+}
@@ -0,0 +1,225 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const testOBJ = `# quad and triangle
+mtllib test.mtl
+v 0 0 0
+v 1 0 0
+v 1 1 0
+v 0 1 0
+vt 0 0
+vt 1 0
+vt 1 1
+vt 0 1
+vn 0 0 1
+
+o quad
+usemtl red
+f 1/1/1 2/2/1 3/3/1 4/4/1
+
+g tri
+usemtl blue
+f -4//-1 -3//-1 -2//-1
+usemtl red
+f 1 2 3
+`
+
+const testMTL = `newmtl red
+Kd 1 0 0
+Ns 98
+map_Kd -bm 1.0 red.png
+
+newmtl blue
+Kd 0 0 1
+Pr 0.25
+Pm 1
+`
+
+func TestParseOBJ(t *testing.T) {
+	m, err := parseOBJ(strings.NewReader(testOBJ))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(m.mtllibs) != 1 || m.mtllibs[0] != "test.mtl" {
+		t.Errorf("mtllibs expected [test.mtl], got: %v", m.mtllibs)
+	}
+
+	v, n, uv, subMeshes, materials := m.build()
+
+	// A quad triangulates into two triangles.
+	if len(v) != 12 || len(n) != 12 || len(uv) != 12 {
+		t.Fatalf("expected 12 vertices, got: %d %d %d", len(v), len(n), len(uv))
+	}
+
+	expected := []struct {
+		name     string
+		material string
+		offset   int32
+		count    int32
+	}{
+		{"quad", "red", 0, 6},
+		{"tri", "blue", 6, 3},
+		{"tri", "red", 9, 3},
+	}
+
+	if len(subMeshes) != len(expected) {
+		t.Fatalf("expected %d sub-meshes, got: %d", len(expected), len(subMeshes))
+	}
+
+	for i := range expected {
+		s := subMeshes[i]
+		if s.Name != expected[i].name || s.Offset != expected[i].offset || s.Count != expected[i].count {
+			t.Errorf("sub-mesh %d expected %+v, got: %+v", i, expected[i], s)
+		}
+		if materials[i] != expected[i].material {
+			t.Errorf("sub-mesh %d expected material %s, got: %s", i, expected[i].material, materials[i])
+		}
+	}
+
+	// Fan triangulation of the quad.
+	if v[3] != (mgl32.Vec3{0, 0, 0}) || v[4] != (mgl32.Vec3{1, 1, 0}) || v[5] != (mgl32.Vec3{0, 1, 0}) {
+		t.Errorf("unexpected second triangle: %v", v[3:6])
+	}
+	if uv[4] != (mgl32.Vec2{1, 1}) {
+		t.Errorf("uv expected {1 1}, got: %v", uv[4])
+	}
+
+	// Negative indices refer to the most recent elements.
+	if v[6] != (mgl32.Vec3{0, 0, 0}) || v[8] != (mgl32.Vec3{1, 1, 0}) {
+		t.Errorf("unexpected relative triangle: %v", v[6:9])
+	}
+
+	// Missing normals are generated from the face.
+	if !n[10].ApproxEqual(mgl32.Vec3{0, 0, 1}) {
+		t.Errorf("generated normal expected {0 0 1}, got: %v", n[10])
+	}
+}
+
+func TestParseOBJ_Errors(t *testing.T) {
+	tests := []string{
+		"v 0 0 0\nf 1 2 3\n",
+		"v 0 0 0\nv 0 0 0\nf 1 2\n",
+		"v 0 0\n",
+		"v 0 0 0\nv 0 0 0\nv 0 0 0\nf 0 1 2\n",
+	}
+
+	for i := range tests {
+		if _, err := parseOBJ(strings.NewReader(tests[i])); err == nil {
+			t.Errorf("test %d expected error", i)
+		}
+	}
+}
+
+func TestParseMTL(t *testing.T) {
+	materials, err := parseMTL(strings.NewReader(testMTL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(materials) != 2 {
+		t.Fatalf("expected 2 materials, got: %d", len(materials))
+	}
+
+	red := materials[0]
+	if red.name != "red" || red.diffuse != (mgl32.Vec3{1, 0, 0}) || red.shininess != 98 || red.hasRough {
+		t.Errorf("unexpected material: %+v", red)
+	}
+	if red.diffuseMap != "red.png" {
+		t.Errorf("diffuse map expected red.png, got: %s", red.diffuseMap)
+	}
+
+	blue := materials[1]
+	if blue.name != "blue" || blue.roughness != 0.25 || !blue.hasRough || blue.metallic != 1 {
+		t.Errorf("unexpected material: %+v", blue)
+	}
+}
+
+func TestDecodeOBJ_Draws(t *testing.T) {
+	a := newHeadlessApp(t)
+	defer a.Teardown()
+
+	ah, err := GetAsset().GetHandler(AssetNameMaterial)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := ah.(*MaterialHandler)
+	red, blue := NewMaterial(), NewMaterial()
+	if err := h.Add("red", red); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Add("blue", blue); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewResource("two.obj")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.buffer.WriteString(`v 0 0 0
+v 1 0 0
+v 1 1 0
+v 0 1 0
+usemtl red
+f 1 2 3
+usemtl blue
+f 1 3 4
+`)
+
+	m := &Mesh{}
+	_, resolve, err := decodeOBJ(r, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := resolve(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Each material is drawn once, with the range of its group.
+	fallback := NewMaterial()
+	draws := m.Draws(fallback)
+	if len(draws) != 2 {
+		t.Fatalf("expected 2 draws, got: %d", len(draws))
+	}
+	if draws[0].Material != red || draws[0].SubMesh != 0 {
+		t.Errorf("expected first draw of sub-mesh 0 with red, got: %+v", draws[0])
+	}
+	if draws[1].Material != blue || draws[1].SubMesh != 1 {
+		t.Errorf("expected second draw of sub-mesh 1 with blue, got: %+v", draws[1])
+	}
+
+	// Meshes without sub-meshes are drawn whole with the fallback.
+	m.SetSubMeshes(nil)
+	if draws := m.Draws(fallback); len(draws) != 1 || draws[0].SubMesh != -1 || draws[0].Material != fallback {
+		t.Errorf("expected whole mesh with fallback, got: %+v", draws)
+	}
+}
@@ -0,0 +1,163 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"fmt"
+	"sync"
+)
+
+const (
+	AssetNamePrefab = "prefab"
+)
+
+var _ AssetHandler = &PrefabHandler{}
+var _ AssetDecoder = &PrefabHandler{}
+var _ AssetReloader = &PrefabHandler{}
+var _ AssetVerifier = &PrefabHandler{}
+
+// PrefabHandler manages prefabs. Prefabs are loaded from game object
+// documents in JSON or YAML, chosen by the file extension, and are named by
+// their filename.
+type PrefabHandler struct {
+	BaseAssetHandler
+}
+
+// Load will load data from the reader.
+func (h *PrefabHandler) Load(r *Resource) error {
+	upload, err := h.Decode(r)
+	if err != nil {
+		return err
+	}
+
+	return upload()
+}
+
+// Decode parses the prefab document, and returns a function creating the
+// prefab.
+func (h *PrefabHandler) Decode(r *Resource) (func() error, error) {
+	name := r.Base()
+
+	doc, err := decodePrefabDocument(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return func() error {
+		if h.has(name) {
+			return ErrAssetExists(name)
+		}
+
+		p := NewPrefab(doc)
+		p.SetName(name)
+
+		return h.Add(name, p)
+	}, nil
+}
+
+// Reload replaces the document of the prefab, updating its instances.
+func (h *PrefabHandler) Reload(r *Resource) error {
+	p, err := h.Get(r.Base())
+	if err != nil {
+		return err
+	}
+
+	doc, err := decodePrefabDocument(r)
+	if err != nil {
+		return err
+	}
+
+	return p.SetDocument(doc)
+}
+
+// Verify checks that the resource parses as a prefab document.
+func (h *PrefabHandler) Verify(r *Resource) error {
+	_, err := decodePrefabDocument(r)
+
+	return err
+}
+
+func (h *PrefabHandler) Add(name string, prefab *Prefab) error {
+	h.Mu.Lock()
+	defer h.Mu.Unlock()
+
+	if _, dup := h.Items[name]; dup {
+		return ErrAssetExists(name)
+	}
+
+	h.addItem(name, prefab.ID())
+
+	return nil
+}
+
+// Get gets an asset by name.
+func (h *PrefabHandler) Get(name string) (*Prefab, error) {
+	a, err := h.GetAsset(name)
+	if err != nil {
+		return nil, err
+	}
+
+	a2, ok := a.(*Prefab)
+	if !ok {
+		return nil, ErrAssetType(name)
+	}
+
+	return a2, nil
+}
+
+// MustGet is like GetAsset, but panics if an error occurs.
+func (h *PrefabHandler) MustGet(name string) *Prefab {
+	a, err := h.Get(name)
+	if err != nil {
+		panic(err)
+	}
+
+	return a
+}
+
+func (h *PrefabHandler) Name() string {
+	return AssetNamePrefab
+}
+
+func NewPrefabHandler() *PrefabHandler {
+	h := &PrefabHandler{}
+	h.Items = make(map[string]uint32)
+	h.Mu = &sync.RWMutex{}
+
+	return h
+}
+
+// decodePrefabDocument reads the game object document of a prefab.
+func decodePrefabDocument(r *Resource) (*GameObjectDocument, error) {
+	format, err := SceneFormatFromFilename(r.Base())
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &GameObjectDocument{}
+	if err := decodeDocument(r.Reader(), format, doc); err != nil {
+		return nil, fmt.Errorf("%s: %v", r.Base(), err)
+	}
+
+	return doc, nil
+}
@@ -0,0 +1,314 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package engine
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// AssetRef is a loaded asset and its number of references.
+type AssetRef struct {
+	Kind  string
+	Name  string
+	Count int
+}
+
+// assetRefs counts references to assets by instance ID, and records the file
+// source each asset was loaded from.
+type assetRefs struct {
+	counts  map[uint32]int
+	sources map[uint32]string
+	loading []string
+	mu      *sync.Mutex
+}
+
+func newAssetRefs() *assetRefs {
+	return &assetRefs{
+		counts:  make(map[uint32]int),
+		sources: make(map[uint32]string),
+		mu:      &sync.Mutex{},
+	}
+}
+
+// beginLoad records the source of assets added until endLoad is called. Loads
+// may nest, the innermost source is recorded.
+func (r *assetRefs) beginLoad(source string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.loading = append(r.loading, source)
+}
+
+func (r *assetRefs) endLoad() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.loading) > 0 {
+		r.loading = r.loading[:len(r.loading)-1]
+	}
+}
+
+func (r *assetRefs) added(id uint32) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if n := len(r.loading); n > 0 {
+		r.sources[id] = r.loading[n-1]
+	}
+}
+
+func (r *assetRefs) forget(ids ...uint32) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, id := range ids {
+		delete(r.counts, id)
+		delete(r.sources, id)
+	}
+}
+
+func (r *assetRefs) count(id uint32) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.counts[id]
+}
+
+// loadedFrom returns the source the asset was loaded from, if it was loaded
+// from a file.
+func (r *assetRefs) loadedFrom(id uint32) (string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	source, ok := r.sources[id]
+
+	return source, ok
+}
+
+// Ref adds a reference to the object. Components holding assets reference
+// them, so that they are not unloaded while in use.
+func (a *Asset) Ref(object Object) {
+	if object == nil || object.ID() == 0 {
+		return
+	}
+
+	a.refs.mu.Lock()
+	defer a.refs.mu.Unlock()
+
+	a.refs.counts[object.ID()]++
+}
+
+// Unref removes a reference to the object added by Ref.
+func (a *Asset) Unref(object Object) {
+	if object == nil || object.ID() == 0 {
+		return
+	}
+
+	a.refs.mu.Lock()
+	defer a.refs.mu.Unlock()
+
+	id := object.ID()
+	if a.refs.counts[id] > 1 {
+		a.refs.counts[id]--
+	} else {
+		delete(a.refs.counts, id)
+	}
+}
+
+// RefCount returns the number of references to the object.
+func (a *Asset) RefCount(object Object) int {
+	if object == nil {
+		return 0
+	}
+
+	return a.refs.count(object.ID())
+}
+
+// Unload releases an asset which is no longer referenced, freeing its GPU
+// objects.
+func (a *Asset) Unload(kind, name string) error {
+	h, err := a.GetHandler(kind)
+	if err != nil {
+		return err
+	}
+
+	return a.unload(h, name)
+}
+
+func (a *Asset) unload(h AssetHandler, name string) error {
+	o, err := h.GetAsset(name)
+	if err != nil {
+		return err
+	}
+
+	id := o.ID()
+	if a.refs.count(id) > 0 {
+		return ErrAssetReferenced(name)
+	}
+
+	if _, err := h.Remove(name); err != nil {
+		return err
+	}
+
+	a.refs.forget(id)
+	a.unwatch(h.Name(), name)
+	GetInstance().Release(id)
+
+	logrus.Debug("Unloaded asset: ", name)
+
+	return nil
+}
+
+// UnloadUnused unloads all assets which were loaded from files, other than
+// the builtin assets, and are no longer referenced. It returns the number of
+// assets unloaded. Call it after switching scenes to free the assets only
+// the previous scene used.
+func (a *Asset) UnloadUnused() int {
+	return a.unloadWhere(func(source string) bool {
+		return source != vfsBuiltinName
+	}, false)
+}
+
+// unloadSource unloads the assets loaded from the named file source. Assets
+// which are still referenced are kept, and a warning is logged.
+func (a *Asset) unloadSource(name string) {
+	a.unloadWhere(func(source string) bool {
+		return source == name
+	}, true)
+}
+
+// unloadWhere unloads the unreferenced assets whose source matches. Matching
+// assets which are referenced are logged if warn is set.
+func (a *Asset) unloadWhere(match func(source string) bool, warn bool) int {
+	count := 0
+
+	for _, kind := range a.kinds() {
+		h, err := a.GetHandler(kind)
+		if err != nil {
+			continue
+		}
+
+		for _, name := range h.Names() {
+			o, err := h.GetAsset(name)
+			if err != nil {
+				continue
+			}
+
+			source, ok := a.refs.loadedFrom(o.ID())
+			if !ok || !match(source) {
+				continue
+			}
+
+			if n := a.refs.count(o.ID()); n > 0 {
+				if warn {
+					logrus.Warnf("asset: keeping %s %s from %s, referenced %d times", kind, name, source, n)
+				}
+				continue
+			}
+
+			if err := a.unload(h, name); err != nil {
+				logrus.Error(err)
+				continue
+			}
+
+			count++
+		}
+	}
+
+	return count
+}
+
+// Referenced returns the loaded assets which are referenced, sorted by kind
+// and name.
+func (a *Asset) Referenced() []AssetRef {
+	var refs []AssetRef
+
+	for _, kind := range a.kinds() {
+		h, err := a.GetHandler(kind)
+		if err != nil {
+			continue
+		}
+
+		for _, name := range h.Names() {
+			o, err := h.GetAsset(name)
+			if err != nil {
+				continue
+			}
+
+			if n := a.refs.count(o.ID()); n > 0 {
+				refs = append(refs, AssetRef{Kind: kind, Name: name, Count: n})
+			}
+		}
+	}
+
+	return refs
+}
+
+// reportLeaks logs the assets which are still referenced.
+func (a *Asset) reportLeaks() {
+	refs := a.Referenced()
+	if len(refs) == 0 {
+		return
+	}
+
+	logrus.Warnf("asset: %d assets still referenced at teardown", len(refs))
+	for _, r := range refs {
+		logrus.Warnf("asset: %s %s referenced %d times", r.Kind, r.Name, r.Count)
+	}
+}
+
+// kinds returns the names of the registered handlers, sorted.
+func (a *Asset) kinds() []string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	kinds := make([]string, 0, len(a.handlers))
+	for kind := range a.handlers {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+
+	return kinds
+}
+
+// sourceName returns the name of the file source the resource is read from.
+// Resources read from the local filesystem have an empty source name.
+func (a *Asset) sourceName(r *Resource) string {
+	switch r.resType {
+	case ResourcePackage:
+		return r.container
+	case ResourceBindata:
+		return vfsBuiltinName
+	case ResourceVFS:
+		source, err := a.vfs.Resolve(r.location)
+		if err != nil {
+			return ""
+		}
+
+		return source.Name()
+	}
+
+	return ""
+}
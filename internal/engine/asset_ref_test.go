@@ -0,0 +1,232 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package engine
+
+import (
+	"archive/zip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/haakenlabs/forge/internal/math"
+)
+
+func newRefTextHandler(t *testing.T) *textHandler {
+	h := &textHandler{}
+	h.Items = make(map[string]uint32)
+	h.Mu = &sync.RWMutex{}
+
+	if err := GetAsset().RegisterHandler(h); err != nil {
+		t.Fatal(err)
+	}
+
+	return h
+}
+
+func TestAsset_Unload(t *testing.T) {
+	a := newHeadlessApp(t)
+	defer a.Teardown()
+
+	dir, err := ioutil.TempDir("", "unload")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	h := newRefTextHandler(t)
+	asset := GetAsset()
+
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if err := asset.Load("text", filepath.Join(dir, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	o := h.MustGetAsset("a.txt")
+	id := o.ID()
+
+	asset.Ref(o)
+	asset.Ref(o)
+
+	if n := asset.RefCount(o); n != 2 {
+		t.Error("asset.RefCount() expected 2, got:", n)
+	}
+
+	refs := asset.Referenced()
+	if len(refs) != 1 || refs[0] != (AssetRef{Kind: "text", Name: "a.txt", Count: 2}) {
+		t.Error("asset.Referenced() expected [{text a.txt 2}], got:", refs)
+	}
+
+	if err := asset.Unload("text", "a.txt"); err != ErrAssetReferenced("a.txt") {
+		t.Error("asset.Unload() expected ErrAssetReferenced, got:", err)
+	}
+
+	// Only unreferenced assets loaded from files are unloaded.
+	if n := asset.UnloadUnused(); n != 1 {
+		t.Error("asset.UnloadUnused() expected 1, got:", n)
+	}
+	if _, err := h.GetAsset("b.txt"); err == nil {
+		t.Error("h.GetAsset(b.txt) expected error")
+	}
+
+	asset.Unref(o)
+	asset.Unref(o)
+
+	if err := asset.Unload("text", "a.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := GetInstance().Get(id); err == nil {
+		t.Error("GetInstance().Get() expected error after unload")
+	}
+	if h.Count() != 0 {
+		t.Error("h.Count() expected 0, got:", h.Count())
+	}
+}
+
+func TestAsset_UnmountUnloads(t *testing.T) {
+	a := newHeadlessApp(t)
+	defer a.Teardown()
+
+	dir, err := ioutil.TempDir("", "unmount")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "level.pkg")
+	f, err := os.Create(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	zw := zip.NewWriter(f)
+	for _, name := range []string{"a.txt", "b.txt"} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	h := newRefTextHandler(t)
+	asset := GetAsset()
+
+	if err := asset.Mount(NewPackageFile(filename), MountPriorityDefault); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if err := asset.Load("text", name); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	b := h.MustGetAsset("b.txt")
+	asset.Ref(b)
+
+	if err := asset.Unmount("level"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Referenced assets are kept loaded.
+	if _, err := h.GetAsset("a.txt"); err == nil {
+		t.Error("h.GetAsset(a.txt) expected error after unmount")
+	}
+	if _, err := h.GetAsset("b.txt"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestModel_Dealloc(t *testing.T) {
+	a := newHeadlessApp(t)
+	defer a.Teardown()
+
+	asset := GetAsset()
+
+	texture := NewTexture2D(math.IVec2{1, 1}, TextureFormatRGBA8)
+	material := NewMaterial()
+	material.SetTexture(MaterialTextureAlbedo, texture)
+	mesh := NewMesh()
+	skeleton, err := NewSkeleton([]Joint{{Name: "root", Parent: -1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Both nodes share the mesh and material, which are released once.
+	node := ModelNode{Meshes: []*Mesh{mesh}, Materials: []*Material{material}, Skeleton: skeleton}
+	model := NewModel()
+	model.SetNodes([]ModelNode{node, node}, []int{0, 1})
+
+	h, err := asset.GetHandler(AssetNameModel)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := h.(*ModelHandler).Add("model.gltf", model); err != nil {
+		t.Fatal(err)
+	}
+
+	ids := []uint32{mesh.ID(), material.ID(), skeleton.ID()}
+
+	// The parts are tracked by their handlers under derived names.
+	if o, err := asset.Get(AssetNameMesh, "model.gltf#mesh0"); err != nil || o != mesh {
+		t.Error("asset.Get(model.gltf#mesh0) expected the mesh, got:", o, err)
+	}
+	if o, err := asset.Get(AssetNameMaterial, "model.gltf#material0"); err != nil || o != material {
+		t.Error("asset.Get(model.gltf#material0) expected the material, got:", o, err)
+	}
+
+	asset.Ref(model)
+	if err := asset.Unload(AssetNameModel, "model.gltf"); err != ErrAssetReferenced("model.gltf") {
+		t.Error("asset.Unload() expected ErrAssetReferenced, got:", err)
+	}
+	asset.Unref(model)
+
+	if err := asset.Unload(AssetNameModel, "model.gltf"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, id := range ids {
+		if _, err := GetInstance().Get(id); err == nil {
+			t.Errorf("GetInstance().Get(%08X) expected error after unload", id)
+		}
+	}
+	if _, err := asset.Get(AssetNameMesh, "model.gltf#mesh0"); err == nil {
+		t.Error("asset.Get(model.gltf#mesh0) expected error after unload")
+	}
+	if n := asset.RefCount(texture); n != 0 {
+		t.Error("asset.RefCount(texture) expected 0, got:", n)
+	}
+}
@@ -0,0 +1,339 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// hotReloadInterval is the minimum time between checks for changed files.
+const hotReloadInterval = 500 * time.Millisecond
+
+// AssetReloader is implemented by asset handlers which can reload an asset in
+// place. The asset keeps its instance ID, so existing references to it see the
+// new data.
+type AssetReloader interface {
+	// Reload loads the resource into the existing asset it was loaded as.
+	Reload(*Resource) error
+}
+
+// AssetReloadEvent is queued as a global event when an asset has been
+// reloaded.
+type AssetReloadEvent struct {
+	Kind     string
+	Filename string
+}
+
+type watchedFile struct {
+	path    string
+	modTime time.Time
+}
+
+// watchedAsset is a loaded asset and the local files it was read from.
+type watchedAsset struct {
+	kind     string
+	filename string
+	files    []watchedFile
+}
+
+// assetTracker records the local files read while loading an asset.
+type assetTracker struct {
+	files []string
+}
+
+type assetReload struct {
+	enabled  bool
+	assets   []*watchedAsset
+	trackers []*assetTracker
+	lastPoll time.Time
+	mu       *sync.Mutex
+}
+
+func newAssetReload() *assetReload {
+	return &assetReload{
+		mu: &sync.Mutex{},
+	}
+}
+
+// SetHotReload enables or disables reloading assets when their files change.
+// It can also be enabled with the configuration key asset.hotreload.
+func (a *Asset) SetHotReload(enabled bool) {
+	a.reload.mu.Lock()
+	defer a.reload.mu.Unlock()
+
+	a.reload.enabled = enabled
+}
+
+// HotReload reports if hot reloading is enabled.
+func (a *Asset) HotReload() bool {
+	a.reload.mu.Lock()
+	defer a.reload.mu.Unlock()
+
+	return a.reload.enabled
+}
+
+// Poll reloads assets whose files have changed, if hot reloading is enabled.
+// It is called by the App once per frame, and checks files at most every
+// hotReloadInterval. Reloading must happen on the thread owning the GL context.
+func (a *Asset) Poll() {
+	a.reload.mu.Lock()
+	if !a.reload.enabled || time.Since(a.reload.lastPoll) < hotReloadInterval {
+		a.reload.mu.Unlock()
+		return
+	}
+	a.reload.lastPoll = time.Now()
+	a.reload.mu.Unlock()
+
+	a.ReloadChanged()
+}
+
+// ReloadChanged reloads all assets whose files have changed since they were
+// loaded. If an asset fails to reload, the error is logged and the previous
+// version is kept. The number of assets reloaded is returned.
+func (a *Asset) ReloadChanged() int {
+	a.reload.mu.Lock()
+	var changed []*watchedAsset
+	for _, w := range a.reload.assets {
+		if w.changed() {
+			changed = append(changed, w)
+		}
+	}
+	a.reload.mu.Unlock()
+
+	count := 0
+
+	for _, w := range changed {
+		if err := a.reloadAsset(w); err != nil {
+			logrus.Errorf("asset: reload of %s failed, keeping previous version: %v", w.filename, err)
+			continue
+		}
+
+		logrus.Info("Reloaded asset: ", w.filename)
+		count++
+
+		if e := events(); e != nil {
+			e.Queue(ScopeGlobal(), AssetReloadEvent{Kind: w.kind, Filename: w.filename})
+		}
+	}
+
+	return count
+}
+
+func (a *Asset) reloadAsset(w *watchedAsset) error {
+	h, err := a.GetHandler(w.kind)
+	if err != nil {
+		return err
+	}
+
+	reloader, ok := h.(AssetReloader)
+	if !ok {
+		a.reload.mu.Lock()
+		w.files = statFiles(w.paths())
+		a.reload.mu.Unlock()
+
+		return ErrAssetNotReloadable(w.kind)
+	}
+
+	r, err := NewResource(w.filename)
+	if err != nil {
+		return err
+	}
+
+	t := a.beginTracking()
+
+	err = a.ReadResource(r)
+	if err == nil {
+		err = reloader.Reload(r)
+	}
+
+	files := a.endTracking(t)
+
+	a.reload.mu.Lock()
+	defer a.reload.mu.Unlock()
+
+	if err != nil {
+		// Record the current state of the files, so a failed reload is only
+		// retried once they change again.
+		w.files = statFiles(w.paths())
+		return err
+	}
+
+	w.files = statFiles(files)
+
+	return nil
+}
+
+// beginTracking starts recording the local files read by ReadResource. No
+// lock is held until endTracking, so handlers can load their dependencies
+// while tracking. Files read by nested or concurrent loads are recorded by
+// every active tracker, which at worst reloads an asset more often than
+// needed.
+func (a *Asset) beginTracking() *assetTracker {
+	a.reload.mu.Lock()
+	defer a.reload.mu.Unlock()
+
+	t := &assetTracker{}
+	a.reload.trackers = append(a.reload.trackers, t)
+
+	return t
+}
+
+// endTracking stops recording with the tracker and returns the files read
+// since tracking began.
+func (a *Asset) endTracking(t *assetTracker) []string {
+	a.reload.mu.Lock()
+	defer a.reload.mu.Unlock()
+
+	for i := range a.reload.trackers {
+		if a.reload.trackers[i] == t {
+			a.reload.trackers = append(a.reload.trackers[:i], a.reload.trackers[i+1:]...)
+			break
+		}
+	}
+
+	return t.files
+}
+
+// track records the local file behind the resource with all active trackers.
+func (a *Asset) track(r *Resource) {
+	a.reload.mu.Lock()
+	defer a.reload.mu.Unlock()
+
+	if len(a.reload.trackers) == 0 {
+		return
+	}
+
+	if p, ok := a.localPath(r); ok {
+		for _, t := range a.reload.trackers {
+			t.files = append(t.files, p)
+		}
+	}
+}
+
+// watch records the files of a loaded asset, replacing those recorded when
+// it was loaded before.
+func (a *Asset) watch(kind, filename string, files []string) {
+	if len(files) == 0 {
+		return
+	}
+
+	a.reload.mu.Lock()
+	defer a.reload.mu.Unlock()
+
+	for _, w := range a.reload.assets {
+		if w.kind == kind && w.filename == filename {
+			w.files = statFiles(files)
+			return
+		}
+	}
+
+	a.reload.assets = append(a.reload.assets, &watchedAsset{
+		kind:     kind,
+		filename: filename,
+		files:    statFiles(files),
+	})
+}
+
+// unwatch stops watching the files of an unloaded asset. Assets are named by
+// the base of their filename.
+func (a *Asset) unwatch(kind, name string) {
+	a.reload.mu.Lock()
+	defer a.reload.mu.Unlock()
+
+	assets := a.reload.assets[:0]
+	for _, w := range a.reload.assets {
+		if w.kind != kind || filepath.Base(w.filename) != name {
+			assets = append(assets, w)
+		}
+	}
+
+	for i := len(assets); i < len(a.reload.assets); i++ {
+		a.reload.assets[i] = nil
+	}
+
+	a.reload.assets = assets
+}
+
+// localPath returns the path on the local filesystem of the resource. Only
+// resources read from files or mounted directories have a local path.
+func (a *Asset) localPath(r *Resource) (string, bool) {
+	switch r.resType {
+	case ResourceFile:
+		return r.location, true
+	case ResourceVFS:
+		source, err := a.vfs.Resolve(r.location)
+		if err != nil {
+			return "", false
+		}
+
+		if d, ok := source.(*DirSource); ok {
+			return d.path(r.location)
+		}
+	}
+
+	return "", false
+}
+
+func (w *watchedAsset) changed() bool {
+	for _, f := range w.files {
+		info, err := os.Stat(f.path)
+		if err != nil {
+			continue
+		}
+
+		if !info.ModTime().Equal(f.modTime) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (w *watchedAsset) paths() []string {
+	paths := make([]string, len(w.files))
+	for i := range w.files {
+		paths[i] = w.files[i].path
+	}
+
+	return paths
+}
+
+func statFiles(paths []string) []watchedFile {
+	files := make([]watchedFile, 0, len(paths))
+
+	for _, p := range paths {
+		f := watchedFile{path: p}
+		if info, err := os.Stat(p); err == nil {
+			f.modTime = info.ModTime()
+		}
+
+		files = append(files, f)
+	}
+
+	return files
+}
@@ -0,0 +1,270 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// textAsset is an asset holding the contents of a text file.
+type textAsset struct {
+	BaseObject
+
+	text string
+}
+
+// textHandler loads text files. Files containing "error" fail to load, and
+// files starting with "load " load the named file first.
+type textHandler struct {
+	BaseAssetHandler
+}
+
+func (h *textHandler) decode(r *Resource) (string, error) {
+	text := string(r.Bytes())
+	if strings.Contains(text, "error") {
+		return "", errors.New("invalid text")
+	}
+
+	return text, nil
+}
+
+func (h *textHandler) Load(r *Resource) error {
+	text, err := h.decode(r)
+	if err != nil {
+		return err
+	}
+
+	if strings.HasPrefix(text, "load ") {
+		if err := GetAsset().Load(h.Name(), strings.TrimPrefix(text, "load ")); err != nil {
+			return err
+		}
+	}
+
+	t := &textAsset{text: text}
+	GetInstance().MustAssign(t)
+	h.addItem(r.Base(), t.ID())
+
+	return nil
+}
+
+func (h *textHandler) Reload(r *Resource) error {
+	a, err := h.GetAsset(r.Base())
+	if err != nil {
+		return err
+	}
+
+	text, err := h.decode(r)
+	if err != nil {
+		return err
+	}
+
+	a.(*textAsset).text = text
+
+	return nil
+}
+
+func (h *textHandler) Name() string {
+	return "text"
+}
+
+func writeReloadFile(t *testing.T, filename, text string, modTime time.Time) {
+	if err := ioutil.WriteFile(filename, []byte(text), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(filename, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAsset_ReloadChanged(t *testing.T) {
+	a := newHeadlessApp(t)
+	defer a.Teardown()
+
+	dir, err := ioutil.TempDir("", "reload")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	h := &textHandler{}
+	h.Items = make(map[string]uint32)
+	h.Mu = &sync.RWMutex{}
+
+	asset := GetAsset()
+	if err := asset.RegisterHandler(h); err != nil {
+		t.Fatal(err)
+	}
+
+	filename := filepath.Join(dir, "a.txt")
+	now := time.Now()
+	writeReloadFile(t, filename, "one", now)
+
+	if err := asset.Load("text", filename); err != nil {
+		t.Fatal(err)
+	}
+
+	obj := asset.MustGet("text", "a.txt").(*textAsset)
+	id := obj.ID()
+
+	if n := asset.ReloadChanged(); n != 0 {
+		t.Errorf("expected no reloads, got: %d", n)
+	}
+
+	writeReloadFile(t, filename, "two", now.Add(time.Second))
+	if n := asset.ReloadChanged(); n != 1 {
+		t.Errorf("expected 1 reload, got: %d", n)
+	}
+	if obj.text != "two" || obj.ID() != id {
+		t.Errorf("expected text two with ID %d, got: %s %d", id, obj.text, obj.ID())
+	}
+
+	// A failed reload keeps the previous version, and is not retried until
+	// the file changes again.
+	writeReloadFile(t, filename, "error", now.Add(2*time.Second))
+	if n := asset.ReloadChanged(); n != 0 {
+		t.Errorf("expected no reloads, got: %d", n)
+	}
+	if obj.text != "two" {
+		t.Errorf("expected previous text two, got: %s", obj.text)
+	}
+
+	writeReloadFile(t, filename, "three", now.Add(3*time.Second))
+	if n := asset.ReloadChanged(); n != 1 {
+		t.Errorf("expected 1 reload, got: %d", n)
+	}
+	if obj.text != "three" {
+		t.Errorf("expected text three, got: %s", obj.text)
+	}
+}
+
+func TestAsset_LoadDependency(t *testing.T) {
+	a := newHeadlessApp(t)
+	defer a.Teardown()
+
+	dir, err := ioutil.TempDir("", "reload")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	h := &textHandler{}
+	h.Items = make(map[string]uint32)
+	h.Mu = &sync.RWMutex{}
+
+	asset := GetAsset()
+	if err := asset.RegisterHandler(h); err != nil {
+		t.Fatal(err)
+	}
+
+	dep := filepath.Join(dir, "dep.txt")
+	filename := filepath.Join(dir, "a.txt")
+	now := time.Now()
+	writeReloadFile(t, dep, "dep", now)
+	writeReloadFile(t, filename, "load "+dep, now)
+
+	// Handlers loading their dependencies must not block on the tracking of
+	// the outer load.
+	done := make(chan error, 1)
+	go func() {
+		done <- asset.Load("text", filename)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected dependency load to finish, deadlocked")
+	}
+
+	obj := asset.MustGet("text", "dep.txt").(*textAsset)
+	if obj.text != "dep" {
+		t.Errorf("expected dependency text dep, got: %s", obj.text)
+	}
+
+	// Both the dependency and the asset loading it are reloaded when the
+	// dependency changes.
+	writeReloadFile(t, dep, "dep2", now.Add(time.Second))
+	if n := asset.ReloadChanged(); n != 2 {
+		t.Errorf("expected 2 reloads, got: %d", n)
+	}
+	if obj.text != "dep2" {
+		t.Errorf("expected dependency text dep2, got: %s", obj.text)
+	}
+}
+
+func TestAsset_UnloadUnwatches(t *testing.T) {
+	a := newHeadlessApp(t)
+	defer a.Teardown()
+
+	dir, err := ioutil.TempDir("", "reload")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	h := &textHandler{}
+	h.Items = make(map[string]uint32)
+	h.Mu = &sync.RWMutex{}
+
+	asset := GetAsset()
+	if err := asset.RegisterHandler(h); err != nil {
+		t.Fatal(err)
+	}
+
+	filename := filepath.Join(dir, "a.txt")
+	now := time.Now()
+	writeReloadFile(t, filename, "one", now)
+
+	if err := asset.Load("text", filename); err != nil {
+		t.Fatal(err)
+	}
+	if err := asset.Unload("text", "a.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(asset.reload.assets); n != 0 {
+		t.Errorf("expected no watched assets after unload, got: %d", n)
+	}
+
+	// Loading again watches the asset once, even if it is reloaded.
+	if err := asset.Load("text", filename); err != nil {
+		t.Fatal(err)
+	}
+	asset.watch("text", filename, []string{filename})
+	if n := len(asset.reload.assets); n != 1 {
+		t.Errorf("expected 1 watched asset, got: %d", n)
+	}
+
+	writeReloadFile(t, filename, "two", now.Add(time.Second))
+	if n := asset.ReloadChanged(); n != 1 {
+		t.Errorf("expected 1 reload, got: %d", n)
+	}
+}
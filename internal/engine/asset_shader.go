@@ -34,52 +34,153 @@ const (
 )
 
 var _ AssetHandler = &ShaderHandler{}
+var _ AssetVerifier = &ShaderHandler{}
+var _ AssetReloader = &ShaderHandler{}
+var _ AssetDecoder = &ShaderHandler{}
 
 type ShaderHandler struct {
 	BaseAssetHandler
 }
 
+// ShaderMetadata describes a shader. Variants maps the name of each variant
+// to its preprocessor symbols.
 type ShaderMetadata struct {
-	Name     string   `json:"name"`
-	Deferred bool     `json:"deferred"`
-	Files    []string `json:"files"`
+	Name     string              `json:"name"`
+	Deferred bool                `json:"deferred"`
+	Files    []string            `json:"files"`
+	Variants map[string][]string `json:"variants"`
 }
 
 // Load will load data from the reader.
 func (h *ShaderHandler) Load(r *Resource) error {
+	upload, err := h.Decode(r)
+	if err != nil {
+		return err
+	}
+
+	return upload()
+}
+
+// Decode reads the shader metadata and sources, and returns a function
+// building the shader.
+func (h *ShaderHandler) Decode(r *Resource) (func() error, error) {
 	m := &ShaderMetadata{}
-	s := NewShader()
 
 	data, err := ioutil.ReadAll(r.Reader())
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if err := json.Unmarshal(data, m); err != nil {
+		return nil, err
+	}
+
+	// Read shader data.
+	sources := make([][]byte, len(m.Files))
+	for i := range m.Files {
+		r, err := NewResource(filepath.Join(r.DirPrefix(), m.Files[i]))
+		if err != nil {
+			return nil, err
+		}
+		if err := GetAsset().ReadResource(r); err != nil {
+			return nil, err
+		}
+
+		sources[i] = r.Bytes()
+	}
+
+	return func() error {
+		name := m.Name
+		if h.has(name) {
+			return ErrAssetExists(name)
+		}
+
+		s := NewShader()
+		s.SetName(m.Name)
+		s.deferredCapable = m.Deferred
+		for variant, defines := range m.Variants {
+			s.SetVariant(variant, defines...)
+		}
+
+		for i := range sources {
+			s.AddData(sources[i])
+		}
+
+		return h.Add(name, s)
+	}, nil
+}
+
+// Verify checks that the shader metadata decodes and that its files can be
+// read.
+func (h *ShaderHandler) Verify(r *Resource) error {
+	m := &ShaderMetadata{}
+
+	if err := json.Unmarshal(r.Bytes(), m); err != nil {
 		return err
 	}
-	name := m.Name
-	if _, dup := h.Items[name]; dup {
-		return ErrAssetExists(name)
+
+	for i := range m.Files {
+		fr, err := NewResource(filepath.Join(r.DirPrefix(), m.Files[i]))
+		if err != nil {
+			return err
+		}
+		if err := GetAsset().ReadResource(fr); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Reload rebuilds the existing shader of the same name from the resource. If
+// the new program fails to build, the previous program is kept.
+func (h *ShaderHandler) Reload(r *Resource) error {
+	m := &ShaderMetadata{}
+
+	if err := json.Unmarshal(r.Bytes(), m); err != nil {
+		return err
+	}
+
+	s, err := h.Get(m.Name)
+	if err != nil {
+		return err
 	}
 
-	s.SetName(m.Name)
-	s.deferredCapable = m.Deferred
+	tmp := &Shader{
+		components:      make(map[ShaderComponent]uint32),
+		deferredCapable: m.Deferred,
+	}
+	for variant, defines := range m.Variants {
+		tmp.SetVariant(variant, defines...)
+	}
 
-	// Populate shader data.
 	for i := range m.Files {
-		r, err := NewResource(filepath.Join(r.DirPrefix(), m.Files[i]))
+		fr, err := NewResource(filepath.Join(r.DirPrefix(), m.Files[i]))
 		if err != nil {
 			return err
 		}
-		if err := GetAsset().ReadResource(r); err != nil {
+		if err := GetAsset().ReadResource(fr); err != nil {
 			return err
 		}
 
-		s.AddData(r.Bytes())
+		tmp.AddData(fr.Bytes())
+	}
+
+	if err := tmp.Alloc(); err != nil {
+		tmp.Dealloc()
+		return err
 	}
 
-	return h.Add(name, s)
+	s.Dealloc()
+
+	s.programId = tmp.programId
+	s.components = tmp.components
+	s.data = tmp.data
+	s.variantDefines = tmp.variantDefines
+	s.variants = tmp.variants
+	s.deferredCapable = tmp.deferredCapable
+
+	return nil
 }
 
 func (h *ShaderHandler) Add(name string, shader *Shader) error {
@@ -91,7 +192,7 @@ func (h *ShaderHandler) Add(name string, shader *Shader) error {
 		return err
 	}
 
-	h.Items[name] = shader.ID()
+	h.addItem(name, shader.ID())
 
 	return nil
 }
@@ -59,6 +59,7 @@ var rotMatrices = [6]mgl32.Mat4{
 }
 
 var _ AssetHandler = &SkyboxHandler{}
+var _ AssetVerifier = &SkyboxHandler{}
 
 type SkyboxMetadata struct {
 	Name       string `json:"name"`
@@ -90,7 +91,7 @@ func (h *SkyboxHandler) Load(r *Resource) error {
 		return err
 	}
 
-	if _, dup := h.Items[m.Name]; dup {
+	if h.has(m.Name) {
 		return ErrAssetExists(m.Name)
 	}
 
@@ -99,7 +100,32 @@ func (h *SkyboxHandler) Load(r *Resource) error {
 		return err
 	}
 
-	h.Items[m.Name] = skybox.ID()
+	h.addItem(m.Name, skybox.ID())
+
+	return nil
+}
+
+// Verify checks that the skybox metadata decodes and that its images can be
+// read and decoded.
+func (h *SkyboxHandler) Verify(r *Resource) error {
+	m := &SkyboxMetadata{}
+
+	if err := json.Unmarshal(r.Bytes(), m); err != nil {
+		return err
+	}
+	if m.Radiance == "" {
+		return errors.New("skybox: missing radiance map")
+	}
+
+	for _, file := range []string{m.Radiance, m.Specular, m.Irradiance} {
+		if file == "" {
+			continue
+		}
+
+		if err := verifyImageFile(filepath.Join(r.DirPrefix(), file)); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
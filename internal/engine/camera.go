@@ -30,6 +30,11 @@ import (
 
 var _ SceneGraphListener = &Camera{}
 var _ ScriptComponent = &Camera{}
+var _ SceneDocumenter = &Camera{}
+
+// ErrCameraPipeline reports that the render path or HDR of a camera was
+// changed after its render pipeline was set up.
+const ErrCameraPipeline = Error("camera: render path and hdr cannot change once awake")
 
 type RenderPath uint32
 
@@ -114,6 +119,10 @@ func (c *Camera) SetClearMode(mode ClearMode) {
 }
 
 func (c *Camera) Render() {
+	if c.framebuffer == nil {
+		return
+	}
+
 	c.startRender()
 
 	c.renderDeferred()
@@ -541,24 +550,71 @@ func NewCamera(renderPath RenderPath, hdr bool) *Camera {
 	c.SetName("Camera")
 	GetInstance().MustAssign(c)
 
-	c.setupPipeline()
 	c.UpdateMatrices()
 
 	return c
 }
 
-func CameraComponent(g *GameObject) *Camera {
-	c := g.Components()
-	for i := range c {
-		if ct, ok := c[i].(*Camera); ok {
-			return ct
-		}
+// cameraDocument is the serialized form of a Camera.
+type cameraDocument struct {
+	RenderPath   RenderPath
+	HDR          bool
+	ClearMode    ClearMode
+	ClearColor   Color
+	Fov          float32
+	NearClip     float32
+	FarClip      float32
+	Orthographic bool
+}
+
+// SceneDocument returns the settings of the camera.
+func (c *Camera) SceneDocument() (interface{}, error) {
+	return &cameraDocument{
+		RenderPath:   c.renderPath,
+		HDR:          c.hdr,
+		ClearMode:    c.clearMode,
+		ClearColor:   c.clearColor,
+		Fov:          c.fov,
+		NearClip:     c.nearClip,
+		FarClip:      c.farClip,
+		Orthographic: c.orthographic,
+	}, nil
+}
+
+// ApplySceneDocument sets the settings of the camera. The render path and HDR
+// can only be changed before the camera is awake.
+func (c *Camera) ApplySceneDocument(doc interface{}) error {
+	d := doc.(*cameraDocument)
+
+	if c.framebuffer != nil && (d.RenderPath != c.renderPath || d.HDR != c.hdr) {
+		return ErrCameraPipeline
 	}
 
+	c.renderPath = d.RenderPath
+	c.hdr = d.HDR
+	c.clearMode = d.ClearMode
+	c.clearColor = d.ClearColor
+	c.fov = d.Fov
+	c.nearClip = d.NearClip
+	c.farClip = d.FarClip
+	c.orthographic = d.Orthographic
+
+	c.UpdateMatrices()
+
 	return nil
 }
 
+func CameraComponent(g *GameObject) *Camera {
+	return GetComponent[*Camera](g)
+}
+
+// Awake sets up the render pipeline, once the settings of the camera are
+// known. Headless apps have no context to draw to, so they have no pipeline.
 func (c *Camera) Awake() {
+	if c.framebuffer == nil && !CurrentApp().Headless() {
+		c.setupPipeline()
+	}
+
 	c.Resize()
 }
 
@@ -570,8 +626,10 @@ func (c *Camera) Update() {
 
 func (c *Camera) Resize() {
 	c.aspectRatio = GetWindow().AspectRatio()
-	c.framebuffer.SetSize(GetWindow().Resolution())
-	if c.renderPath == RenderPathDeferred {
+	if c.framebuffer != nil {
+		c.framebuffer.SetSize(GetWindow().Resolution())
+	}
+	if c.gbuffer != nil {
 		c.gbuffer.SetSize(GetWindow().Resolution())
 	}
 	c.UpdateMatrices()
@@ -38,24 +38,32 @@ type Component interface {
 	Validate() error
 }
 
+// ScriptComponent is a component with a lifecycle. Once its GameObject is
+// active in a scene, the component is awoken, and enabled unless it has been
+// deactivated. Enabled components are started before their first update, and
+// receive the update calls until they are disabled.
 type ScriptComponent interface {
 	Component
 
-	// Active returns the active state of this component.
+	// Active returns the active state of this component. Components are
+	// active by default.
 	Active() bool
 
 	// SetActive sets the active state of this component.
 	SetActive(bool)
 
-	// OnActivate is called when the component transitions to the active state.
-	OnActivate()
+	// OnEnable is called when the component becomes enabled: it is active,
+	// and its GameObject is active in a scene.
+	OnEnable()
 
-	// OnDeactivate is called when the component transitions to the inactive state.
-	OnDeactivate()
+	// OnDisable is called when the component was enabled and no longer is,
+	// including before it is destroyed.
+	OnDisable()
 
-	// Awake is called when the Entity is loaded by the scene graph. Note: it is
-	// not guaranteed that all parent and child associations are made. If such conditions
-	// are required, use Start() instead.
+	// Awake is called once, when the GameObject is first active in a scene,
+	// even if the component itself is inactive. Note: it is not guaranteed
+	// that all other components are awake. If such conditions are required,
+	// use Start() instead.
 	Awake()
 
 	// FixedUpdate is called at a fixed interval, but not every frame.
@@ -67,13 +75,39 @@ type ScriptComponent interface {
 	// Update is called every frame, before the Render call.
 	Update()
 
-	// Start is called when the component is first initialized. This occurs when the
-	// Entity is added to the scene graph and after associations are made between the
-	// component and Entity.
+	// Start is called once, before the first Update of the component, once
+	// it has been enabled.
 	Start()
 
 	// GUIRender is called during the GUI drawing phase of the rendering.
 	GUIRender()
+
+	// scriptState returns the lifecycle state of the component.
+	scriptState() *scriptState
+}
+
+// Prioritized is implemented by script components which run in a defined
+// order. The update calls of components with a lower priority are made first.
+// Components without a priority have priority 0, and components of the same
+// priority run in scene graph order.
+type Prioritized interface {
+	// Priority returns the execution order of the component.
+	Priority() int
+}
+
+// DestroyListener is implemented by components which are notified when their
+// GameObject is destroyed.
+type DestroyListener interface {
+	// OnDestroy is called before the GameObject is removed from the scene
+	// graph and released.
+	OnDestroy()
+}
+
+// ParentListener is implemented by components which are notified when their
+// GameObject is moved to another parent.
+type ParentListener interface {
+	// OnParentChanged is called after the GameObject has a new parent.
+	OnParentChanged()
 }
 
 var _ Component = &BaseComponent{}
@@ -88,7 +122,15 @@ type BaseComponent struct {
 type BaseScriptComponent struct {
 	BaseComponent
 
-	active bool
+	state scriptState
+}
+
+// scriptState is the lifecycle state of a script component.
+type scriptState struct {
+	inactive bool
+	awake    bool
+	enabled  bool
+	started  bool
 }
 
 // GameObject returns the GameObject for this component.
@@ -117,31 +159,41 @@ func (c *BaseComponent) Validate() error {
 
 // Active returns the active state of this component.
 func (c *BaseScriptComponent) Active() bool {
-	return c.active
+	return !c.state.inactive
 }
 
-// SetActive sets the active state of this component.
+// SetActive sets the active state of this component. If the component is in
+// a scene, it is enabled or disabled accordingly.
 func (c *BaseScriptComponent) SetActive(active bool) {
-	if c.active != active {
-		c.active = active
+	if c.state.inactive != active {
+		return
+	}
+
+	c.state.inactive = !active
 
-		if c.active {
-			c.OnActivate()
-		} else {
-			c.OnDeactivate()
+	if g := c.GameObject(); g != nil {
+		for i := range g.components {
+			if sc, ok := g.components[i].(ScriptComponent); ok && sc.scriptState() == &c.state {
+				g.updateScript(sc)
+			}
 		}
 	}
 }
 
-// OnActivate is called when the component transitions to the active state.
-func (c *BaseScriptComponent) OnActivate() {}
+// Enabled reports whether the component is enabled, and receives updates.
+func (c *BaseScriptComponent) Enabled() bool {
+	return c.state.enabled
+}
+
+// OnEnable is called when the component becomes enabled.
+func (c *BaseScriptComponent) OnEnable() {}
 
-// OnDeactivate is called when the component transitions to the inactive state.
-func (c *BaseScriptComponent) OnDeactivate() {}
+// OnDisable is called when the component is no longer enabled.
+func (c *BaseScriptComponent) OnDisable() {}
 
-// Awake is called when the Entity is loaded by the scene graph. Note: it is
-// not guaranteed that all parent and child associations are made. If such conditions
-// are required, use Start() instead.
+// Awake is called once, when the GameObject is first active in a scene.
+// Note: it is not guaranteed that all other components are awake. If such
+// conditions are required, use Start() instead.
 func (c *BaseScriptComponent) Awake() {}
 
 // FixedUpdate is called at a regular interval, but not every frame.
@@ -153,10 +205,52 @@ func (c *BaseScriptComponent) LateUpdate() {}
 // Update is called every frame, before the Render call.
 func (c *BaseScriptComponent) Update() {}
 
-// Start is called when the component is first initialized. This occurs when the
-// Entity is added to the scene graph and after associations are made between the
-// component and Entity.
+// Start is called once, before the first Update of the component.
 func (c *BaseScriptComponent) Start() {}
 
 // GUIRender is called during the GUI drawing phase of the rendering.
 func (c *BaseScriptComponent) GUIRender() {}
+
+func (c *BaseScriptComponent) scriptState() *scriptState {
+	return &c.state
+}
+
+// componentPriority returns the priority of a component.
+func componentPriority(c Component) int {
+	if p, ok := c.(Prioritized); ok {
+		return p.Priority()
+	}
+
+	return 0
+}
+
+// sendScriptMessage makes the call of a per-frame message on the component, if
+// it is due.
+func sendScriptMessage(c ScriptComponent, msg Message) {
+	st := c.scriptState()
+	if !st.enabled {
+		return
+	}
+
+	if msg == MessageStart {
+		if !st.started {
+			st.started = true
+			c.Start()
+		}
+		return
+	}
+	if !st.started {
+		return
+	}
+
+	switch msg {
+	case MessageUpdate:
+		c.Update()
+	case MessageLateUpdate:
+		c.LateUpdate()
+	case MessageFixedUpdate:
+		c.FixedUpdate()
+	case MessageGUIRender:
+		c.GUIRender()
+	}
+}
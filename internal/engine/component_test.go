@@ -0,0 +1,261 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"reflect"
+	"testing"
+)
+
+type orderedComponent struct {
+	BaseScriptComponent
+
+	name     string
+	priority int
+	log      *[]string
+}
+
+func (c *orderedComponent) Awake()     { c.record("awake") }
+func (c *orderedComponent) OnEnable()  { c.record("enable") }
+func (c *orderedComponent) OnDisable() { c.record("disable") }
+func (c *orderedComponent) Start()     { c.record("start") }
+func (c *orderedComponent) Update()    { c.record("update") }
+func (c *orderedComponent) OnDestroy() { c.record("destroy") }
+func (c *orderedComponent) Priority() int {
+	return c.priority
+}
+
+func (c *orderedComponent) record(call string) {
+	*c.log = append(*c.log, c.name+":"+call)
+}
+
+func newOrderedObject(name string, priority int, log *[]string) (*GameObject, *orderedComponent) {
+	c := &orderedComponent{name: name, priority: priority, log: log}
+	GetInstance().MustAssign(c)
+
+	o := NewGameObject(name)
+	o.AddComponent(c)
+
+	return o, c
+}
+
+func TestScriptComponent_Lifecycle(t *testing.T) {
+	app := newHeadlessApp(t)
+	defer app.Teardown()
+
+	s := NewScene("test")
+	s.Setup()
+	g := s.Graph()
+
+	var log []string
+	expect := func(step string, want ...string) {
+		t.Helper()
+		if !reflect.DeepEqual(log, want) {
+			t.Errorf("%s: expected %v, got: %v", step, want, log)
+		}
+		log = nil
+	}
+	frame := func() {
+		g.SendMessage(MessageStart)
+		g.SendMessage(MessageUpdate)
+	}
+
+	parent, pc := newOrderedObject("parent", 0, &log)
+	child, _ := newOrderedObject("child", 0, &log)
+	parent.AddChild(child)
+
+	if err := g.AddGameObject(parent, nil); err != nil {
+		t.Fatal(err)
+	}
+	expect("add", "parent:awake", "parent:enable", "child:awake", "child:enable")
+
+	frame()
+	frame()
+	expect("frames", "parent:start", "child:start", "parent:update", "child:update", "parent:update", "child:update")
+
+	// Inactive components keep their game object's other calls, and are not
+	// started again when reactivated.
+	pc.SetActive(false)
+	frame()
+	pc.SetActive(true)
+	frame()
+	expect("component", "parent:disable", "child:update", "parent:enable", "parent:update", "child:update")
+
+	// Deactivating a game object disables its descendants.
+	parent.SetActive(false)
+	frame()
+	expect("object", "parent:disable", "child:disable")
+
+	// Moving an object under an inactive parent disables it, and moving it
+	// back under an active one enables it again.
+	mover, mc := newOrderedObject("mover", 0, &log)
+	if err := g.AddGameObject(mover, nil); err != nil {
+		t.Fatal(err)
+	}
+	frame()
+	log = nil
+
+	if err := g.SetParent(mover, parent, false); err != nil {
+		t.Fatal(err)
+	}
+	expect("reparent inactive", "mover:disable")
+	if mc.Enabled() {
+		t.Error("mover expected disabled under an inactive parent")
+	}
+
+	if err := g.SetParent(mover, nil, false); err != nil {
+		t.Fatal(err)
+	}
+	expect("reparent active", "mover:enable")
+	if !mc.Enabled() {
+		t.Error("mover expected enabled under an active parent")
+	}
+
+	// Objects added inactive are not awoken until they are activated.
+	late, _ := newOrderedObject("late", 0, &log)
+	late.SetActive(false)
+	if err := g.AddGameObject(late, nil); err != nil {
+		t.Fatal(err)
+	}
+	expect("inactive")
+	late.SetActive(true)
+	expect("activate", "late:awake", "late:enable")
+
+	if err := g.Destroy(late); err != nil {
+		t.Fatal(err)
+	}
+	expect("destroy", "late:disable", "late:destroy")
+}
+
+func TestScriptComponent_Priority(t *testing.T) {
+	app := newHeadlessApp(t)
+	defer app.Teardown()
+
+	s := NewScene("test")
+	s.Setup()
+	g := s.Graph()
+
+	var log []string
+
+	for _, p := range []struct {
+		name     string
+		priority int
+	}{{"a", 10}, {"b", -5}, {"c", 0}, {"d", 0}} {
+		o, _ := newOrderedObject(p.name, p.priority, &log)
+		if err := g.AddGameObject(o, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	log = nil
+	g.SendMessage(MessageStart)
+
+	want := []string{"b:start", "c:start", "d:start", "a:start"}
+	if !reflect.DeepEqual(log, want) {
+		t.Errorf("expected %v, got: %v", want, log)
+	}
+}
+
+type hookComponent struct {
+	orderedComponent
+
+	onUpdate func()
+}
+
+func (c *hookComponent) Update() {
+	c.orderedComponent.Update()
+
+	if c.onUpdate != nil {
+		c.onUpdate()
+	}
+}
+
+func TestSceneGraph_ChangeDuringUpdate(t *testing.T) {
+	app := newHeadlessApp(t)
+	defer app.Teardown()
+
+	s := NewScene("test")
+	s.Setup()
+	g := s.Graph()
+
+	var log []string
+	frame := func() {
+		g.SendMessage(MessageStart)
+		g.SendMessage(MessageUpdate)
+	}
+
+	hook := &hookComponent{orderedComponent: orderedComponent{name: "hook", priority: -1, log: &log}}
+	GetInstance().MustAssign(hook)
+	o := NewGameObject("hook")
+	o.AddComponent(hook)
+
+	if err := g.AddGameObject(o, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	var siblings []*GameObject
+	for _, name := range []string{"a", "b", "c"} {
+		sibling, _ := newOrderedObject(name, 0, &log)
+		if err := g.AddGameObject(sibling, nil); err != nil {
+			t.Fatal(err)
+		}
+		siblings = append(siblings, sibling)
+	}
+
+	frame()
+	log = nil
+
+	// Destroying a sibling shortens the script list while it is iterated.
+	hook.onUpdate = func() {
+		hook.onUpdate = nil
+		if err := g.Destroy(siblings[2]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	frame()
+
+	want := []string{"hook:update", "c:disable", "c:destroy", "a:update", "b:update"}
+	if !reflect.DeepEqual(log, want) {
+		t.Errorf("destroy: expected %v, got: %v", want, log)
+	}
+	log = nil
+
+	// Objects added during an update are first updated in the next frame.
+	hook.onUpdate = func() {
+		hook.onUpdate = nil
+		late, _ := newOrderedObject("late", 0, &log)
+		if err := g.AddGameObject(late, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	frame()
+	frame()
+
+	want = []string{
+		"hook:update", "late:awake", "late:enable", "a:update", "b:update",
+		"late:start", "hook:update", "a:update", "b:update", "late:update",
+	}
+	if !reflect.DeepEqual(log, want) {
+		t.Errorf("add: expected %v, got: %v", want, log)
+	}
+}
@@ -0,0 +1,89 @@
+//go:build go1.23
+
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import "iter"
+
+// coroutine runs a Coroutine with iter.Pull, so the coroutine runs on the
+// thread of the scheduler whenever it is resumed.
+type coroutine struct {
+	next     func() (Wait, bool)
+	stop     func()
+	running  bool
+	canceled bool
+	finished bool
+}
+
+func newCoroutine(fn Coroutine) *coroutine {
+	co := &coroutine{}
+
+	co.next, co.stop = iter.Pull(iter.Seq[Wait](func(yield func(Wait) bool) {
+		fn(func(w Wait) bool {
+			if co.canceled {
+				return false
+			}
+
+			return yield(w) && !co.canceled
+		})
+	}))
+
+	return co
+}
+
+// step resumes the coroutine, and returns its next wait, or false if it has
+// returned. A panic of the coroutine is raised again by step.
+func (co *coroutine) step() (Wait, bool) {
+	if co.finished {
+		return Wait{}, false
+	}
+
+	done := true
+	co.running = true
+	defer func() {
+		co.running = false
+		co.finished = done
+	}()
+
+	w, ok := co.next()
+	done = !ok
+
+	return w, ok
+}
+
+// cancel resumes the coroutine with false, and waits for it to return. If the
+// coroutine cancels itself, its next yield returns false instead.
+func (co *coroutine) cancel() {
+	if co.finished || co.canceled {
+		return
+	}
+
+	co.canceled = true
+	if co.running {
+		return
+	}
+
+	co.finished = true
+	co.stop()
+}
@@ -44,6 +44,11 @@ type Environment struct {
 func NewEnvironment() *Environment {
 	e := &Environment{}
 
+	// Headless apps have no shaders or textures to draw from.
+	if CurrentApp().Headless() {
+		return e
+	}
+
 	e.DeferredShader = DefaultShader()
 	e.Skybox = DefaultSkybox()
 
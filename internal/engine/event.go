@@ -0,0 +1,339 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"reflect"
+	"sync"
+)
+
+var _ System = &Events{}
+
+const SysNameEvents = "events"
+
+type eventScopeKind uint8
+
+const (
+	eventScopeGlobal eventScopeKind = iota
+	eventScopeScene
+	eventScopeSubtree
+	eventScopeObject
+)
+
+// EventScope selects the subscribers an event is delivered to.
+type EventScope struct {
+	kind   eventScopeKind
+	object *GameObject
+	scene  *Scene
+}
+
+// ScopeGlobal delivers an event to all subscribers.
+func ScopeGlobal() EventScope {
+	return EventScope{kind: eventScopeGlobal}
+}
+
+// ScopeScene delivers an event to the subscribers of the scene and of its game
+// objects.
+func ScopeScene(scene *Scene) EventScope {
+	return EventScope{kind: eventScopeScene, scene: scene}
+}
+
+// ScopeSubtree delivers an event to the subscribers of the game object and of
+// its descendants.
+func ScopeSubtree(object *GameObject) EventScope {
+	return EventScope{kind: eventScopeSubtree, object: object}
+}
+
+// ScopeObject delivers an event to the subscribers of the game object only.
+func ScopeObject(object *GameObject) EventScope {
+	return EventScope{kind: eventScopeObject, object: object}
+}
+
+// Subscription is a handler subscribed to a type of event. Subscriptions of a
+// game object are removed when it is destroyed.
+type Subscription struct {
+	events  *Events
+	typ     reflect.Type
+	object  *GameObject
+	scene   *Scene
+	handler func(interface{})
+	active  bool
+}
+
+// Unsubscribe stops delivering events to the handler.
+func (s *Subscription) Unsubscribe() {
+	if s.active {
+		s.events.remove(s)
+	}
+}
+
+// Active reports whether the handler is subscribed.
+func (s *Subscription) Active() bool {
+	return s.active
+}
+
+// receives reports whether the subscriber is in the scope.
+func (s *Subscription) receives(scope EventScope) bool {
+	switch scope.kind {
+	case eventScopeGlobal:
+		return true
+	case eventScopeScene:
+		if s.object != nil {
+			return s.object.Scene() == scope.scene
+		}
+		return s.scene == scope.scene
+	case eventScopeSubtree:
+		for o := s.object; o != nil; o = o.Parent() {
+			if o == scope.object {
+				return true
+			}
+		}
+		return false
+	case eventScopeObject:
+		return s.object == scope.object
+	}
+
+	return false
+}
+
+// Events is a system which delivers typed events to subscribed handlers.
+// Events are values of any type, and are delivered to the handlers subscribed
+// to their dynamic type.
+type Events struct {
+	subscriptions map[reflect.Type][]*Subscription
+	queue         []queuedEvent
+	mu            sync.Mutex
+}
+
+type queuedEvent struct {
+	scope EventScope
+	event interface{}
+}
+
+// Setup sets up the System.
+func (e *Events) Setup() error {
+	return nil
+}
+
+// Teardown tears down the System.
+func (e *Events) Teardown() {
+	for _, subs := range e.subscriptions {
+		for i := range subs {
+			subs[i].active = false
+		}
+	}
+
+	e.subscriptions = make(map[reflect.Type][]*Subscription)
+
+	e.mu.Lock()
+	e.queue = e.queue[:0]
+	e.mu.Unlock()
+}
+
+// Name returns the name of the System.
+func (e *Events) Name() string {
+	return SysNameEvents
+}
+
+// Publish delivers the event to the subscribers in scope immediately. Game
+// objects which are inactive do not receive events.
+func (e *Events) Publish(scope EventScope, event interface{}) {
+	subs := e.subscriptions[reflect.TypeOf(event)]
+
+	for _, s := range subs {
+		if !s.active || !s.receives(scope) {
+			continue
+		}
+		if s.object != nil && !s.object.Active() {
+			continue
+		}
+
+		s.handler(event)
+	}
+}
+
+// Queue adds the event to the queue, to be delivered by the next Flush. It is
+// safe to queue events from other goroutines.
+func (e *Events) Queue(scope EventScope, event interface{}) {
+	e.mu.Lock()
+	e.queue = append(e.queue, queuedEvent{scope, event})
+	e.mu.Unlock()
+}
+
+// Pending returns the number of queued events.
+func (e *Events) Pending() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return len(e.queue)
+}
+
+// Flush delivers the queued events in order. Events queued by the handlers are
+// delivered by the next flush.
+func (e *Events) Flush() {
+	e.mu.Lock()
+	queue := e.queue
+	e.queue = nil
+	e.mu.Unlock()
+
+	for i := range queue {
+		e.Publish(queue[i].scope, queue[i].event)
+	}
+}
+
+// UnsubscribeObject removes all subscriptions of the game object.
+func (e *Events) UnsubscribeObject(object *GameObject) {
+	e.unsubscribeObjects([]*GameObject{object})
+}
+
+// UnsubscribeScene removes all subscriptions of the scene. Subscriptions of
+// its game objects are kept.
+func (e *Events) UnsubscribeScene(scene *Scene) {
+	e.removeIf(func(s *Subscription) bool {
+		return s.object == nil && s.scene == scene
+	})
+}
+
+// Count returns the number of subscriptions.
+func (e *Events) Count() int {
+	n := 0
+	for _, subs := range e.subscriptions {
+		n += len(subs)
+	}
+
+	return n
+}
+
+// unsubscribeObjects removes all subscriptions of the game objects.
+func (e *Events) unsubscribeObjects(objects []*GameObject) {
+	set := make(map[*GameObject]struct{}, len(objects))
+	for i := range objects {
+		set[objects[i]] = struct{}{}
+	}
+
+	e.removeIf(func(s *Subscription) bool {
+		_, ok := set[s.object]
+		return s.object != nil && ok
+	})
+}
+
+func (e *Events) add(s *Subscription) *Subscription {
+	s.events = e
+	s.active = true
+
+	e.subscriptions[s.typ] = append(e.subscriptions[s.typ], s)
+
+	return s
+}
+
+// remove removes the subscription. The list is copied, so a publish in
+// progress keeps iterating the previous list.
+func (e *Events) remove(s *Subscription) {
+	s.active = false
+
+	subs := e.subscriptions[s.typ]
+	for i := range subs {
+		if subs[i] == s {
+			list := make([]*Subscription, 0, len(subs)-1)
+			list = append(list, subs[:i]...)
+			e.subscriptions[s.typ] = append(list, subs[i+1:]...)
+			return
+		}
+	}
+}
+
+func (e *Events) removeIf(fn func(*Subscription) bool) {
+	for _, subs := range e.subscriptions {
+		for _, s := range subs {
+			if fn(s) {
+				e.remove(s)
+			}
+		}
+	}
+}
+
+// NewEvents creates a new event system.
+func NewEvents() *Events {
+	return &Events{
+		subscriptions: make(map[reflect.Type][]*Subscription),
+	}
+}
+
+// GetEvents gets the event system from the current app.
+func GetEvents() *Events {
+	return CurrentApp().MustSystem(SysNameEvents).(*Events)
+}
+
+// Subscribe subscribes the handler of the game object to events of type E.
+// Events are matched by their dynamic type, so E should not be an interface
+// type. The game object receives global events, events of its scene, and events
+// scoped to it or to one of its ancestors.
+func Subscribe[E any](object *GameObject, handler func(E)) *Subscription {
+	return GetEvents().add(newSubscription(handler, object, nil))
+}
+
+// SubscribeScene subscribes the handler of the scene to events of type E. The
+// scene receives global events and events scoped to it.
+func SubscribeScene[E any](scene *Scene, handler func(E)) *Subscription {
+	return GetEvents().add(newSubscription(handler, nil, scene))
+}
+
+// SubscribeGlobal subscribes the handler to global events of type E, such as
+// for systems.
+func SubscribeGlobal[E any](handler func(E)) *Subscription {
+	return GetEvents().add(newSubscription[E](handler, nil, nil))
+}
+
+// Publish delivers the event to the subscribers in scope immediately.
+func Publish(scope EventScope, event interface{}) {
+	GetEvents().Publish(scope, event)
+}
+
+// QueueEvent queues the event, to be delivered to the subscribers in scope
+// after the scene update of the frame.
+func QueueEvent(scope EventScope, event interface{}) {
+	GetEvents().Queue(scope, event)
+}
+
+func newSubscription[E any](handler func(E), object *GameObject, scene *Scene) *Subscription {
+	return &Subscription{
+		typ:    reflect.TypeOf((*E)(nil)).Elem(),
+		object: object,
+		scene:  scene,
+		handler: func(event interface{}) {
+			handler(event.(E))
+		},
+	}
+}
+
+// events returns the event system of the current app, or nil if there is
+// none.
+func events() *Events {
+	if a := CurrentApp(); a != nil {
+		if s, err := a.System(SysNameEvents); err == nil {
+			return s.(*Events)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,152 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"testing"
+)
+
+type testEvent struct {
+	Value int
+}
+
+func TestEvents_Scopes(t *testing.T) {
+	app := newHeadlessApp(t)
+	defer app.Teardown()
+
+	scene := NewScene("events")
+	if err := scene.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	parent := NewGameObject("parent")
+	child := NewGameObject("child")
+	other := NewGameObject("other")
+	parent.AddChild(child)
+
+	if err := scene.Graph().AddGameObject(parent, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := scene.Graph().AddGameObject(other, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	got := map[string]int{}
+	record := func(name string) func(testEvent) {
+		return func(e testEvent) {
+			got[name] += e.Value
+		}
+	}
+
+	Subscribe(parent, record("parent"))
+	Subscribe(child, record("child"))
+	Subscribe(other, record("other"))
+	SubscribeScene(scene, record("scene"))
+	global := SubscribeGlobal(record("global"))
+
+	// Events of other types are not delivered.
+	Publish(ScopeGlobal(), struct{}{})
+
+	tests := []struct {
+		scope EventScope
+		want  map[string]int
+	}{
+		{ScopeObject(parent), map[string]int{"parent": 1}},
+		{ScopeSubtree(parent), map[string]int{"parent": 1, "child": 1}},
+		{ScopeScene(scene), map[string]int{"parent": 1, "child": 1, "other": 1, "scene": 1}},
+		{ScopeGlobal(), map[string]int{"parent": 1, "child": 1, "other": 1, "scene": 1, "global": 1}},
+	}
+
+	for i, tt := range tests {
+		got = map[string]int{}
+		Publish(tt.scope, testEvent{1})
+
+		if len(got) != len(tt.want) {
+			t.Errorf("scope %d: expected %v, got: %v", i, tt.want, got)
+			continue
+		}
+		for k, v := range tt.want {
+			if got[k] != v {
+				t.Errorf("scope %d: expected %v, got: %v", i, tt.want, got)
+			}
+		}
+	}
+
+	// Inactive game objects and unsubscribed handlers receive nothing.
+	got = map[string]int{}
+	other.SetActive(false)
+	global.Unsubscribe()
+	Publish(ScopeGlobal(), testEvent{1})
+	if got["other"] != 0 || got["global"] != 0 || global.Active() {
+		t.Errorf("expected no delivery to other and global, got: %v", got)
+	}
+
+	// Destroying a game object removes the subscriptions of its subtree.
+	n := GetEvents().Count()
+	if err := scene.Graph().Destroy(parent); err != nil {
+		t.Fatal(err)
+	}
+	if c := GetEvents().Count(); c != n-2 {
+		t.Errorf("expected %d subscriptions after destroy, got: %d", n-2, c)
+	}
+}
+
+func TestEvents_Queue(t *testing.T) {
+	app := newHeadlessApp(t)
+	defer app.Teardown()
+
+	e := GetEvents()
+
+	var values []int
+	var sub *Subscription
+	sub = SubscribeGlobal(func(ev testEvent) {
+		values = append(values, ev.Value)
+
+		// Events queued by handlers are delivered by the next flush.
+		if ev.Value == 1 {
+			e.Queue(ScopeGlobal(), testEvent{3})
+		}
+		if ev.Value == 3 {
+			sub.Unsubscribe()
+		}
+	})
+
+	e.Queue(ScopeGlobal(), testEvent{1})
+	e.Queue(ScopeGlobal(), testEvent{2})
+	if len(values) != 0 || e.Pending() != 2 {
+		t.Fatalf("expected 2 pending events, got: %d delivered, %d pending", len(values), e.Pending())
+	}
+
+	e.Flush()
+	if len(values) != 2 || values[0] != 1 || values[1] != 2 || e.Pending() != 1 {
+		t.Errorf("expected [1 2] and 1 pending, got: %v, %d pending", values, e.Pending())
+	}
+
+	// The app flushes the queue each frame.
+	app.Step(1)
+	e.Queue(ScopeGlobal(), testEvent{4})
+	app.Step(1)
+	if len(values) != 3 || values[2] != 3 {
+		t.Errorf("expected [1 2 3], got: %v", values)
+	}
+}
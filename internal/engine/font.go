@@ -301,6 +301,28 @@ func (f *Font) DrawText(text string, size float64) ([]Vertex, mgl32.Vec2) {
 	return verts, mgl32.Vec2{}
 }
 
+// TextWidth returns the advance of the text drawn at the given size.
+func (f *Font) TextWidth(text string, size float64) float32 {
+	if text == "" {
+		return 0
+	}
+
+	atlas := f.Atlas(size)
+	if atlas == nil {
+		return 0
+	}
+
+	var dot mgl64.Vec2
+	var prev rune
+
+	for _, r := range text {
+		_, _, _, dot = atlas.DrawRune(prev, r, dot)
+		prev = r
+	}
+
+	return float32(dot.X())
+}
+
 func (a *Atlas) Texture() *TextureFont {
 	return a.texture
 }
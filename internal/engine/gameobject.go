@@ -33,6 +33,17 @@ const (
 	MessageFixedUpdate
 	MessageGUIRender
 	MessageSGUpdate
+	MessageDestroy
+	MessageParentChanged
+)
+
+// Layers are bitmasks. A game object may be in several layers.
+const (
+	LayerDefault uint32 = 1 << iota
+	LayerUI
+
+	LayerNone uint32 = 0
+	LayerAll  uint32 = ^LayerNone
 )
 
 type GameObject struct {
@@ -42,6 +53,8 @@ type GameObject struct {
 	children   []*GameObject
 	parent     *GameObject
 	scene      *Scene
+	tags       []string
+	layer      uint32
 	active     bool
 }
 
@@ -50,16 +63,100 @@ func (g *GameObject) Active() bool {
 	return g.active
 }
 
-// SetActive sets the active state of this game object.
+// SetActive sets the active state of this game object. If the game object
+// is in a scene, the script components of it and its descendants are enabled
+// or disabled accordingly.
 func (g *GameObject) SetActive(active bool) {
 	if g.active != active {
 		g.active = active
 
-		// TODO: Notify scene graph
-		if g.Scene() != nil && g.Scene().Graph() != nil {
-			g.Scene().Graph().SetDirty()
+		g.setGraphDirty()
+		if g.scene != nil {
+			g.refreshScripts()
+		}
+	}
+}
+
+// ActiveInHierarchy reports whether this game object and all of its ancestors
+// are active.
+func (g *GameObject) ActiveInHierarchy() bool {
+	for o := g; o != nil; o = o.parent {
+		if !o.active {
+			return false
+		}
+	}
+
+	return true
+}
+
+// SetName sets the name of this game object.
+func (g *GameObject) SetName(name string) {
+	g.BaseObject.SetName(name)
+	g.setGraphDirty()
+}
+
+// Tags returns the tags of this game object.
+func (g *GameObject) Tags() []string {
+	return g.tags
+}
+
+// HasTag reports whether this game object has the tag.
+func (g *GameObject) HasTag(tag string) bool {
+	for i := range g.tags {
+		if g.tags[i] == tag {
+			return true
 		}
 	}
+
+	return false
+}
+
+// SetTags replaces the tags of this game object.
+func (g *GameObject) SetTags(tags ...string) {
+	g.tags = g.tags[:0]
+	for _, tag := range tags {
+		if !g.HasTag(tag) {
+			g.tags = append(g.tags, tag)
+		}
+	}
+
+	g.setGraphDirty()
+}
+
+// AddTag adds a tag to this game object.
+func (g *GameObject) AddTag(tag string) {
+	if g.HasTag(tag) {
+		return
+	}
+
+	g.tags = append(g.tags, tag)
+	g.setGraphDirty()
+}
+
+// RemoveTag removes a tag from this game object.
+func (g *GameObject) RemoveTag(tag string) {
+	for i := range g.tags {
+		if g.tags[i] == tag {
+			g.tags = append(g.tags[:i], g.tags[i+1:]...)
+			g.setGraphDirty()
+			return
+		}
+	}
+}
+
+// Layer returns the layer mask of this game object.
+func (g *GameObject) Layer() uint32 {
+	return g.layer
+}
+
+// SetLayer sets the layer mask of this game object.
+func (g *GameObject) SetLayer(layer uint32) {
+	g.layer = layer
+}
+
+// InLayers reports whether this game object is in any of the layers of mask.
+func (g *GameObject) InLayers(mask uint32) bool {
+	return g.layer&mask != 0
 }
 
 // Transform returns the transform for this game object.
@@ -95,34 +192,26 @@ func (g *GameObject) SendMessage(msg Message) {
 
 	for i := range g.components {
 		switch msg {
-		case MessageStart:
-			if c, ok := g.components[i].(ScriptComponent); ok {
-				c.Start()
-			}
 		case MessageAwake:
 			if c, ok := g.components[i].(ScriptComponent); ok {
-				c.Awake()
-			}
-		case MessageUpdate:
-			if c, ok := g.components[i].(ScriptComponent); ok {
-				c.Update()
-			}
-		case MessageLateUpdate:
-			if c, ok := g.components[i].(ScriptComponent); ok {
-				c.LateUpdate()
+				g.updateScript(c)
 			}
-		case MessageFixedUpdate:
+		case MessageStart, MessageUpdate, MessageLateUpdate, MessageFixedUpdate, MessageGUIRender:
 			if c, ok := g.components[i].(ScriptComponent); ok {
-				c.FixedUpdate()
-			}
-		case MessageGUIRender:
-			if c, ok := g.components[i].(ScriptComponent); ok {
-				c.GUIRender()
+				sendScriptMessage(c, msg)
 			}
 		case MessageSGUpdate:
 			if c, ok := g.components[i].(SceneGraphListener); ok {
 				c.OnSceneGraphUpdate()
 			}
+		case MessageDestroy:
+			if c, ok := g.components[i].(DestroyListener); ok {
+				c.OnDestroy()
+			}
+		case MessageParentChanged:
+			if c, ok := g.components[i].(ParentListener); ok {
+				c.OnParentChanged()
+			}
 		}
 	}
 }
@@ -176,6 +265,48 @@ func (g *GameObject) ComponentsInChildren() []Component {
 	return components
 }
 
+// GetComponent returns the first component of the game object of type T, or
+// the zero value of T if there is none.
+func GetComponent[T any](g *GameObject) T {
+	c, _ := componentOf[T](g)
+
+	return c
+}
+
+// GetComponentInChildren is like GetComponent, but also searches the
+// descendants of the game object, depth-first.
+func GetComponentInChildren[T any](g *GameObject) T {
+	c, _ := componentInChildren[T](g)
+
+	return c
+}
+
+func componentOf[T any](g *GameObject) (T, bool) {
+	for i := range g.components {
+		if c, ok := g.components[i].(T); ok {
+			return c, true
+		}
+	}
+
+	var zero T
+	return zero, false
+}
+
+func componentInChildren[T any](g *GameObject) (T, bool) {
+	if c, ok := componentOf[T](g); ok {
+		return c, true
+	}
+
+	for i := range g.children {
+		if c, ok := componentInChildren[T](g.children[i]); ok {
+			return c, true
+		}
+	}
+
+	var zero T
+	return zero, false
+}
+
 // ComponentsInChildren returns the components in any of the parent objects.
 func (g *GameObject) ComponentsInParent() []Component {
 	ancestors := g.Ancestors()
@@ -200,6 +331,13 @@ func (g *GameObject) AddComponent(component Component) {
 
 	g.components = append(g.components, component)
 	component.SetGameObject(g)
+
+	if g.scene != nil {
+		g.setGraphDirty()
+		if c, ok := component.(ScriptComponent); ok {
+			g.updateScript(c)
+		}
+	}
 }
 
 // AddChild adds a child game object to this game object.
@@ -213,21 +351,33 @@ func (g *GameObject) AddChild(child *GameObject) {
 	g.children = append(g.children, child)
 }
 
-// RemoveChild removes a child game object from this game object by ID.
+// RemoveChild removes a child game object from this game object by ID. Only
+// the child list is changed; use SceneGraph.RemoveGameObject to remove the
+// child from the scene.
 func (g *GameObject) RemoveChild(id uint32) {
-	// TODO: break all underlying associations
 	for i := range g.children {
 		if g.children[i].ID() == id {
-			g.children[i] = g.children[len(g.children)-1]
-			g.children = g.children[:len(g.children)-1]
+			g.children = append(g.children[:i], g.children[i+1:]...)
+			return
 		}
 	}
 }
 
-// AddChild removes all child objects from this game object.
+// RemoveAllChildren removes all child objects from this game object. If the
+// game object is in a scene, the children are removed from its scene graph.
 func (g *GameObject) RemoveAllChildren() {
-	// TODO: Add me
-	panic(ErrNotImplemented)
+	children := append([]*GameObject(nil), g.children...)
+
+	for _, child := range children {
+		if g.scene != nil && g.scene.Graph() != nil {
+			if err := g.scene.Graph().RemoveGameObject(child); err == nil {
+				continue
+			}
+		}
+
+		g.RemoveChild(child.ID())
+		child.parent = nil
+	}
 }
 
 // activate is called when the game object is initialized and needs to build
@@ -240,6 +390,52 @@ func (g *GameObject) activate() {
 	}
 }
 
+// updateScript awakens, enables or disables the script component, to match
+// the state of the component and of this game object.
+func (g *GameObject) updateScript(c ScriptComponent) {
+	st := c.scriptState()
+
+	live := g.scene != nil && g.ActiveInHierarchy()
+	if live && !st.awake {
+		st.awake = true
+		c.Awake()
+	}
+
+	if enable := live && !st.inactive; enable && !st.enabled {
+		st.enabled = true
+		c.OnEnable()
+	} else if !enable && st.enabled {
+		st.enabled = false
+		c.OnDisable()
+	}
+}
+
+// refreshScripts updates the script components of this game object and its
+// descendants.
+func (g *GameObject) refreshScripts() {
+	for i := range g.components {
+		if c, ok := g.components[i].(ScriptComponent); ok {
+			g.updateScript(c)
+		}
+	}
+
+	for i := range g.children {
+		g.children[i].refreshScripts()
+	}
+}
+
+// disableScripts disables the enabled script components of this game object.
+func (g *GameObject) disableScripts() {
+	for i := range g.components {
+		if c, ok := g.components[i].(ScriptComponent); ok {
+			if st := c.scriptState(); st.enabled {
+				st.enabled = false
+				c.OnDisable()
+			}
+		}
+	}
+}
+
 // SetScene sets the scene for this game object. Once set, the scene cannot be
 // changed or unset.
 func (g *GameObject) SetScene(scene *Scene) {
@@ -248,6 +444,13 @@ func (g *GameObject) SetScene(scene *Scene) {
 	}
 }
 
+// setGraphDirty marks the scene graph of this game object for an update.
+func (g *GameObject) setGraphDirty() {
+	if g.Scene() != nil && g.Scene().Graph() != nil {
+		g.Scene().Graph().SetDirty()
+	}
+}
+
 // Scene returns the scene for this game object.
 func (g *GameObject) Scene() *Scene {
 	return g.scene
@@ -266,14 +469,18 @@ func (g *GameObject) Ancestors() []*GameObject {
 }
 
 // OnParentChanged is called when the parent of this gameobject has changed.
+// The transform is recomputed and components implementing ParentListener are
+// notified.
 func (g *GameObject) OnParentChanged() {
 	g.Transform().Recompute(true)
+	g.SendMessage(MessageParentChanged)
 }
 
 // NewGameObject creates a new GameObject.
 func NewGameObject(name string) *GameObject {
 	g := &GameObject{
 		active:     true,
+		layer:      LayerDefault,
 		components: make([]Component, 1),
 		children:   []*GameObject{},
 	}
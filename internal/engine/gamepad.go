@@ -0,0 +1,243 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package engine
+
+import (
+	"github.com/go-gl/glfw/v3.2/glfw"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// GamepadButton is a button of the standard gamepad layout.
+type GamepadButton int
+
+const (
+	GamepadA GamepadButton = iota
+	GamepadB
+	GamepadX
+	GamepadY
+	GamepadLeftBumper
+	GamepadRightBumper
+	GamepadBack
+	GamepadStart
+	GamepadGuide
+	GamepadLeftThumb
+	GamepadRightThumb
+	GamepadDpadUp
+	GamepadDpadRight
+	GamepadDpadDown
+	GamepadDpadLeft
+	GamepadButtonCount
+)
+
+// GamepadAxis is an axis of the standard gamepad layout.
+type GamepadAxis int
+
+const (
+	GamepadLeftX GamepadAxis = iota
+	GamepadLeftY
+	GamepadRightX
+	GamepadRightY
+	GamepadLeftTrigger
+	GamepadRightTrigger
+	GamepadAxisCount
+)
+
+// GamepadMapping maps the standard gamepad layout to the raw button and
+// axis indices of a joystick. An index of -1 is unmapped.
+type GamepadMapping struct {
+	Buttons [GamepadButtonCount]int
+	Axes    [GamepadAxisCount]int
+}
+
+// DefaultGamepadMapping is the layout of XInput controllers, which is used
+// for joysticks without a mapping of their own.
+var DefaultGamepadMapping = GamepadMapping{
+	Buttons: [GamepadButtonCount]int{0, 1, 2, 3, 4, 5, 6, 7, -1, 8, 9, 10, 11, 12, 13},
+	Axes:    [GamepadAxisCount]int{0, 1, 2, 3, 4, 5},
+}
+
+// GamepadConnectedEvent is queued as a global event when a joystick is
+// connected.
+type GamepadConnectedEvent struct {
+	Joystick glfw.Joystick
+	Name     string
+}
+
+// GamepadDisconnectedEvent is queued as a global event when a joystick is
+// disconnected.
+type GamepadDisconnectedEvent struct {
+	Joystick glfw.Joystick
+}
+
+// InputSource is a source of joystick state, which the input system polls
+// every frame.
+type InputSource interface {
+	// JoystickPresent reports if the joystick is connected.
+	JoystickPresent(joy glfw.Joystick) bool
+
+	// JoystickName returns the name of the joystick.
+	JoystickName(joy glfw.Joystick) string
+
+	// JoystickAxes returns the axis positions of the joystick.
+	JoystickAxes(joy glfw.Joystick) []float32
+
+	// JoystickButtons returns the button states of the joystick, as
+	// glfw.Press or glfw.Release.
+	JoystickButtons(joy glfw.Joystick) []byte
+}
+
+// glfwInputSource is an InputSource backed by GLFW.
+type glfwInputSource struct{}
+
+func (s *glfwInputSource) JoystickPresent(joy glfw.Joystick) bool {
+	return glfw.JoystickPresent(joy)
+}
+
+func (s *glfwInputSource) JoystickName(joy glfw.Joystick) string {
+	return glfw.GetJoystickName(joy)
+}
+
+func (s *glfwInputSource) JoystickAxes(joy glfw.Joystick) []float32 {
+	return glfw.GetJoystickAxes(joy)
+}
+
+func (s *glfwInputSource) JoystickButtons(joy glfw.Joystick) []byte {
+	return glfw.GetJoystickButtons(joy)
+}
+
+// Gamepad is the state of a connected joystick for the current frame.
+type Gamepad struct {
+	joystick glfw.Joystick
+	name     string
+	mapping  GamepadMapping
+	axes     []float32
+	buttons  []bool
+	previous []bool
+}
+
+// Joystick returns the joystick of the gamepad.
+func (g *Gamepad) Joystick() glfw.Joystick {
+	return g.joystick
+}
+
+// Name returns the name of the gamepad.
+func (g *Gamepad) Name() string {
+	return g.name
+}
+
+// Mapping returns the standard layout mapping of the gamepad.
+func (g *Gamepad) Mapping() GamepadMapping {
+	return g.mapping
+}
+
+// AxisCount returns the number of raw axes.
+func (g *Gamepad) AxisCount() int {
+	return len(g.axes)
+}
+
+// ButtonCount returns the number of raw buttons.
+func (g *Gamepad) ButtonCount() int {
+	return len(g.buttons)
+}
+
+// RawAxis returns the position of the raw axis.
+func (g *Gamepad) RawAxis(i int) float32 {
+	if i < 0 || i >= len(g.axes) {
+		return 0
+	}
+
+	return g.axes[i]
+}
+
+// RawButtonHeld reports if the raw button is held.
+func (g *Gamepad) RawButtonHeld(i int) bool {
+	return i >= 0 && i < len(g.buttons) && g.buttons[i]
+}
+
+// RawButtonDown reports if the raw button was pressed this frame.
+func (g *Gamepad) RawButtonDown(i int) bool {
+	return g.RawButtonHeld(i) && !g.wasHeld(i)
+}
+
+// RawButtonUp reports if the raw button was released this frame.
+func (g *Gamepad) RawButtonUp(i int) bool {
+	return !g.RawButtonHeld(i) && g.wasHeld(i)
+}
+
+// Axis returns the position of the standard axis. Sticks range from -1 to
+// 1. Triggers, which GLFW reports from -1 at rest to 1, are rescaled to range
+// from 0 at rest to 1.
+func (g *Gamepad) Axis(a GamepadAxis) float32 {
+	if a < 0 || a >= GamepadAxisCount {
+		return 0
+	}
+
+	i := g.mapping.Axes[a]
+	if i < 0 || i >= len(g.axes) {
+		return 0
+	}
+
+	v := g.axes[i]
+	if a == GamepadLeftTrigger || a == GamepadRightTrigger {
+		v = mgl32.Clamp((v+1)/2, 0, 1)
+	}
+
+	return v
+}
+
+// ButtonHeld reports if the standard button is held.
+func (g *Gamepad) ButtonHeld(b GamepadButton) bool {
+	return g.RawButtonHeld(g.buttonIndex(b))
+}
+
+// ButtonDown reports if the standard button was pressed this frame.
+func (g *Gamepad) ButtonDown(b GamepadButton) bool {
+	return g.RawButtonDown(g.buttonIndex(b))
+}
+
+// ButtonUp reports if the standard button was released this frame.
+func (g *Gamepad) ButtonUp(b GamepadButton) bool {
+	return g.RawButtonUp(g.buttonIndex(b))
+}
+
+func (g *Gamepad) buttonIndex(b GamepadButton) int {
+	if b < 0 || b >= GamepadButtonCount {
+		return -1
+	}
+
+	return g.mapping.Buttons[b]
+}
+
+func (g *Gamepad) wasHeld(i int) bool {
+	return i < len(g.previous) && g.previous[i]
+}
+
+// poll reads the state of the gamepad from the source.
+func (g *Gamepad) poll(source InputSource) {
+	g.axes = append(g.axes[:0], source.JoystickAxes(g.joystick)...)
+	g.previous = append(g.previous[:0], g.buttons...)
+	g.buttons = g.buttons[:0]
+
+	for _, b := range source.JoystickButtons(g.joystick) {
+		g.buttons = append(g.buttons, glfw.Action(b) == glfw.Press)
+	}
+}
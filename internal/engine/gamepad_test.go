@@ -0,0 +1,245 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package engine
+
+import (
+	"testing"
+
+	"github.com/go-gl/glfw/v3.2/glfw"
+)
+
+// fakeInputSource is an InputSource of fake joysticks.
+type fakeInputSource struct {
+	names   map[glfw.Joystick]string
+	axes    map[glfw.Joystick][]float32
+	buttons map[glfw.Joystick][]byte
+}
+
+func newFakeInputSource() *fakeInputSource {
+	return &fakeInputSource{
+		names:   make(map[glfw.Joystick]string),
+		axes:    make(map[glfw.Joystick][]float32),
+		buttons: make(map[glfw.Joystick][]byte),
+	}
+}
+
+func (s *fakeInputSource) connect(joy glfw.Joystick, name string) {
+	s.names[joy] = name
+	s.axes[joy] = make([]float32, 6)
+	s.buttons[joy] = make([]byte, 14)
+}
+
+func (s *fakeInputSource) disconnect(joy glfw.Joystick) {
+	delete(s.names, joy)
+	delete(s.axes, joy)
+	delete(s.buttons, joy)
+}
+
+func (s *fakeInputSource) press(joy glfw.Joystick, button int, pressed bool) {
+	if pressed {
+		s.buttons[joy][button] = byte(glfw.Press)
+	} else {
+		s.buttons[joy][button] = byte(glfw.Release)
+	}
+}
+
+func (s *fakeInputSource) JoystickPresent(joy glfw.Joystick) bool {
+	_, ok := s.names[joy]
+	return ok
+}
+
+func (s *fakeInputSource) JoystickName(joy glfw.Joystick) string {
+	return s.names[joy]
+}
+
+func (s *fakeInputSource) JoystickAxes(joy glfw.Joystick) []float32 {
+	return s.axes[joy]
+}
+
+func (s *fakeInputSource) JoystickButtons(joy glfw.Joystick) []byte {
+	return s.buttons[joy]
+}
+
+func TestGamepad_Connect(t *testing.T) {
+	a := newHeadlessApp(t)
+	defer a.Teardown()
+
+	var connected []GamepadConnectedEvent
+	var disconnected []GamepadDisconnectedEvent
+
+	SubscribeGlobal(func(e GamepadConnectedEvent) { connected = append(connected, e) })
+	SubscribeGlobal(func(e GamepadDisconnectedEvent) { disconnected = append(disconnected, e) })
+
+	pad := newFakeInputSource()
+	pad.connect(glfw.Joystick3, "second")
+	pad.connect(glfw.Joystick1, "first")
+
+	in := GetInput()
+	in.SetSource(pad)
+
+	a.Step(1)
+
+	if n := len(in.Connected()); n != 2 {
+		t.Error("in.Connected() expected 2 gamepads, got:", n)
+	}
+	if g := in.Gamepads(); len(g) != 2 || g[0].Name() != "first" || g[1].Joystick() != glfw.Joystick3 {
+		t.Error("in.Gamepads() expected first and second, got:", g)
+	}
+	if len(connected) != 2 {
+		t.Error("GamepadConnectedEvent expected 2, got:", connected)
+	}
+
+	pad.disconnect(glfw.Joystick3)
+	a.Step(1)
+
+	if len(in.Connected()) != 0 {
+		t.Error("in.Connected() expected empty")
+	}
+	if d := in.Disconnected(); len(d) != 1 || d[0] != glfw.Joystick3 {
+		t.Error("in.Disconnected() expected [Joystick3], got:", d)
+	}
+	if in.Gamepad(glfw.Joystick3) != nil {
+		t.Error("in.Gamepad(Joystick3) expected nil")
+	}
+	if len(disconnected) != 1 || disconnected[0].Joystick != glfw.Joystick3 {
+		t.Error("GamepadDisconnectedEvent expected Joystick3, got:", disconnected)
+	}
+}
+
+func TestGamepad_Buttons(t *testing.T) {
+	pad := newFakeInputSource()
+	pad.connect(glfw.Joystick1, "pad")
+
+	in := NewInput()
+	in.SetSource(pad)
+	in.Update()
+
+	g := in.Gamepad(glfw.Joystick1)
+	if g == nil {
+		t.Fatal("in.Gamepad(Joystick1) expected gamepad")
+	}
+
+	// Triggers rest at -1.
+	pad.axes[glfw.Joystick1][4] = -1
+	pad.axes[glfw.Joystick1][5] = -1
+	in.Update()
+
+	if v := g.Axis(GamepadLeftTrigger); v != 0 {
+		t.Error("g.Axis(GamepadLeftTrigger) at rest expected 0, got:", v)
+	}
+
+	pad.press(glfw.Joystick1, 0, true)
+	pad.axes[glfw.Joystick1][4] = 0
+	in.Update()
+
+	if !g.ButtonDown(GamepadA) || !g.ButtonHeld(GamepadA) || g.ButtonUp(GamepadA) {
+		t.Error("GamepadA expected down and held")
+	}
+	if v := g.Axis(GamepadLeftTrigger); v != 0.5 {
+		t.Error("g.Axis(GamepadLeftTrigger) expected 0.5, got:", v)
+	}
+
+	in.Update()
+
+	if g.ButtonDown(GamepadA) || !g.ButtonHeld(GamepadA) {
+		t.Error("GamepadA expected held only")
+	}
+
+	pad.press(glfw.Joystick1, 0, false)
+	in.Update()
+
+	if !g.ButtonUp(GamepadA) || g.ButtonHeld(GamepadA) {
+		t.Error("GamepadA expected up")
+	}
+	if g.ButtonHeld(GamepadGuide) || g.RawButtonHeld(99) {
+		t.Error("unmapped buttons expected not held")
+	}
+}
+
+func TestGamepad_Mapping(t *testing.T) {
+	m := DefaultGamepadMapping
+	m.Buttons[GamepadA] = 3
+	m.Axes[GamepadLeftX] = 5
+
+	pad := newFakeInputSource()
+	pad.connect(glfw.Joystick2, "odd")
+
+	in := NewInput()
+	in.SetGamepadMapping("odd", m)
+	in.SetSource(pad)
+
+	pad.press(glfw.Joystick2, 3, true)
+	pad.axes[glfw.Joystick2][5] = -1
+	in.Update()
+
+	g := in.Gamepad(glfw.Joystick2)
+	if !g.ButtonHeld(GamepadA) {
+		t.Error("GamepadA expected held through mapping")
+	}
+	if v := g.Axis(GamepadLeftX); v != -1 {
+		t.Error("g.Axis(GamepadLeftX) expected -1, got:", v)
+	}
+}
+
+func TestGamepad_Bindings(t *testing.T) {
+	pad := newFakeInputSource()
+	pad.connect(glfw.Joystick1, "pad")
+
+	in := NewInput()
+	in.SetSource(pad)
+	in.DefineAction("Jump", GamepadButtonBinding(glfw.Joystick1, GamepadA))
+	in.DefineAction("Brake", GamepadAxisBinding(glfw.Joystick1, GamepadRightTrigger).WithScale(-1))
+	in.DefineAction("Move",
+		GamepadAxisBinding(glfw.Joystick1, GamepadLeftX).WithDeadZone(0.2),
+		GamepadAxisBinding(glfw.Joystick1, GamepadLeftY).WithDirection([2]float32{0, -1}),
+	)
+	in.Update()
+
+	pad.axes[glfw.Joystick1][5] = -1
+	in.Update()
+
+	if in.Down("Brake") {
+		t.Error("Brake expected up with the trigger at rest")
+	}
+
+	pad.press(glfw.Joystick1, 0, true)
+	pad.axes[glfw.Joystick1][0] = 0.6
+	pad.axes[glfw.Joystick1][1] = 0.5
+	in.Update()
+
+	if !in.Pressed("Jump") {
+		t.Error("Jump expected pressed")
+	}
+	if v := in.Vector("Move"); !nearFloat(v[0], 0.5) || !nearFloat(v[1], -0.5) {
+		t.Error("Vector(Move) expected {0.5 -0.5}, got:", v)
+	}
+	if b, ok := in.LastControl(); !ok || b.Kind != BindingGamepadButton || b.GamepadButton != GamepadA {
+		t.Error("LastControl() expected GamepadA, got:", b, ok)
+	}
+
+	pad.disconnect(glfw.Joystick1)
+	in.Update()
+
+	if !in.Released("Jump") || in.Down("Move") {
+		t.Error("Jump expected released and Move up after disconnect")
+	}
+}
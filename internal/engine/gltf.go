@@ -0,0 +1,891 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// glTF errors
+const (
+	ErrGLTFInvalid = Error("gltf: invalid document")
+	ErrGLTFVersion = Error("gltf: unsupported version")
+	ErrGLTFSparse  = Error("gltf: sparse accessors are not supported")
+)
+
+// glTF accessor component types.
+const (
+	gltfByte          = 5120
+	gltfUnsignedByte  = 5121
+	gltfShort         = 5122
+	gltfUnsignedShort = 5123
+	gltfUnsignedInt   = 5125
+	gltfFloat         = 5126
+)
+
+const gltfModeTriangles = 4
+
+// gltfMaxZeroValues is the most values an accessor without a buffer view may
+// have. Its values are zero, so its count is not limited by a buffer.
+const gltfMaxZeroValues = 1 << 24
+
+// Binary glTF container constants.
+const (
+	glbMagic     = 0x46546C67
+	glbChunkJSON = 0x4E4F534A
+	glbChunkBIN  = 0x004E4942
+)
+
+type gltfDocument struct {
+	Asset struct {
+		Version string `json:"version"`
+	} `json:"asset"`
+	Scene       *int             `json:"scene"`
+	Scenes      []gltfScene      `json:"scenes"`
+	Nodes       []gltfNode       `json:"nodes"`
+	Meshes      []gltfMesh       `json:"meshes"`
+	Accessors   []gltfAccessor   `json:"accessors"`
+	BufferViews []gltfBufferView `json:"bufferViews"`
+	Buffers     []gltfBuffer     `json:"buffers"`
+	Materials   []gltfMaterial   `json:"materials"`
+	Textures    []gltfTexture    `json:"textures"`
+	Images      []gltfImage      `json:"images"`
+	Skins       []gltfSkin       `json:"skins"`
+
+	bin     []byte
+	buffers [][]byte
+}
+
+type gltfScene struct {
+	Name  string `json:"name"`
+	Nodes []int  `json:"nodes"`
+}
+
+type gltfNode struct {
+	Name        string      `json:"name"`
+	Children    []int       `json:"children"`
+	Mesh        *int        `json:"mesh"`
+	Skin        *int        `json:"skin"`
+	Translation *mgl32.Vec3 `json:"translation"`
+	Rotation    *mgl32.Vec4 `json:"rotation"`
+	Scale       *mgl32.Vec3 `json:"scale"`
+	Matrix      *mgl32.Mat4 `json:"matrix"`
+}
+
+type gltfMesh struct {
+	Name       string          `json:"name"`
+	Primitives []gltfPrimitive `json:"primitives"`
+}
+
+type gltfPrimitive struct {
+	Attributes map[string]int `json:"attributes"`
+	Indices    *int           `json:"indices"`
+	Material   *int           `json:"material"`
+	Mode       *int           `json:"mode"`
+}
+
+type gltfAccessor struct {
+	BufferView    *int            `json:"bufferView"`
+	ByteOffset    int             `json:"byteOffset"`
+	ComponentType int             `json:"componentType"`
+	Normalized    bool            `json:"normalized"`
+	Count         int             `json:"count"`
+	Type          string          `json:"type"`
+	Sparse        json.RawMessage `json:"sparse"`
+}
+
+type gltfBufferView struct {
+	Buffer     int `json:"buffer"`
+	ByteOffset int `json:"byteOffset"`
+	ByteLength int `json:"byteLength"`
+	ByteStride int `json:"byteStride"`
+}
+
+type gltfSkin struct {
+	Name                string `json:"name"`
+	InverseBindMatrices *int   `json:"inverseBindMatrices"`
+	Joints              []int  `json:"joints"`
+}
+
+type gltfBuffer struct {
+	URI        string `json:"uri"`
+	ByteLength int    `json:"byteLength"`
+}
+
+type gltfMaterial struct {
+	Name                 string           `json:"name"`
+	PBRMetallicRoughness *gltfPBR         `json:"pbrMetallicRoughness"`
+	NormalTexture        *gltfTextureInfo `json:"normalTexture"`
+}
+
+type gltfPBR struct {
+	BaseColorFactor          *mgl32.Vec4      `json:"baseColorFactor"`
+	BaseColorTexture         *gltfTextureInfo `json:"baseColorTexture"`
+	MetallicFactor           *float32         `json:"metallicFactor"`
+	RoughnessFactor          *float32         `json:"roughnessFactor"`
+	MetallicRoughnessTexture *gltfTextureInfo `json:"metallicRoughnessTexture"`
+}
+
+type gltfTextureInfo struct {
+	Index int `json:"index"`
+}
+
+type gltfTexture struct {
+	Source *int `json:"source"`
+}
+
+type gltfImage struct {
+	Name       string `json:"name"`
+	URI        string `json:"uri"`
+	MimeType   string `json:"mimeType"`
+	BufferView *int   `json:"bufferView"`
+}
+
+// decodeGLTF decodes a glTF document from either the JSON or binary container.
+func decodeGLTF(data []byte) (*gltfDocument, error) {
+	doc := &gltfDocument{}
+
+	if len(data) >= 12 && binary.LittleEndian.Uint32(data) == glbMagic {
+		var err error
+		if data, doc.bin, err = decodeGLB(data); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := json.Unmarshal(data, doc); err != nil {
+		return nil, err
+	}
+
+	if !strings.HasPrefix(doc.Asset.Version, "2.") {
+		return nil, ErrGLTFVersion
+	}
+
+	return doc, nil
+}
+
+// decodeGLB splits a binary glTF container into its JSON and binary chunks.
+func decodeGLB(data []byte) (jsonChunk, binChunk []byte, err error) {
+	if binary.LittleEndian.Uint32(data[4:]) != 2 {
+		return nil, nil, ErrGLTFVersion
+	}
+
+	length := int(binary.LittleEndian.Uint32(data[8:]))
+	if length > len(data) {
+		return nil, nil, ErrGLTFInvalid
+	}
+
+	for offset := 12; offset+8 <= length; {
+		chunkLength := int(binary.LittleEndian.Uint32(data[offset:]))
+		chunkType := binary.LittleEndian.Uint32(data[offset+4:])
+		offset += 8
+
+		if offset+chunkLength > length {
+			return nil, nil, ErrGLTFInvalid
+		}
+
+		switch chunkType {
+		case glbChunkJSON:
+			jsonChunk = data[offset : offset+chunkLength]
+		case glbChunkBIN:
+			binChunk = data[offset : offset+chunkLength]
+		}
+
+		offset += chunkLength
+	}
+
+	if jsonChunk == nil {
+		return nil, nil, ErrGLTFInvalid
+	}
+
+	return jsonChunk, binChunk, nil
+}
+
+// decodeDataURI decodes a base64 data URI.
+func decodeDataURI(uri string) ([]byte, error) {
+	i := strings.Index(uri, ",")
+	if i < 0 || !strings.HasSuffix(uri[:i], ";base64") {
+		return nil, fmt.Errorf("gltf: unsupported data uri")
+	}
+
+	return base64.StdEncoding.DecodeString(uri[i+1:])
+}
+
+// loadBuffers resolves the data of all buffers. External buffers are read
+// with the readFile function.
+func (d *gltfDocument) loadBuffers(readFile func(uri string) ([]byte, error)) error {
+	d.buffers = make([][]byte, len(d.Buffers))
+
+	for i, b := range d.Buffers {
+		var data []byte
+		var err error
+
+		switch {
+		case b.URI == "":
+			data = d.bin
+		case strings.HasPrefix(b.URI, "data:"):
+			data, err = decodeDataURI(b.URI)
+		default:
+			data, err = readFile(b.URI)
+		}
+
+		if err != nil {
+			return err
+		}
+		if len(data) < b.ByteLength {
+			return fmt.Errorf("gltf: buffer %d is shorter than its byte length", i)
+		}
+
+		d.buffers[i] = data
+	}
+
+	return nil
+}
+
+// bufferView returns the data for a buffer view.
+func (d *gltfDocument) bufferView(index int) ([]byte, int, error) {
+	if index < 0 || index >= len(d.BufferViews) {
+		return nil, 0, ErrGLTFInvalid
+	}
+
+	v := d.BufferViews[index]
+	if v.Buffer < 0 || v.Buffer >= len(d.buffers) {
+		return nil, 0, ErrGLTFInvalid
+	}
+	if v.ByteOffset < 0 || v.ByteLength < 0 || v.ByteStride < 0 {
+		return nil, 0, ErrGLTFInvalid
+	}
+
+	data := d.buffers[v.Buffer]
+	if v.ByteOffset+v.ByteLength > len(data) {
+		return nil, 0, ErrGLTFInvalid
+	}
+
+	return data[v.ByteOffset : v.ByteOffset+v.ByteLength], v.ByteStride, nil
+}
+
+// fits reports if the elements of the accessor fit in a buffer view of the
+// given length. The stride must be positive.
+func (a *gltfAccessor) fits(length, stride, elementSize int) bool {
+	if a.ByteOffset < 0 || a.Count < 0 || a.ByteOffset > length {
+		return false
+	}
+	if a.Count == 0 {
+		return true
+	}
+
+	// Divide rather than multiply, so large counts cannot overflow.
+	space := length - a.ByteOffset - elementSize
+	if space < 0 {
+		return false
+	}
+
+	return a.Count-1 <= space/stride
+}
+
+func gltfComponentCount(accessorType string) int {
+	switch accessorType {
+	case "SCALAR":
+		return 1
+	case "VEC2":
+		return 2
+	case "VEC3":
+		return 3
+	case "VEC4", "MAT2":
+		return 4
+	case "MAT3":
+		return 9
+	case "MAT4":
+		return 16
+	default:
+		return 0
+	}
+}
+
+func gltfComponentSize(componentType int) int {
+	switch componentType {
+	case gltfByte, gltfUnsignedByte:
+		return 1
+	case gltfShort, gltfUnsignedShort:
+		return 2
+	case gltfUnsignedInt, gltfFloat:
+		return 4
+	default:
+		return 0
+	}
+}
+
+// readAccessor reads the elements of an accessor as float32 values. Integer
+// components are converted, and normalized if the accessor requires it.
+func (d *gltfDocument) readAccessor(index int) ([]float32, int, error) {
+	if index < 0 || index >= len(d.Accessors) {
+		return nil, 0, ErrGLTFInvalid
+	}
+
+	a := d.Accessors[index]
+	if len(a.Sparse) != 0 {
+		return nil, 0, ErrGLTFSparse
+	}
+
+	n := gltfComponentCount(a.Type)
+	size := gltfComponentSize(a.ComponentType)
+	if n == 0 || size == 0 || a.Count < 0 {
+		return nil, 0, ErrGLTFInvalid
+	}
+
+	// An accessor without a buffer view is initialized with zeros.
+	if a.BufferView == nil {
+		if a.Count > gltfMaxZeroValues/n {
+			return nil, 0, ErrGLTFInvalid
+		}
+		return make([]float32, a.Count*n), n, nil
+	}
+
+	data, stride, err := d.bufferView(*a.BufferView)
+	if err != nil {
+		return nil, 0, err
+	}
+	if stride == 0 {
+		stride = n * size
+	}
+
+	if !a.fits(len(data), stride, n*size) {
+		return nil, 0, ErrGLTFInvalid
+	}
+
+	values := make([]float32, a.Count*n)
+
+	for i := 0; i < a.Count; i++ {
+		for j := 0; j < n; j++ {
+			b := data[a.ByteOffset+i*stride+j*size:]
+
+			var v float32
+
+			switch a.ComponentType {
+			case gltfFloat:
+				v = math.Float32frombits(binary.LittleEndian.Uint32(b))
+			case gltfUnsignedInt:
+				v = float32(binary.LittleEndian.Uint32(b))
+			case gltfUnsignedShort:
+				v = float32(binary.LittleEndian.Uint16(b))
+				if a.Normalized {
+					v /= 65535
+				}
+			case gltfShort:
+				v = float32(int16(binary.LittleEndian.Uint16(b)))
+				if a.Normalized {
+					v = float32(math.Max(float64(v)/32767, -1))
+				}
+			case gltfUnsignedByte:
+				v = float32(b[0])
+				if a.Normalized {
+					v /= 255
+				}
+			case gltfByte:
+				v = float32(int8(b[0]))
+				if a.Normalized {
+					v = float32(math.Max(float64(v)/127, -1))
+				}
+			}
+
+			values[i*n+j] = v
+		}
+	}
+
+	return values, n, nil
+}
+
+// readVec3 reads a VEC3 accessor.
+func (d *gltfDocument) readVec3(index int) ([]mgl32.Vec3, error) {
+	values, n, err := d.readAccessor(index)
+	if err != nil {
+		return nil, err
+	}
+	if n != 3 {
+		return nil, ErrGLTFInvalid
+	}
+
+	v := make([]mgl32.Vec3, len(values)/3)
+	for i := range v {
+		v[i] = mgl32.Vec3{values[i*3], values[i*3+1], values[i*3+2]}
+	}
+
+	return v, nil
+}
+
+// readVec2 reads a VEC2 accessor.
+func (d *gltfDocument) readVec2(index int) ([]mgl32.Vec2, error) {
+	values, n, err := d.readAccessor(index)
+	if err != nil {
+		return nil, err
+	}
+	if n != 2 {
+		return nil, ErrGLTFInvalid
+	}
+
+	v := make([]mgl32.Vec2, len(values)/2)
+	for i := range v {
+		v[i] = mgl32.Vec2{values[i*2], values[i*2+1]}
+	}
+
+	return v, nil
+}
+
+// readVec4 reads a VEC4 accessor.
+func (d *gltfDocument) readVec4(index int) ([]mgl32.Vec4, error) {
+	values, n, err := d.readAccessor(index)
+	if err != nil {
+		return nil, err
+	}
+	if n != 4 {
+		return nil, ErrGLTFInvalid
+	}
+
+	v := make([]mgl32.Vec4, len(values)/4)
+	for i := range v {
+		v[i] = mgl32.Vec4{values[i*4], values[i*4+1], values[i*4+2], values[i*4+3]}
+	}
+
+	return v, nil
+}
+
+// readMat4 reads a MAT4 accessor. Matrices are stored in column major order,
+// like mgl32.Mat4.
+func (d *gltfDocument) readMat4(index int) ([]mgl32.Mat4, error) {
+	values, n, err := d.readAccessor(index)
+	if err != nil {
+		return nil, err
+	}
+	if n != 16 || d.Accessors[index].ComponentType != gltfFloat {
+		return nil, ErrGLTFInvalid
+	}
+
+	m := make([]mgl32.Mat4, len(values)/16)
+	for i := range m {
+		copy(m[i][:], values[i*16:])
+	}
+
+	return m, nil
+}
+
+// readIndices reads a SCALAR index accessor.
+func (d *gltfDocument) readIndices(index int) ([]uint32, error) {
+	if index < 0 || index >= len(d.Accessors) {
+		return nil, ErrGLTFInvalid
+	}
+
+	a := d.Accessors[index]
+	if a.Type != "SCALAR" || a.Normalized || a.Count < 0 {
+		return nil, ErrGLTFInvalid
+	}
+
+	switch a.ComponentType {
+	case gltfUnsignedByte, gltfUnsignedShort:
+		values, _, err := d.readAccessor(index)
+		if err != nil {
+			return nil, err
+		}
+
+		indices := make([]uint32, len(values))
+		for i := range values {
+			indices[i] = uint32(values[i])
+		}
+
+		return indices, nil
+	case gltfUnsignedInt:
+		// Read directly, as float32 cannot represent all 32 bit indices.
+		if a.BufferView == nil {
+			return make([]uint32, a.Count), nil
+		}
+
+		data, stride, err := d.bufferView(*a.BufferView)
+		if err != nil {
+			return nil, err
+		}
+		if stride == 0 {
+			stride = 4
+		}
+		if !a.fits(len(data), stride, 4) {
+			return nil, ErrGLTFInvalid
+		}
+
+		indices := make([]uint32, a.Count)
+		for i := range indices {
+			indices[i] = binary.LittleEndian.Uint32(data[a.ByteOffset+i*stride:])
+		}
+
+		return indices, nil
+	default:
+		return nil, ErrGLTFInvalid
+	}
+}
+
+// primitive reads the vertex data of a triangle primitive. Missing normals are
+// generated from the triangles, and missing texture coordinates are zero.
+func (d *gltfDocument) primitive(p *gltfPrimitive) (v, n []mgl32.Vec3, t []mgl32.Vec2, indices []uint32, err error) {
+	position, ok := p.Attributes["POSITION"]
+	if !ok {
+		return nil, nil, nil, nil, fmt.Errorf("gltf: primitive has no positions")
+	}
+
+	if v, err = d.readVec3(position); err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	if p.Indices != nil {
+		if indices, err = d.readIndices(*p.Indices); err != nil {
+			return nil, nil, nil, nil, err
+		}
+	} else {
+		indices = make([]uint32, len(v))
+		for i := range indices {
+			indices[i] = uint32(i)
+		}
+	}
+
+	for i := range indices {
+		if int(indices[i]) >= len(v) {
+			return nil, nil, nil, nil, ErrGLTFInvalid
+		}
+	}
+
+	if normal, ok := p.Attributes["NORMAL"]; ok {
+		if n, err = d.readVec3(normal); err != nil {
+			return nil, nil, nil, nil, err
+		}
+	} else {
+		n = computeNormals(v, indices)
+	}
+
+	if uv, ok := p.Attributes["TEXCOORD_0"]; ok {
+		if t, err = d.readVec2(uv); err != nil {
+			return nil, nil, nil, nil, err
+		}
+	} else {
+		t = make([]mgl32.Vec2, len(v))
+	}
+
+	if len(n) != len(v) || len(t) != len(v) {
+		return nil, nil, nil, nil, ErrGLTFInvalid
+	}
+
+	return v, n, t, indices, nil
+}
+
+// primitiveSkin reads the joint indices and weights of a skinned primitive.
+// Both are nil if the primitive is not skinned. Joint indices refer to the
+// joints of the skin of the node using the mesh.
+func (d *gltfDocument) primitiveSkin(p *gltfPrimitive, count int) ([][4]uint16, []mgl32.Vec4, error) {
+	jointsIndex, hasJoints := p.Attributes["JOINTS_0"]
+	weightsIndex, hasWeights := p.Attributes["WEIGHTS_0"]
+	if !hasJoints && !hasWeights {
+		return nil, nil, nil
+	}
+	if !hasJoints || !hasWeights {
+		return nil, nil, ErrGLTFInvalid
+	}
+
+	// Joint indices are unsigned integers, never normalized.
+	if jointsIndex < 0 || jointsIndex >= len(d.Accessors) {
+		return nil, nil, ErrGLTFInvalid
+	}
+	if a := d.Accessors[jointsIndex]; a.Normalized || (a.ComponentType != gltfUnsignedByte && a.ComponentType != gltfUnsignedShort) {
+		return nil, nil, ErrGLTFInvalid
+	}
+
+	values, err := d.readVec4(jointsIndex)
+	if err != nil {
+		return nil, nil, err
+	}
+	weights, err := d.readVec4(weightsIndex)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(values) != count || len(weights) != count {
+		return nil, nil, ErrGLTFInvalid
+	}
+
+	joints := make([][4]uint16, len(values))
+	for i := range values {
+		for j := range values[i] {
+			joints[i][j] = uint16(values[i][j])
+		}
+	}
+
+	return joints, weights, nil
+}
+
+// skinJoints returns the joints of a skin, named by the names of their nodes.
+// Joints are ordered so that parents precede their children, as skeletons
+// require; order holds the position of each joint of the skin in that order.
+// The parent of a joint is its nearest ancestor node which is also a joint.
+func (d *gltfDocument) skinJoints(index int, names []string) (joints []Joint, order []int, err error) {
+	if index < 0 || index >= len(d.Skins) {
+		return nil, nil, ErrGLTFInvalid
+	}
+
+	skin := &d.Skins[index]
+	if len(skin.Joints) == 0 {
+		return nil, nil, ErrGLTFInvalid
+	}
+
+	parent, err := d.nodeParents()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The joint of each node, or -1 for nodes which are not joints.
+	jointOf := make([]int, len(d.Nodes))
+	for i := range jointOf {
+		jointOf[i] = -1
+	}
+	for i, n := range skin.Joints {
+		if n < 0 || n >= len(d.Nodes) || jointOf[n] != -1 {
+			return nil, nil, ErrGLTFInvalid
+		}
+		jointOf[n] = i
+	}
+
+	inverseBind := make([]mgl32.Mat4, len(skin.Joints))
+	for i := range inverseBind {
+		inverseBind[i] = mgl32.Ident4()
+	}
+	if skin.InverseBindMatrices != nil {
+		m, err := d.readMat4(*skin.InverseBindMatrices)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(m) < len(skin.Joints) {
+			return nil, nil, ErrGLTFInvalid
+		}
+		copy(inverseBind, m)
+	}
+
+	jointParent := make([]int, len(skin.Joints))
+	depth := make([]int, len(skin.Joints))
+	for i, n := range skin.Joints {
+		jointParent[i] = -1
+		for p := parent[n]; p != -1; p = parent[p] {
+			if jointOf[p] != -1 && jointParent[i] == -1 {
+				jointParent[i] = jointOf[p]
+			}
+
+			// Deeper chains than there are nodes are cycles.
+			if depth[i]++; depth[i] > len(d.Nodes) {
+				return nil, nil, ErrGLTFInvalid
+			}
+		}
+	}
+
+	// Keep the order of the skin if parents already precede their children,
+	// as vertices refer to joints by their index in the skin. Otherwise, sort
+	// the joints by depth.
+	sorted := make([]int, len(skin.Joints))
+	for i := range sorted {
+		sorted[i] = i
+	}
+	for i, p := range jointParent {
+		if p >= i {
+			sort.SliceStable(sorted, func(a, b int) bool {
+				return depth[sorted[a]] < depth[sorted[b]]
+			})
+			break
+		}
+	}
+
+	order = make([]int, len(skin.Joints))
+	for i, j := range sorted {
+		order[j] = i
+	}
+
+	joints = make([]Joint, len(skin.Joints))
+	for i, j := range sorted {
+		n := skin.Joints[j]
+
+		joints[i] = Joint{
+			Name:        names[n],
+			Parent:      -1,
+			InverseBind: inverseBind[j],
+		}
+		if p := jointParent[j]; p != -1 {
+			joints[i].Parent = order[p]
+		}
+
+		pose := &joints[i].Pose
+		pose.Position, pose.Rotation, pose.Scale = gltfNodeTransform(&d.Nodes[n])
+	}
+
+	return joints, order, nil
+}
+
+// nodeParents returns the parent of each node, or -1 for nodes without a
+// parent. Child indices must be valid, and each node must have at most one
+// parent.
+func (d *gltfDocument) nodeParents() ([]int, error) {
+	parent := make([]int, len(d.Nodes))
+	for i := range parent {
+		parent[i] = -1
+	}
+
+	for i := range d.Nodes {
+		for _, c := range d.Nodes[i].Children {
+			if c < 0 || c >= len(d.Nodes) || parent[c] != -1 || c == i {
+				return nil, ErrGLTFInvalid
+			}
+			parent[c] = i
+		}
+	}
+
+	return parent, nil
+}
+
+// rootNodes returns the root nodes of the default scene. If the document has
+// no scenes, nodes which are not the child of any other node are returned.
+// The nodes must form a forest: child indices must be valid, and each node
+// must have at most one parent and must not be its own ancestor.
+func (d *gltfDocument) rootNodes() ([]int, error) {
+	parent, err := d.nodeParents()
+	if err != nil {
+		return nil, err
+	}
+
+	// Nodes not reachable from a node without a parent are part of a cycle.
+	visited := make([]bool, len(d.Nodes))
+	var roots, stack []int
+
+	for i := range d.Nodes {
+		if parent[i] != -1 {
+			continue
+		}
+
+		roots = append(roots, i)
+		stack = append(stack[:0], i)
+
+		for len(stack) > 0 {
+			n := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+
+			visited[n] = true
+			stack = append(stack, d.Nodes[n].Children...)
+		}
+	}
+
+	for i := range visited {
+		if !visited[i] {
+			return nil, ErrGLTFInvalid
+		}
+	}
+
+	if len(d.Scenes) == 0 {
+		return roots, nil
+	}
+
+	scene := 0
+	if d.Scene != nil && *d.Scene >= 0 && *d.Scene < len(d.Scenes) {
+		scene = *d.Scene
+	}
+
+	// Scenes may only list each root node once.
+	listed := make([]bool, len(d.Nodes))
+	for _, n := range d.Scenes[scene].Nodes {
+		if n < 0 || n >= len(d.Nodes) || parent[n] != -1 || listed[n] {
+			return nil, ErrGLTFInvalid
+		}
+		listed[n] = true
+	}
+
+	return d.Scenes[scene].Nodes, nil
+}
+
+// gltfNodeTransform returns the local transform of a node. Matrices are
+// decomposed into translation, rotation and scale.
+func gltfNodeTransform(n *gltfNode) (position mgl32.Vec3, rotation mgl32.Quat, scale mgl32.Vec3) {
+	position = mgl32.Vec3{}
+	rotation = mgl32.QuatIdent()
+	scale = mgl32.Vec3{1, 1, 1}
+
+	if n.Matrix != nil {
+		return decomposeMatrix(*n.Matrix)
+	}
+
+	if n.Translation != nil {
+		position = *n.Translation
+	}
+	if n.Rotation != nil {
+		rotation = mgl32.Quat{W: n.Rotation[3], V: n.Rotation.Vec3()}
+	}
+	if n.Scale != nil {
+		scale = *n.Scale
+	}
+
+	return position, rotation, scale
+}
+
+// imageData returns the encoded data of an image. External images are read
+// with the readFile function.
+func (d *gltfDocument) imageData(index int, readFile func(uri string) ([]byte, error)) ([]byte, error) {
+	if index < 0 || index >= len(d.Images) {
+		return nil, ErrGLTFInvalid
+	}
+
+	img := d.Images[index]
+
+	switch {
+	case img.BufferView != nil:
+		data, _, err := d.bufferView(*img.BufferView)
+		return data, err
+	case strings.HasPrefix(img.URI, "data:"):
+		return decodeDataURI(img.URI)
+	default:
+		return readFile(img.URI)
+	}
+}
+
+// computeNormals generates smooth vertex normals from indexed triangles.
+func computeNormals(v []mgl32.Vec3, indices []uint32) []mgl32.Vec3 {
+	n := make([]mgl32.Vec3, len(v))
+
+	for i := 0; i+2 < len(indices); i += 3 {
+		a, b, c := indices[i], indices[i+1], indices[i+2]
+		faceNormal := v[b].Sub(v[a]).Cross(v[c].Sub(v[a]))
+
+		n[a] = n[a].Add(faceNormal)
+		n[b] = n[b].Add(faceNormal)
+		n[c] = n[c].Add(faceNormal)
+	}
+
+	for i := range n {
+		if n[i].Len() > 0 {
+			n[i] = n[i].Normalize()
+		}
+	}
+
+	return n
+}
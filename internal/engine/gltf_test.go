@@ -0,0 +1,336 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// testGLTFBuffer returns a buffer with three positions followed by three
+// unsigned short indices.
+func testGLTFBuffer() []byte {
+	buf := &bytes.Buffer{}
+
+	binary.Write(buf, binary.LittleEndian, []float32{0, 0, 0, 1, 0, 0, 0, 1, 0})
+	binary.Write(buf, binary.LittleEndian, []uint16{0, 1, 2, 0})
+
+	return buf.Bytes()
+}
+
+func testGLTFJSON(uri string) string {
+	return fmt.Sprintf(`{
+	"asset": {"version": "2.0"},
+	"scene": 0,
+	"scenes": [{"nodes": [0]}],
+	"nodes": [
+		{"name": "root", "children": [1], "matrix": [2,0,0,0, 0,2,0,0, 0,0,2,0, 1,2,3,1]},
+		{"name": "tri", "mesh": 0, "rotation": [0, 0.7071068, 0, 0.7071068]}
+	],
+	"meshes": [{"primitives": [{"attributes": {"POSITION": 0}, "indices": 1}]}],
+	"accessors": [
+		{"bufferView": 0, "componentType": 5126, "count": 3, "type": "VEC3"},
+		{"bufferView": 1, "componentType": 5123, "count": 3, "type": "SCALAR"}
+	],
+	"bufferViews": [
+		{"buffer": 0, "byteOffset": 0, "byteLength": 36},
+		{"buffer": 0, "byteOffset": 36, "byteLength": 6}
+	],
+	"buffers": [{%s"byteLength": 44}]
+}`, uri)
+}
+
+func testGLTFPrimitive(t *testing.T, doc *gltfDocument) {
+	v, n, uv, indices, err := doc.primitive(&doc.Meshes[0].Primitives[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(v) != 3 || v[1] != (mgl32.Vec3{1, 0, 0}) {
+		t.Errorf("unexpected vertices: %v", v)
+	}
+	if len(indices) != 3 || indices[2] != 2 {
+		t.Errorf("unexpected indices: %v", indices)
+	}
+	if len(uv) != 3 {
+		t.Errorf("expected 3 uvs, got: %d", len(uv))
+	}
+	if len(n) != 3 || !n[0].ApproxEqual(mgl32.Vec3{0, 0, 1}) {
+		t.Errorf("unexpected normals: %v", n)
+	}
+}
+
+func TestDecodeGLTF(t *testing.T) {
+	uri := `"uri": "data:application/octet-stream;base64,` + base64.StdEncoding.EncodeToString(testGLTFBuffer()) + `", `
+
+	doc, err := decodeGLTF([]byte(testGLTFJSON(uri)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.loadBuffers(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	testGLTFPrimitive(t, doc)
+
+	if roots, err := doc.rootNodes(); err != nil || len(roots) != 1 || roots[0] != 0 {
+		t.Errorf("roots expected [0], got: %v %v", roots, err)
+	}
+
+	p, r, s := gltfNodeTransform(&doc.Nodes[0])
+	if !p.ApproxEqual(mgl32.Vec3{1, 2, 3}) || !s.ApproxEqual(mgl32.Vec3{2, 2, 2}) || !r.ApproxEqual(mgl32.QuatIdent()) {
+		t.Errorf("unexpected matrix decomposition: %v %v %v", p, r, s)
+	}
+
+	_, r, _ = gltfNodeTransform(&doc.Nodes[1])
+	if !r.ApproxEqualThreshold(mgl32.QuatRotate(mgl32.DegToRad(90), mgl32.Vec3{0, 1, 0}), 1e-5) {
+		t.Errorf("unexpected rotation: %v", r)
+	}
+}
+
+func TestDecodeGLTF_Binary(t *testing.T) {
+	jsonChunk := []byte(testGLTFJSON(""))
+	for len(jsonChunk)%4 != 0 {
+		jsonChunk = append(jsonChunk, ' ')
+	}
+	binChunk := testGLTFBuffer()
+
+	buf := &bytes.Buffer{}
+	binary.Write(buf, binary.LittleEndian, []uint32{glbMagic, 2, uint32(12 + 8 + len(jsonChunk) + 8 + len(binChunk))})
+	binary.Write(buf, binary.LittleEndian, []uint32{uint32(len(jsonChunk)), glbChunkJSON})
+	buf.Write(jsonChunk)
+	binary.Write(buf, binary.LittleEndian, []uint32{uint32(len(binChunk)), glbChunkBIN})
+	buf.Write(binChunk)
+
+	doc, err := decodeGLTF(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.loadBuffers(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	testGLTFPrimitive(t, doc)
+}
+
+func TestDecodeGLTF_Version(t *testing.T) {
+	if _, err := decodeGLTF([]byte(`{"asset": {"version": "1.0"}}`)); err != ErrGLTFVersion {
+		t.Errorf("expected ErrGLTFVersion, got: %v", err)
+	}
+}
+
+func TestGLTF_InvalidNodes(t *testing.T) {
+	cases := []struct {
+		name string
+		json string
+	}{
+		{"child out of range", `"nodes": [{"children": [2]}, {}]`},
+		{"negative child", `"nodes": [{"children": [-1]}]`},
+		{"own child", `"nodes": [{"children": [0]}]`},
+		{"cycle", `"nodes": [{"children": [1]}, {"children": [2]}, {"children": [1]}]`},
+		{"unreachable cycle", `"nodes": [{}, {"children": [2]}, {"children": [1]}]`},
+		{"shared child", `"nodes": [{"children": [2]}, {"children": [2]}, {}]`},
+		{"scene node out of range", `"scenes": [{"nodes": [3]}], "nodes": [{}]`},
+		{"scene node not a root", `"scenes": [{"nodes": [1]}], "nodes": [{"children": [1]}, {}]`},
+		{"scene node listed twice", `"scenes": [{"nodes": [0, 0]}], "nodes": [{}]`},
+	}
+
+	for _, c := range cases {
+		doc, err := decodeGLTF([]byte(`{"asset": {"version": "2.0"}, ` + c.json + `}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := doc.rootNodes(); err != ErrGLTFInvalid {
+			t.Errorf("%s: expected ErrGLTFInvalid, got: %v", c.name, err)
+		}
+	}
+}
+
+func TestGLTF_InvalidAccessors(t *testing.T) {
+	cases := []struct {
+		name       string
+		accessor   string
+		bufferView string
+	}{
+		{"negative accessor offset", `"byteOffset": -4, "componentType": 5126, "count": 1, "type": "VEC3"`, `"byteLength": 36`},
+		{"negative count", `"componentType": 5126, "count": -1, "type": "VEC3"`, `"byteLength": 36`},
+		{"count past end", `"componentType": 5126, "count": 4, "type": "VEC3"`, `"byteLength": 36`},
+		{"offset past end", `"byteOffset": 32, "componentType": 5126, "count": 1, "type": "VEC3"`, `"byteLength": 36`},
+		{"huge count", `"componentType": 5126, "count": 4611686018427387904, "type": "VEC3"`, `"byteLength": 36`},
+		{"stride past end", `"componentType": 5126, "count": 2, "type": "VEC3"`, `"byteLength": 36, "byteStride": 32`},
+		{"negative stride", `"componentType": 5126, "count": 2, "type": "VEC3"`, `"byteLength": 36, "byteStride": -12`},
+		{"negative view offset", `"componentType": 5126, "count": 1, "type": "VEC3"`, `"byteOffset": -4, "byteLength": 36`},
+		{"negative view length", `"componentType": 5126, "count": 0, "type": "VEC3"`, `"byteLength": -1`},
+		{"view past buffer", `"componentType": 5126, "count": 1, "type": "VEC3"`, `"byteOffset": 40, "byteLength": 36`},
+	}
+
+	uri := "data:application/octet-stream;base64," + base64.StdEncoding.EncodeToString(testGLTFBuffer())
+
+	for _, c := range cases {
+		doc, err := decodeGLTF([]byte(fmt.Sprintf(`{
+	"asset": {"version": "2.0"},
+	"accessors": [{"bufferView": 0, %s}],
+	"bufferViews": [{"buffer": 0, %s}],
+	"buffers": [{"uri": "%s", "byteLength": 44}]
+}`, c.accessor, c.bufferView, uri)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := doc.loadBuffers(nil); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, _, err := doc.readAccessor(0); err != ErrGLTFInvalid {
+			t.Errorf("%s: expected ErrGLTFInvalid, got: %v", c.name, err)
+		}
+	}
+
+	// Accessors without a buffer view are zeros, up to a limit.
+	doc, err := decodeGLTF([]byte(`{
+	"asset": {"version": "2.0"},
+	"accessors": [
+		{"componentType": 5126, "count": 2, "type": "VEC3"},
+		{"componentType": 5126, "count": 4611686018427387904, "type": "VEC3"}
+	]
+}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, n, err := doc.readAccessor(0); err != nil || n != 3 || len(v) != 6 {
+		t.Errorf("expected 6 zeros, got: %v %d %v", v, n, err)
+	}
+	if _, _, err := doc.readAccessor(1); err != ErrGLTFInvalid {
+		t.Errorf("huge count without buffer view: expected ErrGLTFInvalid, got: %v", err)
+	}
+
+	// 32 bit indices are read separately, and are checked the same way.
+	doc, err = decodeGLTF([]byte(fmt.Sprintf(`{
+	"asset": {"version": "2.0"},
+	"accessors": [{"bufferView": 0, "byteOffset": 40, "componentType": 5125, "count": 2, "type": "SCALAR"}],
+	"bufferViews": [{"buffer": 0, "byteLength": 44}],
+	"buffers": [{"uri": "%s", "byteLength": 44}]
+}`, uri)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.loadBuffers(nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := doc.readIndices(0); err != ErrGLTFInvalid {
+		t.Errorf("expected ErrGLTFInvalid for indices past end, got: %v", err)
+	}
+}
+
+func TestGLTF_Skin(t *testing.T) {
+	buf := &bytes.Buffer{}
+	binary.Write(buf, binary.LittleEndian, []float32{0, 0, 0, 1, 0, 0, 0, 1, 0})
+	binary.Write(buf, binary.LittleEndian, []uint8{0, 1, 0, 0, 1, 0, 0, 0, 1, 0, 0, 0})
+	binary.Write(buf, binary.LittleEndian, []float32{0.5, 0.5, 0, 0, 1, 0, 0, 0, 1, 0, 0, 0})
+
+	leg, hip := mgl32.Translate3D(0, 1, 0), mgl32.Translate3D(0, 2, 0)
+	binary.Write(buf, binary.LittleEndian, leg[:])
+	binary.Write(buf, binary.LittleEndian, hip[:])
+
+	uri := "data:application/octet-stream;base64," + base64.StdEncoding.EncodeToString(buf.Bytes())
+
+	// The skin lists the leg before its parent, so the joints are reordered.
+	doc, err := decodeGLTF([]byte(fmt.Sprintf(`{
+	"asset": {"version": "2.0"},
+	"nodes": [
+		{"name": "body", "mesh": 0, "skin": 0},
+		{"name": "armature", "children": [2]},
+		{"name": "hip", "children": [3], "translation": [0, -2, 0]},
+		{"children": [], "translation": [0, 1, 0]}
+	],
+	"meshes": [{"primitives": [{"attributes": {"POSITION": 0, "JOINTS_0": 1, "WEIGHTS_0": 2}}]}],
+	"skins": [{"inverseBindMatrices": 3, "joints": [3, 2]}],
+	"accessors": [
+		{"bufferView": 0, "componentType": 5126, "count": 3, "type": "VEC3"},
+		{"bufferView": 1, "componentType": 5121, "count": 3, "type": "VEC4"},
+		{"bufferView": 2, "componentType": 5126, "count": 3, "type": "VEC4"},
+		{"bufferView": 3, "componentType": 5126, "count": 2, "type": "MAT4"}
+	],
+	"bufferViews": [
+		{"buffer": 0, "byteOffset": 0, "byteLength": 36},
+		{"buffer": 0, "byteOffset": 36, "byteLength": 12},
+		{"buffer": 0, "byteOffset": 48, "byteLength": 48},
+		{"buffer": 0, "byteOffset": 96, "byteLength": 128}
+	],
+	"buffers": [{"uri": "%s", "byteLength": 224}]
+}`, uri)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.loadBuffers(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	joints, weights, err := doc.primitiveSkin(&doc.Meshes[0].Primitives[0], 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(joints) != 3 || joints[0] != [4]uint16{0, 1, 0, 0} || joints[2] != [4]uint16{1, 0, 0, 0} {
+		t.Errorf("unexpected joints: %v", joints)
+	}
+	if len(weights) != 3 || weights[0] != (mgl32.Vec4{0.5, 0.5, 0, 0}) {
+		t.Errorf("unexpected weights: %v", weights)
+	}
+
+	skeleton, order, err := doc.skinJoints(0, []string{"body", "armature", "hip", "node3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(order) != 2 || order[0] != 1 || order[1] != 0 {
+		t.Fatalf("expected order [1 0], got: %v", order)
+	}
+	if skeleton[0].Name != "hip" || skeleton[0].Parent != -1 || skeleton[1].Name != "node3" || skeleton[1].Parent != 0 {
+		t.Errorf("unexpected joints: %+v", skeleton)
+	}
+	if skeleton[0].InverseBind != hip || skeleton[1].InverseBind != leg {
+		t.Errorf("unexpected inverse bind matrices: %v %v", skeleton[0].InverseBind, skeleton[1].InverseBind)
+	}
+	if !skeleton[0].Pose.Position.ApproxEqual(mgl32.Vec3{0, -2, 0}) {
+		t.Errorf("unexpected hip pose: %v", skeleton[0].Pose.Position)
+	}
+
+	// Joints and weights must come together, and joints must be integers.
+	p := gltfPrimitive{Attributes: map[string]int{"POSITION": 0, "JOINTS_0": 1}}
+	if _, _, err := doc.primitiveSkin(&p, 3); err != ErrGLTFInvalid {
+		t.Errorf("expected ErrGLTFInvalid without weights, got: %v", err)
+	}
+	p.Attributes = map[string]int{"POSITION": 0, "JOINTS_0": 2, "WEIGHTS_0": 2}
+	if _, _, err := doc.primitiveSkin(&p, 3); err != ErrGLTFInvalid {
+		t.Errorf("expected ErrGLTFInvalid for float joints, got: %v", err)
+	}
+
+	doc.Skins[0].Joints = []int{2, 2}
+	if _, _, err := doc.skinJoints(0, nil); err != ErrGLTFInvalid {
+		t.Errorf("expected ErrGLTFInvalid for duplicate joints, got: %v", err)
+	}
+}
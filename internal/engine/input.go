@@ -0,0 +1,671 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package engine
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/go-gl/glfw/v3.2/glfw"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/spf13/viper"
+)
+
+var _ System = &Input{}
+
+const SysNameInput = "input"
+
+const (
+	ErrInputAction  = Error("input action not found")
+	ErrInputBinding = Error("input binding index out of range")
+)
+
+// Action is a named input, such as "Jump" or "Zoom", which is driven by its
+// bindings. Digital controls give a value of 1 while held, analog controls
+// their position past the dead zone.
+type Action struct {
+	name     string
+	bindings []Binding
+	value    mgl32.Vec2
+	down     bool
+	wasDown  bool
+}
+
+// Name returns the name of the action.
+func (a *Action) Name() string {
+	return a.name
+}
+
+// Bindings returns the bindings of the action.
+func (a *Action) Bindings() []Binding {
+	return a.bindings
+}
+
+// Down reports if any binding of the action is active.
+func (a *Action) Down() bool {
+	return a.down
+}
+
+// Pressed reports if the action became active this frame.
+func (a *Action) Pressed() bool {
+	return a.down && !a.wasDown
+}
+
+// Released reports if the action became inactive this frame.
+func (a *Action) Released() bool {
+	return !a.down && a.wasDown
+}
+
+// Value returns the value of the action along the X axis.
+func (a *Action) Value() float32 {
+	return a.value[0]
+}
+
+// Vector returns the 2D value of the action, clamped to unit length.
+func (a *Action) Vector() mgl32.Vec2 {
+	if l := a.value.Len(); l > 1 {
+		return a.value.Mul(1 / l)
+	}
+
+	return a.value
+}
+
+// inputEvent is a queued change of a control, or a typed character. Value
+// is 1 for a press and 0 for a release of a digital control.
+type inputEvent struct {
+	control Binding
+	value   float32
+	char    rune
+	repeat  bool
+}
+
+// TextEvent is a character typed, or an editing key pressed or repeated,
+// during the frame. Char is zero for key events.
+type TextEvent struct {
+	Char rune
+	Key  glfw.Key
+	Mods glfw.ModifierKey
+}
+
+// Clipboard is a source of clipboard text.
+type Clipboard interface {
+	// ClipboardString returns the text on the clipboard.
+	ClipboardString() string
+
+	// SetClipboardString puts text on the clipboard.
+	SetClipboardString(s string)
+}
+
+// memoryClipboard is a Clipboard which is private to the process.
+type memoryClipboard struct {
+	text string
+}
+
+func (c *memoryClipboard) ClipboardString() string {
+	return c.text
+}
+
+func (c *memoryClipboard) SetClipboardString(s string) {
+	c.text = s
+}
+
+// Input implements an input system, which maps controls to named actions.
+// Keys and mouse controls are fed by the window, or injected directly, and
+// joysticks are polled from the input source. They are applied to the
+// actions on Update.
+type Input struct {
+	actions      map[string]*Action
+	events       []inputEvent
+	keys         map[glfw.Key]bool
+	buttons      map[glfw.MouseButton]bool
+	gamepads     map[glfw.Joystick]*Gamepad
+	mappings     map[string]GamepadMapping
+	connected    []*Gamepad
+	disconnected []glfw.Joystick
+	source       InputSource
+	clipboard    Clipboard
+	text         []TextEvent
+	tapped       []Binding
+	last         []Binding
+	scroll       mgl32.Vec2
+	wheel        mgl32.Vec2
+	pointer      mgl32.Vec2
+	cursor       mgl32.Vec2
+}
+
+// Setup sets up the System. Bindings are read from the configuration key
+// input.actions, and then from the binding file named by input.bindings.
+func (i *Input) Setup() error {
+	if a := CurrentApp(); a != nil {
+		if w, err := a.System(SysNameWindow); err == nil {
+			if !w.(*Window).Null() {
+				i.source = &glfwInputSource{}
+			}
+			i.clipboard = w.(*Window)
+		}
+	}
+
+	var actions []actionConfig
+
+	if err := viper.UnmarshalKey("input.actions", &actions); err != nil {
+		return err
+	}
+	if err := i.loadActions(actions); err != nil {
+		return err
+	}
+
+	if viper.IsSet("input.bindings") {
+		return i.LoadBindingFile(viper.GetString("input.bindings"))
+	}
+
+	return nil
+}
+
+// Teardown tears down the System.
+func (i *Input) Teardown() {}
+
+// Name returns the name of the System.
+func (i *Input) Name() string {
+	return SysNameInput
+}
+
+// DefineAction defines an action with default bindings. If the action is
+// already defined, for example by a binding file, it is left unchanged.
+func (i *Input) DefineAction(name string, defaults ...Binding) *Action {
+	if a, ok := i.actions[name]; ok {
+		return a
+	}
+
+	return i.SetBindings(name, defaults...)
+}
+
+// SetBindings replaces the bindings of an action, defining it if needed.
+func (i *Input) SetBindings(name string, bindings ...Binding) *Action {
+	a, ok := i.actions[name]
+	if !ok {
+		a = &Action{name: name}
+		i.actions[name] = a
+	}
+
+	a.bindings = append([]Binding(nil), bindings...)
+
+	return a
+}
+
+// AddBinding adds a binding to an action, defining it if needed.
+func (i *Input) AddBinding(name string, b Binding) *Action {
+	a := i.DefineAction(name)
+	a.bindings = append(a.bindings, b)
+
+	return a
+}
+
+// Rebind replaces the binding at index of an action.
+func (i *Input) Rebind(name string, index int, b Binding) error {
+	a, ok := i.actions[name]
+	if !ok {
+		return ErrInputAction
+	}
+	if index < 0 || index >= len(a.bindings) {
+		return ErrInputBinding
+	}
+
+	a.bindings[index] = b
+
+	return nil
+}
+
+// RemoveAction removes an action.
+func (i *Input) RemoveAction(name string) {
+	delete(i.actions, name)
+}
+
+// Action returns the action with the given name, or nil if there is none.
+func (i *Input) Action(name string) *Action {
+	return i.actions[name]
+}
+
+// Actions returns the names of the defined actions, sorted.
+func (i *Input) Actions() []string {
+	names := make([]string, 0, len(i.actions))
+	for name := range i.actions {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// Down reports if the named action is active.
+func (i *Input) Down(name string) bool {
+	if a := i.actions[name]; a != nil {
+		return a.Down()
+	}
+
+	return false
+}
+
+// Pressed reports if the named action became active this frame.
+func (i *Input) Pressed(name string) bool {
+	if a := i.actions[name]; a != nil {
+		return a.Pressed()
+	}
+
+	return false
+}
+
+// Released reports if the named action became inactive this frame.
+func (i *Input) Released(name string) bool {
+	if a := i.actions[name]; a != nil {
+		return a.Released()
+	}
+
+	return false
+}
+
+// Value returns the value of the named action along the X axis.
+func (i *Input) Value(name string) float32 {
+	if a := i.actions[name]; a != nil {
+		return a.Value()
+	}
+
+	return 0
+}
+
+// Vector returns the 2D value of the named action.
+func (i *Input) Vector(name string) mgl32.Vec2 {
+	if a := i.actions[name]; a != nil {
+		return a.Vector()
+	}
+
+	return mgl32.Vec2{}
+}
+
+// TextEvents returns the characters typed and editing keys pressed this
+// frame, in order.
+func (i *Input) TextEvents() []TextEvent {
+	return i.text
+}
+
+// Text returns the characters typed this frame.
+func (i *Input) Text() string {
+	var b strings.Builder
+
+	for _, e := range i.text {
+		if e.Char != 0 {
+			b.WriteRune(e.Char)
+		}
+	}
+
+	return b.String()
+}
+
+// SetClipboard sets the clipboard used by the input system.
+func (i *Input) SetClipboard(c Clipboard) {
+	i.clipboard = c
+}
+
+// Clipboard returns the clipboard used by the input system.
+func (i *Input) Clipboard() Clipboard {
+	return i.clipboard
+}
+
+// ClipboardText returns the text on the clipboard.
+func (i *Input) ClipboardText() string {
+	return i.clipboard.ClipboardString()
+}
+
+// SetClipboardText puts text on the clipboard.
+func (i *Input) SetClipboardText(s string) {
+	i.clipboard.SetClipboardString(s)
+}
+
+// LastControl returns a digital control pressed this frame, for rebinding
+// an action to whatever the user presses next.
+func (i *Input) LastControl() (Binding, bool) {
+	if len(i.last) == 0 {
+		return Binding{}, false
+	}
+
+	return i.last[len(i.last)-1], true
+}
+
+// InjectKey queues a key event. Repeats only reach the text events.
+func (i *Input) InjectKey(key glfw.Key, action glfw.Action) {
+	switch action {
+	case glfw.Press:
+		i.events = append(i.events, inputEvent{control: KeyBinding(key), value: 1})
+	case glfw.Repeat:
+		i.events = append(i.events, inputEvent{control: KeyBinding(key), value: 1, repeat: true})
+	case glfw.Release:
+		i.events = append(i.events, inputEvent{control: KeyBinding(key), value: 0})
+	}
+}
+
+// InjectChar queues a typed character.
+func (i *Input) InjectChar(char rune) {
+	i.events = append(i.events, inputEvent{char: char})
+}
+
+// InjectMouseButton queues a mouse button event.
+func (i *Input) InjectMouseButton(button glfw.MouseButton, action glfw.Action) {
+	switch action {
+	case glfw.Press:
+		i.events = append(i.events, inputEvent{control: MouseButtonBinding(button), value: 1})
+	case glfw.Release:
+		i.events = append(i.events, inputEvent{control: MouseButtonBinding(button), value: 0})
+	}
+}
+
+// InjectScroll queues a scroll of the mouse wheel.
+func (i *Input) InjectScroll(x, y float32) {
+	i.scroll = i.scroll.Add(mgl32.Vec2{x, y})
+}
+
+// InjectCursor queues a move of the mouse cursor to the position, in window
+// coordinates.
+func (i *Input) InjectCursor(x, y float32) {
+	i.pointer = mgl32.Vec2{x, y}
+}
+
+// Cursor returns the position of the mouse cursor in window coordinates, as
+// of the last update.
+func (i *Input) Cursor() mgl32.Vec2 {
+	return i.cursor
+}
+
+// SetSource sets the source of joystick state. A nil source disconnects all
+// gamepads on the next update.
+func (i *Input) SetSource(source InputSource) {
+	i.source = source
+}
+
+// Source returns the source of joystick state.
+func (i *Input) Source() InputSource {
+	return i.source
+}
+
+// SetGamepadMapping sets the standard layout mapping of joysticks with the
+// given name. It applies to joysticks connected afterwards.
+func (i *Input) SetGamepadMapping(name string, m GamepadMapping) {
+	i.mappings[name] = m
+}
+
+// Gamepad returns the gamepad of the joystick, or nil if it is not
+// connected.
+func (i *Input) Gamepad(joy glfw.Joystick) *Gamepad {
+	return i.gamepads[joy]
+}
+
+// Gamepads returns the connected gamepads, ordered by joystick.
+func (i *Input) Gamepads() []*Gamepad {
+	gamepads := make([]*Gamepad, 0, len(i.gamepads))
+	for joy := glfw.Joystick1; joy <= glfw.JoystickLast; joy++ {
+		if g, ok := i.gamepads[joy]; ok {
+			gamepads = append(gamepads, g)
+		}
+	}
+
+	return gamepads
+}
+
+// Connected returns the gamepads which were connected this frame.
+func (i *Input) Connected() []*Gamepad {
+	return i.connected
+}
+
+// Disconnected returns the joysticks which were disconnected this frame.
+func (i *Input) Disconnected() []glfw.Joystick {
+	return i.disconnected
+}
+
+// Update applies the queued events and updates the actions. Controls which
+// were pressed and released within the frame count as held for the frame.
+func (i *Input) Update() {
+	i.tapped = i.tapped[:0]
+	i.last = i.last[:0]
+	i.text = i.text[:0]
+
+	i.pollGamepads()
+
+	for _, e := range i.events {
+		c := e.control
+
+		if e.char != 0 {
+			i.text = append(i.text, TextEvent{Char: e.char, Mods: i.mods()})
+			continue
+		}
+
+		switch c.Kind {
+		case BindingKey:
+			if e.value != 0 {
+				i.text = append(i.text, TextEvent{Key: c.Key, Mods: i.mods()})
+			}
+			if e.repeat {
+				continue
+			}
+			if e.value != 0 && !i.keys[c.Key] {
+				i.tapped = append(i.tapped, c)
+				i.last = append(i.last, c)
+			}
+			i.keys[c.Key] = e.value != 0
+		case BindingMouseButton:
+			if e.value != 0 && !i.buttons[c.Button] {
+				i.tapped = append(i.tapped, c)
+				i.last = append(i.last, c)
+			}
+			i.buttons[c.Button] = e.value != 0
+		}
+	}
+
+	i.events = i.events[:0]
+	i.wheel = i.scroll
+	i.cursor = i.pointer
+	i.scroll = mgl32.Vec2{}
+
+	mods := i.mods()
+
+	for _, a := range i.actions {
+		a.wasDown = a.down
+		a.down = false
+		a.value = mgl32.Vec2{}
+
+		for _, b := range a.bindings {
+			if !b.matchMods(mods) {
+				continue
+			}
+
+			v := i.controlValue(b)
+			if v == 0 {
+				continue
+			}
+
+			a.down = true
+			a.value = a.value.Add(b.direction().Mul(v * b.scale()))
+		}
+	}
+}
+
+// controlValue returns the current value of the control of a binding, with
+// the dead zone applied.
+func (i *Input) controlValue(b Binding) float32 {
+	switch b.Kind {
+	case BindingKey, BindingMouseButton:
+		if i.held(b) {
+			return 1
+		}
+	case BindingMouseWheel:
+		if b.Axis >= 0 && b.Axis < 2 {
+			return applyDeadZone(i.wheel[b.Axis], b.DeadZone)
+		}
+	case BindingJoystickAxis:
+		if g := i.gamepads[b.Joystick]; g != nil {
+			return applyDeadZone(g.RawAxis(b.Axis), b.DeadZone)
+		}
+	case BindingGamepadAxis:
+		if g := i.gamepads[b.Joystick]; g != nil {
+			return applyDeadZone(g.Axis(b.GamepadAxis), b.DeadZone)
+		}
+	case BindingGamepadButton:
+		if g := i.gamepads[b.Joystick]; g != nil && g.ButtonHeld(b.GamepadButton) {
+			return 1
+		}
+	}
+
+	return 0
+}
+
+// held reports if a digital control is held, or was tapped this frame.
+func (i *Input) held(b Binding) bool {
+	if b.Kind == BindingKey && i.keys[b.Key] {
+		return true
+	}
+	if b.Kind == BindingMouseButton && i.buttons[b.Button] {
+		return true
+	}
+
+	for _, c := range i.tapped {
+		if c.Kind == b.Kind && c.Key == b.Key && c.Button == b.Button {
+			return true
+		}
+	}
+
+	return false
+}
+
+// mods returns the modifier keys which are held.
+func (i *Input) mods() glfw.ModifierKey {
+	var mods glfw.ModifierKey
+
+	if i.keys[glfw.KeyLeftShift] || i.keys[glfw.KeyRightShift] {
+		mods |= glfw.ModShift
+	}
+	if i.keys[glfw.KeyLeftControl] || i.keys[glfw.KeyRightControl] {
+		mods |= glfw.ModControl
+	}
+	if i.keys[glfw.KeyLeftAlt] || i.keys[glfw.KeyRightAlt] {
+		mods |= glfw.ModAlt
+	}
+	if i.keys[glfw.KeyLeftSuper] || i.keys[glfw.KeyRightSuper] {
+		mods |= glfw.ModSuper
+	}
+
+	return mods
+}
+
+// pollGamepads polls the joysticks of the source, and tracks which are
+// connected and disconnected.
+func (i *Input) pollGamepads() {
+	i.connected = i.connected[:0]
+	i.disconnected = i.disconnected[:0]
+
+	for joy := glfw.Joystick1; joy <= glfw.JoystickLast; joy++ {
+		g, ok := i.gamepads[joy]
+		present := i.source != nil && i.source.JoystickPresent(joy)
+
+		if !present {
+			if ok {
+				delete(i.gamepads, joy)
+				i.disconnected = append(i.disconnected, joy)
+
+				if e := events(); e != nil {
+					e.Queue(ScopeGlobal(), GamepadDisconnectedEvent{Joystick: joy})
+				}
+			}
+			continue
+		}
+
+		if !ok {
+			g = &Gamepad{joystick: joy, name: i.source.JoystickName(joy)}
+			if m, ok := i.mappings[g.name]; ok {
+				g.mapping = m
+			} else {
+				g.mapping = DefaultGamepadMapping
+			}
+
+			i.gamepads[joy] = g
+			i.connected = append(i.connected, g)
+
+			if e := events(); e != nil {
+				e.Queue(ScopeGlobal(), GamepadConnectedEvent{Joystick: joy, Name: g.name})
+			}
+		}
+
+		g.poll(i.source)
+
+		for b := GamepadButton(0); b < GamepadButtonCount; b++ {
+			if g.ButtonDown(b) {
+				i.last = append(i.last, GamepadButtonBinding(joy, b))
+			}
+		}
+	}
+}
+
+// applyDeadZone zeroes values within the dead zone, and rescales the rest
+// to start from zero at its edge.
+func applyDeadZone(v, deadZone float32) float32 {
+	if deadZone <= 0 {
+		return v
+	}
+
+	a := mgl32.Abs(v)
+	if a <= deadZone || deadZone >= 1 {
+		return 0
+	}
+
+	s := (a - deadZone) / (1 - deadZone)
+	if v < 0 {
+		return -s
+	}
+
+	return s
+}
+
+// NewInput creates a new input system.
+func NewInput() *Input {
+	return &Input{
+		actions:   make(map[string]*Action),
+		keys:      make(map[glfw.Key]bool),
+		buttons:   make(map[glfw.MouseButton]bool),
+		gamepads:  make(map[glfw.Joystick]*Gamepad),
+		mappings:  make(map[string]GamepadMapping),
+		clipboard: &memoryClipboard{},
+	}
+}
+
+// GetInput gets the input system from the current app.
+func GetInput() *Input {
+	return CurrentApp().MustSystem(SysNameInput).(*Input)
+}
+
+// input returns the input system of the current app, or nil if there is
+// none.
+func input() *Input {
+	if a := CurrentApp(); a != nil {
+		if s, err := a.System(SysNameInput); err == nil {
+			return s.(*Input)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,461 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package engine
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/go-gl/glfw/v3.2/glfw"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/spf13/viper"
+)
+
+const (
+	ErrInputControl   = Error("unknown input control")
+	ErrInputDirection = Error("unknown input direction")
+)
+
+// BindingKind is the kind of control of a Binding.
+type BindingKind int
+
+const (
+	BindingKey BindingKind = iota
+	BindingMouseButton
+	BindingMouseWheel
+	BindingJoystickAxis
+	BindingGamepadButton
+	BindingGamepadAxis
+)
+
+// Binding binds a control to an action. The value of the control is
+// multiplied by Scale and added to the action along Direction. A zero Scale
+// is treated as 1, and a zero Direction as the X axis. The binding is only
+// active while at least the modifier keys in Mods are held, or exactly those
+// if ExactMods is set. The modifier of a bound modifier key is not counted.
+type Binding struct {
+	Kind          BindingKind
+	Key           glfw.Key
+	Button        glfw.MouseButton
+	Joystick      glfw.Joystick
+	Axis          int
+	GamepadButton GamepadButton
+	GamepadAxis   GamepadAxis
+	Mods          glfw.ModifierKey
+	ExactMods     bool
+	Direction     mgl32.Vec2
+	Scale         float32
+	DeadZone      float32
+}
+
+// KeyBinding creates a binding to a key.
+func KeyBinding(key glfw.Key) Binding {
+	return Binding{Kind: BindingKey, Key: key}
+}
+
+// MouseButtonBinding creates a binding to a mouse button.
+func MouseButtonBinding(button glfw.MouseButton) Binding {
+	return Binding{Kind: BindingMouseButton, Button: button}
+}
+
+// MouseWheelBinding creates a binding to an axis of the mouse wheel, 0 for
+// horizontal and 1 for vertical scrolling.
+func MouseWheelBinding(axis int) Binding {
+	return Binding{Kind: BindingMouseWheel, Axis: axis}
+}
+
+// JoystickAxisBinding creates a binding to an axis of a joystick.
+func JoystickAxisBinding(joy glfw.Joystick, axis int) Binding {
+	return Binding{Kind: BindingJoystickAxis, Joystick: joy, Axis: axis}
+}
+
+// GamepadButtonBinding creates a binding to a button of the standard gamepad
+// layout.
+func GamepadButtonBinding(joy glfw.Joystick, button GamepadButton) Binding {
+	return Binding{Kind: BindingGamepadButton, Joystick: joy, GamepadButton: button}
+}
+
+// GamepadAxisBinding creates a binding to an axis of the standard gamepad
+// layout.
+func GamepadAxisBinding(joy glfw.Joystick, axis GamepadAxis) Binding {
+	return Binding{Kind: BindingGamepadAxis, Joystick: joy, GamepadAxis: axis}
+}
+
+// Composite2D creates the bindings of a 2D axis from four directional
+// controls, such as the WASD keys.
+func Composite2D(up, down, left, right Binding) []Binding {
+	return []Binding{
+		up.WithDirection(mgl32.Vec2{0, 1}),
+		down.WithDirection(mgl32.Vec2{0, -1}),
+		left.WithDirection(mgl32.Vec2{-1, 0}),
+		right.WithDirection(mgl32.Vec2{1, 0}),
+	}
+}
+
+// WithMods returns a copy of the binding which requires the modifier keys.
+func (b Binding) WithMods(mods glfw.ModifierKey) Binding {
+	b.Mods = mods
+	return b
+}
+
+// WithExactMods returns a copy of the binding which is inactive while other
+// modifier keys than its own are held.
+func (b Binding) WithExactMods() Binding {
+	b.ExactMods = true
+	return b
+}
+
+// WithDirection returns a copy of the binding with the direction.
+func (b Binding) WithDirection(d mgl32.Vec2) Binding {
+	b.Direction = d
+	return b
+}
+
+// WithScale returns a copy of the binding with the scale.
+func (b Binding) WithScale(s float32) Binding {
+	b.Scale = s
+	return b
+}
+
+// WithDeadZone returns a copy of the binding with the dead zone.
+func (b Binding) WithDeadZone(d float32) Binding {
+	b.DeadZone = d
+	return b
+}
+
+// matchMods reports if the held modifier keys activate the binding. The
+// modifier of a bound modifier key is held by the key itself, so it is
+// ignored. Joystick and gamepad controls are not combined with modifier keys,
+// so only the modifiers of the binding are required for them.
+func (b Binding) matchMods(mods glfw.ModifierKey) bool {
+	var own glfw.ModifierKey
+	if b.Kind == BindingKey {
+		own = keyMod(b.Key)
+	}
+
+	mods &^= own
+	want := b.Mods &^ own
+
+	switch b.Kind {
+	case BindingKey, BindingMouseButton, BindingMouseWheel:
+		if b.ExactMods {
+			return mods == want
+		}
+	}
+
+	return mods&want == want
+}
+
+// keyMod returns the modifier held by a key, or zero if it is not a
+// modifier key.
+func keyMod(key glfw.Key) glfw.ModifierKey {
+	switch key {
+	case glfw.KeyLeftShift, glfw.KeyRightShift:
+		return glfw.ModShift
+	case glfw.KeyLeftControl, glfw.KeyRightControl:
+		return glfw.ModControl
+	case glfw.KeyLeftAlt, glfw.KeyRightAlt:
+		return glfw.ModAlt
+	case glfw.KeyLeftSuper, glfw.KeyRightSuper:
+		return glfw.ModSuper
+	}
+
+	return 0
+}
+
+func (b Binding) direction() mgl32.Vec2 {
+	if b.Direction == (mgl32.Vec2{}) {
+		return mgl32.Vec2{1, 0}
+	}
+
+	return b.Direction
+}
+
+func (b Binding) scale() float32 {
+	if b.Scale == 0 {
+		return 1
+	}
+
+	return b.Scale
+}
+
+// actionConfig describes an action in a binding file. Actions are listed
+// rather than keyed by name, since viper folds the case of keys.
+type actionConfig struct {
+	Name     string          `mapstructure:"name"`
+	Bindings []bindingConfig `mapstructure:"bindings"`
+}
+
+// bindingConfig describes a binding in a binding file. Exactly one of Key,
+// Mouse, Wheel, Axis, Gamepad or GamepadAxis names the control.
+type bindingConfig struct {
+	Key         string   `mapstructure:"key"`
+	Mouse       string   `mapstructure:"mouse"`
+	Wheel       string   `mapstructure:"wheel"`
+	Joystick    int      `mapstructure:"joystick"`
+	Axis        *int     `mapstructure:"axis"`
+	Gamepad     string   `mapstructure:"gamepad"`
+	GamepadAxis string   `mapstructure:"gamepad_axis"`
+	Mods        []string `mapstructure:"mods"`
+	ExactMods   bool     `mapstructure:"exactmods"`
+	Direction   string   `mapstructure:"direction"`
+	Scale       float32  `mapstructure:"scale"`
+	DeadZone    float32  `mapstructure:"deadzone"`
+}
+
+func (c bindingConfig) binding() (b Binding, err error) {
+	switch {
+	case c.Key != "":
+		key, ok := keyNames[strings.ToLower(c.Key)]
+		if !ok {
+			return b, fmt.Errorf("key %s: %v", c.Key, ErrInputControl)
+		}
+		b = KeyBinding(key)
+	case c.Mouse != "":
+		button, ok := mouseButtonNames[strings.ToLower(c.Mouse)]
+		if !ok {
+			return b, fmt.Errorf("mouse %s: %v", c.Mouse, ErrInputControl)
+		}
+		b = MouseButtonBinding(button)
+	case c.Wheel != "":
+		switch strings.ToLower(c.Wheel) {
+		case "x":
+			b = MouseWheelBinding(0)
+		case "y":
+			b = MouseWheelBinding(1)
+		default:
+			return b, fmt.Errorf("wheel %s: %v", c.Wheel, ErrInputControl)
+		}
+	case c.Axis != nil:
+		b = JoystickAxisBinding(glfw.Joystick(c.Joystick), *c.Axis)
+	case c.Gamepad != "":
+		button, ok := gamepadButtonNames[strings.ToLower(c.Gamepad)]
+		if !ok {
+			return b, fmt.Errorf("gamepad %s: %v", c.Gamepad, ErrInputControl)
+		}
+		b = GamepadButtonBinding(glfw.Joystick(c.Joystick), button)
+	case c.GamepadAxis != "":
+		axis, ok := gamepadAxisNames[strings.ToLower(c.GamepadAxis)]
+		if !ok {
+			return b, fmt.Errorf("gamepad axis %s: %v", c.GamepadAxis, ErrInputControl)
+		}
+		b = GamepadAxisBinding(glfw.Joystick(c.Joystick), axis)
+	default:
+		return b, ErrInputControl
+	}
+
+	for _, m := range c.Mods {
+		mod, ok := modNames[strings.ToLower(m)]
+		if !ok {
+			return b, fmt.Errorf("mod %s: %v", m, ErrInputControl)
+		}
+		b.Mods |= mod
+	}
+
+	if c.Direction != "" {
+		d, ok := directionNames[strings.ToLower(c.Direction)]
+		if !ok {
+			return b, fmt.Errorf("direction %s: %v", c.Direction, ErrInputDirection)
+		}
+		b.Direction = d
+	}
+
+	b.ExactMods = c.ExactMods
+	b.Scale = c.Scale
+	b.DeadZone = c.DeadZone
+
+	return b, nil
+}
+
+// LoadBindings reads a JSON binding file and replaces the bindings of the
+// actions in it. Other actions are left unchanged.
+func (i *Input) LoadBindings(r io.Reader) error {
+	v := viper.New()
+	v.SetConfigType("json")
+
+	if err := v.ReadConfig(r); err != nil {
+		return err
+	}
+
+	return i.loadConfig(v)
+}
+
+// LoadBindingFile reads a JSON binding file from disk.
+func (i *Input) LoadBindingFile(filename string) error {
+	v := viper.New()
+	v.SetConfigFile(filename)
+	v.SetConfigType("json")
+
+	if err := v.ReadInConfig(); err != nil {
+		return err
+	}
+
+	return i.loadConfig(v)
+}
+
+func (i *Input) loadConfig(v *viper.Viper) error {
+	var actions []actionConfig
+
+	if err := v.UnmarshalKey("actions", &actions); err != nil {
+		return err
+	}
+
+	return i.loadActions(actions)
+}
+
+func (i *Input) loadActions(actions []actionConfig) error {
+	for _, a := range actions {
+		bindings := make([]Binding, 0, len(a.Bindings))
+
+		for _, c := range a.Bindings {
+			b, err := c.binding()
+			if err != nil {
+				return fmt.Errorf("input: action %s: %v", a.Name, err)
+			}
+			bindings = append(bindings, b)
+		}
+
+		i.SetBindings(a.Name, bindings...)
+	}
+
+	return nil
+}
+
+var directionNames = map[string]mgl32.Vec2{
+	"x":     {1, 0},
+	"y":     {0, 1},
+	"up":    {0, 1},
+	"down":  {0, -1},
+	"left":  {-1, 0},
+	"right": {1, 0},
+}
+
+var modNames = map[string]glfw.ModifierKey{
+	"shift":   glfw.ModShift,
+	"control": glfw.ModControl,
+	"ctrl":    glfw.ModControl,
+	"alt":     glfw.ModAlt,
+	"super":   glfw.ModSuper,
+}
+
+var mouseButtonNames = map[string]glfw.MouseButton{
+	"left":   glfw.MouseButtonLeft,
+	"right":  glfw.MouseButtonRight,
+	"middle": glfw.MouseButtonMiddle,
+}
+
+var gamepadButtonNames = map[string]GamepadButton{
+	"a":            GamepadA,
+	"b":            GamepadB,
+	"x":            GamepadX,
+	"y":            GamepadY,
+	"left_bumper":  GamepadLeftBumper,
+	"right_bumper": GamepadRightBumper,
+	"back":         GamepadBack,
+	"start":        GamepadStart,
+	"guide":        GamepadGuide,
+	"left_thumb":   GamepadLeftThumb,
+	"right_thumb":  GamepadRightThumb,
+	"dpad_up":      GamepadDpadUp,
+	"dpad_right":   GamepadDpadRight,
+	"dpad_down":    GamepadDpadDown,
+	"dpad_left":    GamepadDpadLeft,
+}
+
+var gamepadAxisNames = map[string]GamepadAxis{
+	"left_x":        GamepadLeftX,
+	"left_y":        GamepadLeftY,
+	"right_x":       GamepadRightX,
+	"right_y":       GamepadRightY,
+	"left_trigger":  GamepadLeftTrigger,
+	"right_trigger": GamepadRightTrigger,
+}
+
+var keyNames = map[string]glfw.Key{
+	"space":         glfw.KeySpace,
+	"apostrophe":    glfw.KeyApostrophe,
+	"comma":         glfw.KeyComma,
+	"minus":         glfw.KeyMinus,
+	"period":        glfw.KeyPeriod,
+	"slash":         glfw.KeySlash,
+	"semicolon":     glfw.KeySemicolon,
+	"equal":         glfw.KeyEqual,
+	"left_bracket":  glfw.KeyLeftBracket,
+	"backslash":     glfw.KeyBackslash,
+	"right_bracket": glfw.KeyRightBracket,
+	"grave_accent":  glfw.KeyGraveAccent,
+	"escape":        glfw.KeyEscape,
+	"enter":         glfw.KeyEnter,
+	"tab":           glfw.KeyTab,
+	"backspace":     glfw.KeyBackspace,
+	"insert":        glfw.KeyInsert,
+	"delete":        glfw.KeyDelete,
+	"right":         glfw.KeyRight,
+	"left":          glfw.KeyLeft,
+	"down":          glfw.KeyDown,
+	"up":            glfw.KeyUp,
+	"page_up":       glfw.KeyPageUp,
+	"page_down":     glfw.KeyPageDown,
+	"home":          glfw.KeyHome,
+	"end":           glfw.KeyEnd,
+	"caps_lock":     glfw.KeyCapsLock,
+	"scroll_lock":   glfw.KeyScrollLock,
+	"num_lock":      glfw.KeyNumLock,
+	"print_screen":  glfw.KeyPrintScreen,
+	"pause":         glfw.KeyPause,
+	"kp_decimal":    glfw.KeyKPDecimal,
+	"kp_divide":     glfw.KeyKPDivide,
+	"kp_multiply":   glfw.KeyKPMultiply,
+	"kp_subtract":   glfw.KeyKPSubtract,
+	"kp_add":        glfw.KeyKPAdd,
+	"kp_enter":      glfw.KeyKPEnter,
+	"kp_equal":      glfw.KeyKPEqual,
+	"left_shift":    glfw.KeyLeftShift,
+	"left_control":  glfw.KeyLeftControl,
+	"left_alt":      glfw.KeyLeftAlt,
+	"left_super":    glfw.KeyLeftSuper,
+	"right_shift":   glfw.KeyRightShift,
+	"right_control": glfw.KeyRightControl,
+	"right_alt":     glfw.KeyRightAlt,
+	"right_super":   glfw.KeyRightSuper,
+	"menu":          glfw.KeyMenu,
+}
+
+func init() {
+	for k := glfw.KeyA; k <= glfw.KeyZ; k++ {
+		keyNames[string(rune('a'+k-glfw.KeyA))] = k
+	}
+	for k := glfw.Key0; k <= glfw.Key9; k++ {
+		keyNames[string(rune('0'+k-glfw.Key0))] = k
+	}
+	for k := glfw.KeyKP0; k <= glfw.KeyKP9; k++ {
+		keyNames["kp_"+strconv.Itoa(int(k-glfw.KeyKP0))] = k
+	}
+	for k := glfw.KeyF1; k <= glfw.KeyF25; k++ {
+		keyNames["f"+strconv.Itoa(int(k-glfw.KeyF1)+1)] = k
+	}
+	for b := glfw.MouseButton1; b <= glfw.MouseButtonLast; b++ {
+		mouseButtonNames["button"+strconv.Itoa(int(b-glfw.MouseButton1)+1)] = b
+	}
+}
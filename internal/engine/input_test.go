@@ -0,0 +1,349 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package engine
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-gl/glfw/v3.2/glfw"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestInput_Action(t *testing.T) {
+	in := NewInput()
+	in.DefineAction("Jump", KeyBinding(glfw.KeySpace), MouseButtonBinding(glfw.MouseButtonLeft))
+
+	in.InjectKey(glfw.KeySpace, glfw.Press)
+	in.Update()
+
+	if !in.Pressed("Jump") || !in.Down("Jump") {
+		t.Error("Jump expected pressed and down")
+	}
+
+	in.InjectKey(glfw.KeySpace, glfw.Repeat)
+	in.Update()
+
+	if in.Pressed("Jump") || !in.Down("Jump") {
+		t.Error("Jump expected held")
+	}
+
+	in.InjectKey(glfw.KeySpace, glfw.Release)
+	in.Update()
+
+	if !in.Released("Jump") || in.Down("Jump") {
+		t.Error("Jump expected released")
+	}
+
+	// A press and release within one frame still counts for the frame.
+	in.InjectMouseButton(glfw.MouseButtonLeft, glfw.Press)
+	in.InjectMouseButton(glfw.MouseButtonLeft, glfw.Release)
+	in.Update()
+
+	if !in.Pressed("Jump") {
+		t.Error("Jump expected pressed by a tap")
+	}
+
+	in.Update()
+
+	if !in.Released("Jump") {
+		t.Error("Jump expected released after a tap")
+	}
+}
+
+func TestInput_Composite2D(t *testing.T) {
+	in := NewInput()
+	in.DefineAction("Move", Composite2D(
+		KeyBinding(glfw.KeyW),
+		KeyBinding(glfw.KeyS),
+		KeyBinding(glfw.KeyA),
+		KeyBinding(glfw.KeyD),
+	)...)
+
+	in.InjectKey(glfw.KeyW, glfw.Press)
+	in.InjectKey(glfw.KeyD, glfw.Press)
+	in.Update()
+
+	v := in.Vector("Move")
+	if !nearFloat(v.Len(), 1) || !nearFloat(v[0], v[1]) || v[0] <= 0 {
+		t.Error("Vector(Move) expected unit diagonal, got:", v)
+	}
+
+	in.InjectKey(glfw.KeyA, glfw.Press)
+	in.Update()
+
+	if v := in.Vector("Move"); v != (mgl32.Vec2{0, 1}) {
+		t.Error("Vector(Move) expected {0 1}, got:", v)
+	}
+}
+
+func TestInput_ModsAndDeadZone(t *testing.T) {
+	pad := newFakeInputSource()
+	pad.connect(glfw.Joystick1, "pad")
+
+	in := NewInput()
+	in.SetSource(pad)
+	in.DefineAction("Save", KeyBinding(glfw.KeyS).WithMods(glfw.ModControl))
+	in.DefineAction("Step", KeyBinding(glfw.KeyS))
+	in.DefineAction("Walk", KeyBinding(glfw.KeyS).WithExactMods())
+	in.DefineAction("Look", JoystickAxisBinding(glfw.Joystick1, 2).WithDeadZone(0.2))
+	in.DefineAction("Zoom", MouseWheelBinding(1).WithScale(-2))
+
+	in.InjectKey(glfw.KeyS, glfw.Press)
+	pad.axes[glfw.Joystick1][2] = 0.1
+	in.InjectScroll(0, 1)
+	in.Update()
+
+	if in.Down("Save") {
+		t.Error("Save expected up without control")
+	}
+	if !in.Down("Step") || !in.Down("Walk") {
+		t.Error("Step and Walk expected down without modifiers")
+	}
+	if in.Down("Look") {
+		t.Error("Look expected up within dead zone")
+	}
+	if v := in.Value("Zoom"); v != -2 {
+		t.Error("Value(Zoom) expected -2, got:", v)
+	}
+
+	in.InjectKey(glfw.KeyRightControl, glfw.Press)
+	pad.axes[glfw.Joystick1][2] = -0.6
+	in.Update()
+
+	if !in.Pressed("Save") {
+		t.Error("Save expected pressed with control")
+	}
+	if !in.Down("Step") {
+		t.Error("Step expected down with control")
+	}
+	if in.Down("Walk") {
+		t.Error("Walk expected up with exact modifiers and control")
+	}
+	if v := in.Value("Look"); !nearFloat(v, -0.5) {
+		t.Error("Value(Look) expected -0.5, got:", v)
+	}
+	if in.Down("Zoom") {
+		t.Error("Zoom expected up once the wheel stops")
+	}
+}
+
+func TestInput_ModifierKeys(t *testing.T) {
+	in := NewInput()
+	in.DefineAction("Sprint", KeyBinding(glfw.KeyLeftShift))
+	in.DefineAction("Crouch", KeyBinding(glfw.KeyLeftControl).WithExactMods())
+	in.DefineAction("Forward", KeyBinding(glfw.KeyW))
+
+	in.InjectKey(glfw.KeyLeftShift, glfw.Press)
+	in.InjectKey(glfw.KeyLeftControl, glfw.Press)
+	in.InjectKey(glfw.KeyW, glfw.Press)
+	in.Update()
+
+	if !in.Down("Sprint") {
+		t.Error("Sprint expected down with its own modifier key")
+	}
+	if in.Down("Crouch") {
+		t.Error("Crouch expected up with exact modifiers and shift")
+	}
+	if !in.Down("Forward") {
+		t.Error("Forward expected down with shift and control")
+	}
+
+	in.InjectKey(glfw.KeyLeftShift, glfw.Release)
+	in.Update()
+
+	if in.Down("Sprint") {
+		t.Error("Sprint expected up once released")
+	}
+	if !in.Down("Crouch") {
+		t.Error("Crouch expected down with only its own modifier key")
+	}
+	if !in.Down("Forward") {
+		t.Error("Forward expected down with control")
+	}
+}
+
+func TestInput_LoadBindings(t *testing.T) {
+	const file = `{
+		"actions": [
+			{"name": "Jump", "bindings": [{"key": "J"}]},
+			{"name": "Move", "bindings": [
+				{"key": "up", "direction": "up"},
+				{"axis": 1, "joystick": 1, "direction": "y", "scale": -1, "deadzone": 0.1}
+			]},
+			{"name": "Quit", "bindings": [{"key": "q", "mods": ["ctrl", "shift"], "exactmods": true}]},
+			{"name": "Fire", "bindings": [{"mouse": "button4"}, {"wheel": "x"}, {"gamepad": "right_bumper", "joystick": 1}]},
+			{"name": "Throttle", "bindings": [{"gamepad_axis": "right_trigger"}]}
+		]
+	}`
+
+	in := NewInput()
+	if err := in.LoadBindings(strings.NewReader(file)); err != nil {
+		t.Fatal(err)
+	}
+
+	in.DefineAction("Jump", KeyBinding(glfw.KeySpace))
+
+	if b := in.Action("Jump").Bindings(); len(b) != 1 || b[0].Key != glfw.KeyJ {
+		t.Error("Jump expected the loaded binding, got:", b)
+	}
+
+	move := in.Action("Move").Bindings()
+	if len(move) != 2 || move[0].Key != glfw.KeyUp || move[0].Direction != (mgl32.Vec2{0, 1}) {
+		t.Error("Move expected key binding up, got:", move)
+	} else if b := move[1]; b.Kind != BindingJoystickAxis || b.Joystick != glfw.Joystick2 || b.Axis != 1 || b.Scale != -1 || b.DeadZone != 0.1 {
+		t.Error("Move expected joystick axis binding, got:", b)
+	}
+
+	if b := in.Action("Quit").Bindings(); b[0].Mods != glfw.ModControl|glfw.ModShift || !b[0].ExactMods {
+		t.Error("Quit expected control and shift mods, got:", b[0].Mods)
+	}
+	if b := in.Action("Fire").Bindings(); b[0].Button != glfw.MouseButton4 || b[1].Kind != BindingMouseWheel {
+		t.Error("Fire expected mouse button 4 and wheel, got:", b)
+	} else if b[2].Kind != BindingGamepadButton || b[2].GamepadButton != GamepadRightBumper || b[2].Joystick != glfw.Joystick2 {
+		t.Error("Fire expected gamepad right bumper, got:", b[2])
+	}
+	if b := in.Action("Throttle").Bindings(); b[0].Kind != BindingGamepadAxis || b[0].GamepadAxis != GamepadRightTrigger {
+		t.Error("Throttle expected gamepad right trigger, got:", b)
+	}
+
+	if err := in.LoadBindings(strings.NewReader(`{"actions": [{"name": "X", "bindings": [{"key": "nope"}]}]}`)); err == nil {
+		t.Error("LoadBindings() expected error for unknown key")
+	}
+}
+
+func TestInput_Rebind(t *testing.T) {
+	in := NewInput()
+	in.DefineAction("Jump", KeyBinding(glfw.KeySpace))
+
+	in.InjectKey(glfw.KeyK, glfw.Press)
+	in.Update()
+
+	b, ok := in.LastControl()
+	if !ok || b.Key != glfw.KeyK {
+		t.Fatal("LastControl() expected K, got:", b, ok)
+	}
+	if err := in.Rebind("Jump", 0, b); err != nil {
+		t.Fatal(err)
+	}
+
+	in.InjectKey(glfw.KeyK, glfw.Release)
+	in.InjectKey(glfw.KeySpace, glfw.Press)
+	in.Update()
+
+	if in.Down("Jump") {
+		t.Error("Jump expected up on the old binding")
+	}
+	if _, ok := in.LastControl(); !ok {
+		t.Error("LastControl() expected space")
+	}
+
+	if err := in.Rebind("Jump", 1, b); err != ErrInputBinding {
+		t.Error("Rebind() expected ErrInputBinding, got:", err)
+	}
+	if err := in.Rebind("Run", 0, b); err != ErrInputAction {
+		t.Error("Rebind() expected ErrInputAction, got:", err)
+	}
+}
+
+func TestInput_App(t *testing.T) {
+	a := newHeadlessApp(t)
+	defer a.Teardown()
+
+	in := GetInput()
+	in.DefineAction("Jump", KeyBinding(glfw.KeySpace))
+
+	c := &inputComponent{}
+	GetInstance().MustAssign(c)
+
+	s := NewScene("test")
+	s.SetLoadFunc(func() error {
+		o := NewGameObject("player")
+		o.AddComponent(c)
+
+		return s.Graph().AddGameObject(o, nil)
+	})
+
+	if err := a.RegisterScene(s); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.PushScene("test"); err != nil {
+		t.Fatal(err)
+	}
+
+	in.InjectKey(glfw.KeySpace, glfw.Press)
+	a.Step(3)
+
+	if c.pressed != 1 {
+		t.Error("c.pressed expected 1, got:", c.pressed)
+	}
+}
+
+type inputComponent struct {
+	BaseScriptComponent
+
+	pressed int
+}
+
+func (c *inputComponent) Update() {
+	if GetInput().Pressed("Jump") {
+		c.pressed++
+	}
+}
+
+func TestInput_Text(t *testing.T) {
+	in := NewInput()
+
+	in.InjectKey(glfw.KeyLeftShift, glfw.Press)
+	in.InjectChar('H')
+	in.InjectKey(glfw.KeyLeftShift, glfw.Release)
+	in.InjectChar('é')
+	in.InjectKey(glfw.KeyBackspace, glfw.Press)
+	in.InjectKey(glfw.KeyBackspace, glfw.Repeat)
+	in.Update()
+
+	if s := in.Text(); s != "Hé" {
+		t.Error("in.Text() expected Hé, got:", s)
+	}
+
+	events := in.TextEvents()
+	if len(events) != 5 {
+		t.Fatal("in.TextEvents() expected 5 events, got:", events)
+	}
+	if events[1].Char != 'H' || events[1].Mods != glfw.ModShift {
+		t.Error("events[1] expected shifted H, got:", events[1])
+	}
+	if events[3].Key != glfw.KeyBackspace || events[4].Key != glfw.KeyBackspace {
+		t.Error("events[3:] expected backspace press and repeat, got:", events[3:])
+	}
+
+	in.Update()
+
+	if len(in.TextEvents()) != 0 {
+		t.Error("in.TextEvents() expected empty on the next frame")
+	}
+
+	in.SetClipboardText("copied")
+	if s := in.ClipboardText(); s != "copied" {
+		t.Error("in.ClipboardText() expected copied, got:", s)
+	}
+}
@@ -103,53 +103,55 @@ func (s *Instance) MustAssign(object Object) {
 }
 
 func (s *Instance) Release(ids ...uint32) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
 	for _, v := range ids {
 		if v == 0 {
 			continue
 		}
 
-		if _, ok := s.objects[v]; !ok {
+		if !s.release(v) {
 			logrus.Error(ErrIDNotFound(v))
-			continue
 		}
-
-		if s.objects[v] == nil {
-			logrus.Warnf("Attempted to release nil object %08X", v)
-		} else {
-			s.objects[v].Dealloc()
-			s.objects[v].Release()
-		}
-
-		delete(s.objects, v)
-
-		logrus.Debugf("Released ID %08X", v)
 	}
 }
 
 func (s *Instance) ReleaseAll() {
 	s.mu.Lock()
-	defer s.mu.Unlock()
-
+	ids := make([]uint32, 0, len(s.objects))
 	for v := range s.objects {
-		if _, ok := s.objects[v]; !ok {
-			logrus.Error(ErrIDNotFound(v))
-			continue
-		}
+		ids = append(ids, v)
+	}
+	s.mu.Unlock()
 
-		if s.objects[v] == nil {
-			logrus.Warnf("Attempted to release nil object %08X", v)
-		} else {
-			s.objects[v].Dealloc()
-			s.objects[v].Release()
-		}
+	// Objects released by the Dealloc of an object they belong to are
+	// skipped.
+	for _, v := range ids {
+		s.release(v)
+	}
+}
 
-		delete(s.objects, v)
+// release removes the object with the ID, then deallocates and releases it.
+// The lock is not held while deallocating, so objects can release the objects
+// they own. It returns false if the ID is not assigned.
+func (s *Instance) release(id uint32) bool {
+	s.mu.Lock()
+	object, ok := s.objects[id]
+	delete(s.objects, id)
+	s.mu.Unlock()
 
-		logrus.Debugf("Released ID %08X", v)
+	if !ok {
+		return false
+	}
+
+	if object == nil {
+		logrus.Warnf("Attempted to release nil object %08X", id)
+	} else {
+		object.Dealloc()
+		object.Release()
 	}
+
+	logrus.Debugf("Released ID %08X", id)
+
+	return true
 }
 
 func (s *Instance) nextID() (uint32, error) {
@@ -25,3 +25,13 @@ package engine
 type Light struct {
 	BaseComponent
 }
+
+// NewLight creates a new Light component.
+func NewLight() *Light {
+	l := &Light{}
+
+	l.SetName("Light")
+	GetInstance().MustAssign(l)
+
+	return l
+}
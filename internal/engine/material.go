@@ -47,16 +47,43 @@ type Material struct {
 	shader           *Shader
 }
 
+// SetTexture sets a texture of the material. The material holds a reference
+// to the texture until it is replaced or the material is released.
 func (m *Material) SetTexture(id MaterialTexture, texture Texture) {
 	if id < MaterialMaxTextures {
+		if m.textures[id] != nil {
+			GetAsset().Unref(m.textures[id])
+		}
+		if texture != nil {
+			GetAsset().Ref(texture)
+		}
+
 		m.textures[id] = texture
 	}
 }
 
+// SetShader sets the shader of the material. The material holds a reference
+// to the shader until it is replaced or the material is released.
 func (m *Material) SetShader(shader *Shader) {
+	if m.shader != nil {
+		GetAsset().Unref(m.shader)
+	}
+	if shader != nil {
+		GetAsset().Ref(shader)
+	}
+
 	m.shader = shader
 }
 
+// Dealloc drops the references held by the material.
+func (m *Material) Dealloc() {
+	for i := range m.textures {
+		m.SetTexture(MaterialTexture(i), nil)
+	}
+
+	m.SetShader(nil)
+}
+
 func (m *Material) Texture(id MaterialTexture) Texture {
 	if id >= MaterialMaxTextures {
 		return nil
@@ -70,11 +97,17 @@ func (m *Material) Shader() *Shader {
 }
 
 func (m *Material) Bind() {
-	if m.shader == nil {
+	m.BindShader(m.shader)
+}
+
+// BindShader binds the textures and properties of the material with another
+// shader, such as a variant of the material shader.
+func (m *Material) BindShader(shader *Shader) {
+	if shader == nil {
 		return
 	}
 
-	m.shader.Bind()
+	shader.Bind()
 
 	for i := range m.textures {
 		if m.textures[i] != nil {
@@ -82,7 +115,7 @@ func (m *Material) Bind() {
 		}
 	}
 	for key, value := range m.shaderProperties {
-		m.shader.SetUniform(key, value)
+		shader.SetUniform(key, value)
 	}
 }
 
@@ -116,7 +149,7 @@ func NewMaterial() *Material {
 func NewMaterialPBR() *Material {
 	m := NewMaterial()
 
-	m.shader = DefaultShader()
+	m.SetShader(DefaultShader())
 
 	m.SetProperty("f_albedo", ColorCopper.Vec3())
 	m.SetProperty("f_metallic", 1.0)
@@ -37,18 +37,44 @@ type Mesh struct {
 	normals        []mgl32.Vec3
 	uvs            []mgl32.Vec2
 	triangles      []uint32
+	joints         [][4]uint16
+	weights        []mgl32.Vec4
+	subMeshes      []SubMesh
 	vao            uint32
 	vbo            uint32
 	ibo            uint32
+	sbo            uint32
 	reverseWinding bool
 }
 
+// SubMesh is a range of triangles within a mesh which share a material.
+type SubMesh struct {
+	Name     string
+	Material *Material
+	Offset   int32 // Offset is the first vertex, or first index if indexed.
+	Count    int32 // Count is the number of vertices, or indices if indexed.
+}
+
+// MeshDraw is a part of a mesh drawn with one material. A SubMesh of -1 draws
+// the whole mesh.
+type MeshDraw struct {
+	SubMesh  int
+	Material *Material
+}
+
 type Vertex struct {
 	V mgl32.Vec3
 	N mgl32.Vec3
 	U mgl32.Vec2
 }
 
+// SkinVertex holds the joint indices of a vertex of a skinned mesh, and the
+// weights of their influence.
+type SkinVertex struct {
+	J [4]uint16
+	W mgl32.Vec4
+}
+
 // NewMesh creates a new mesh object.
 func NewMesh() *Mesh {
 	m := &Mesh{}
@@ -81,9 +107,15 @@ func (m *Mesh) Alloc() error {
 
 // Dealloc releases builtin for this mesh.
 func (m *Mesh) Dealloc() {
-	gl.DeleteBuffers(1, &m.vbo)
-	gl.DeleteBuffers(1, &m.ibo)
-	gl.DeleteVertexArrays(1, &m.vao)
+	if m.vao != 0 {
+		gl.DeleteBuffers(1, &m.vbo)
+		gl.DeleteBuffers(1, &m.ibo)
+		if m.sbo != 0 {
+			gl.DeleteBuffers(1, &m.sbo)
+		}
+		gl.DeleteVertexArrays(1, &m.vao)
+		m.vbo, m.ibo, m.sbo, m.vao = 0, 0, 0, 0
+	}
 }
 
 func (m *Mesh) Bind() {
@@ -99,7 +131,11 @@ func (m *Mesh) Draw() {
 		return
 	}
 
-	gl.DrawArrays(gl.TRIANGLES, 0, int32(len(m.vertices)))
+	if m.Indexed() {
+		gl.DrawElements(gl.TRIANGLES, int32(len(m.triangles)), gl.UNSIGNED_INT, nil)
+	} else {
+		gl.DrawArrays(gl.TRIANGLES, 0, int32(len(m.vertices)))
+	}
 }
 
 func (m *Mesh) Clear() {
@@ -107,6 +143,9 @@ func (m *Mesh) Clear() {
 	m.normals = m.normals[:0]
 	m.uvs = m.uvs[:0]
 	m.triangles = m.triangles[:0]
+	m.joints = m.joints[:0]
+	m.weights = m.weights[:0]
+	m.subMeshes = m.subMeshes[:0]
 }
 
 func (m *Mesh) Upload() error {
@@ -118,6 +157,10 @@ func (m *Mesh) Upload() error {
 		return fmt.Errorf("mesh upload failed: vao %d has invalid geometry definition: asymmetric data", m.vao)
 	}
 
+	if (len(m.joints) != 0 || len(m.weights) != 0) && (len(m.joints) != len(m.vertices) || len(m.weights) != len(m.vertices)) {
+		return fmt.Errorf("mesh upload failed: vao %d has invalid skin definition: asymmetric data", m.vao)
+	}
+
 	data := make([]Vertex, len(m.vertices))
 	for idx := range m.vertices {
 		data[idx] = Vertex{m.vertices[idx], m.normals[idx], m.uvs[idx]}
@@ -126,11 +169,112 @@ func (m *Mesh) Upload() error {
 	m.Bind()
 	gl.BindBuffer(gl.ARRAY_BUFFER, m.vbo)
 	gl.BufferData(gl.ARRAY_BUFFER, len(data)*32, gl.Ptr(data), gl.STATIC_DRAW)
+	if m.Indexed() {
+		gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, m.ibo)
+		gl.BufferData(gl.ELEMENT_ARRAY_BUFFER, len(m.triangles)*4, gl.Ptr(m.triangles), gl.STATIC_DRAW)
+	}
+	if m.Skinned() {
+		m.uploadSkin()
+	}
 	m.Unbind()
 
 	return nil
 }
 
+// uploadSkin uploads the joints and weights into a second vertex buffer,
+// bound to attributes 3 and 4. The mesh must be bound.
+func (m *Mesh) uploadSkin() {
+	if m.sbo == 0 {
+		gl.GenBuffers(1, &m.sbo)
+	}
+
+	data := make([]SkinVertex, len(m.joints))
+	for idx := range m.joints {
+		data[idx] = SkinVertex{m.joints[idx], m.weights[idx]}
+	}
+
+	gl.BindBuffer(gl.ARRAY_BUFFER, m.sbo)
+	gl.BufferData(gl.ARRAY_BUFFER, len(data)*24, gl.Ptr(data), gl.STATIC_DRAW)
+
+	gl.EnableVertexAttribArray(3)
+	gl.VertexAttribIPointer(3, 4, gl.UNSIGNED_SHORT, 24, gl.PtrOffset(0))
+	gl.EnableVertexAttribArray(4)
+	gl.VertexAttribPointer(4, 4, gl.FLOAT, false, 24, gl.PtrOffset(8))
+}
+
+// Weld merges vertices with identical position, normal and uv into a shared
+// vertex list, and indexes the triangles into it. The order of the triangles
+// is kept, so sub-mesh ranges remain valid as index ranges. Vertices of a
+// skinned mesh must also share their joints and weights.
+func (m *Mesh) Weld() {
+	if len(m.vertices) != len(m.normals) || len(m.normals) != len(m.uvs) {
+		return
+	}
+
+	skinned := m.Skinned()
+	if skinned && (len(m.joints) != len(m.vertices) || len(m.weights) != len(m.vertices)) {
+		return
+	}
+
+	indices := m.triangles
+	if !m.Indexed() {
+		indices = make([]uint32, len(m.vertices))
+		for i := range indices {
+			indices[i] = uint32(i)
+		}
+	}
+
+	type weldKey struct {
+		Vertex
+		SkinVertex
+	}
+
+	lookup := make(map[weldKey]uint32, len(m.vertices))
+	remap := make([]uint32, len(m.vertices))
+
+	var v, n []mgl32.Vec3
+	var t []mgl32.Vec2
+	var j [][4]uint16
+	var w []mgl32.Vec4
+
+	for i := range m.vertices {
+		key := weldKey{Vertex: Vertex{m.vertices[i], m.normals[i], m.uvs[i]}}
+		if skinned {
+			key.SkinVertex = SkinVertex{m.joints[i], m.weights[i]}
+		}
+
+		idx, ok := lookup[key]
+		if !ok {
+			idx = uint32(len(v))
+			lookup[key] = idx
+
+			v = append(v, key.V)
+			n = append(n, key.N)
+			t = append(t, key.U)
+			if skinned {
+				j = append(j, key.J)
+				w = append(w, key.W)
+			}
+		}
+
+		remap[i] = idx
+	}
+
+	triangles := make([]uint32, len(indices))
+	for i := range indices {
+		triangles[i] = remap[indices[i]]
+	}
+
+	m.vertices = v
+	m.normals = n
+	m.uvs = t
+	m.triangles = triangles
+	if skinned {
+		m.joints = j
+		m.weights = w
+	}
+}
+
 func (m *Mesh) Vertices() []mgl32.Vec3 {
 	return m.vertices
 }
@@ -147,6 +291,67 @@ func (m *Mesh) Triangles() []uint32 {
 	return m.triangles
 }
 
+// Joints returns the joint indices of each vertex of a skinned mesh.
+func (m *Mesh) Joints() [][4]uint16 {
+	return m.joints
+}
+
+// Weights returns the joint weights of each vertex of a skinned mesh.
+func (m *Mesh) Weights() []mgl32.Vec4 {
+	return m.weights
+}
+
+// Skinned reports whether this mesh has joints and weights.
+func (m *Mesh) Skinned() bool {
+	return len(m.joints) != 0
+}
+
+// SubMeshes returns the sub-meshes of this mesh.
+func (m *Mesh) SubMeshes() []SubMesh {
+	return m.subMeshes
+}
+
+// DrawSubMesh draws the sub-mesh at the given index.
+func (m *Mesh) DrawSubMesh(index int) {
+	if index < 0 || index >= len(m.subMeshes) {
+		return
+	}
+
+	if m.Indexed() {
+		gl.DrawElements(gl.TRIANGLES, m.subMeshes[index].Count, gl.UNSIGNED_INT, gl.PtrOffset(int(m.subMeshes[index].Offset)*4))
+	} else {
+		gl.DrawArrays(gl.TRIANGLES, m.subMeshes[index].Offset, m.subMeshes[index].Count)
+	}
+}
+
+// Draws returns the draws rendering this mesh. Each sub-mesh is drawn with its
+// own material, or with the fallback if it has none. A mesh without sub-meshes
+// is drawn whole with the fallback.
+func (m *Mesh) Draws(fallback *Material) []MeshDraw {
+	if len(m.subMeshes) == 0 {
+		return []MeshDraw{{SubMesh: -1, Material: fallback}}
+	}
+
+	draws := make([]MeshDraw, len(m.subMeshes))
+	for i := range m.subMeshes {
+		draws[i] = MeshDraw{SubMesh: i, Material: m.subMeshes[i].Material}
+		if draws[i].Material == nil {
+			draws[i].Material = fallback
+		}
+	}
+
+	return draws
+}
+
+// DrawPart draws the part of the mesh given by the draw.
+func (m *Mesh) DrawPart(d MeshDraw) {
+	if d.SubMesh < 0 {
+		m.Draw()
+	} else {
+		m.DrawSubMesh(d.SubMesh)
+	}
+}
+
 func (m *Mesh) Indexed() bool {
 	return len(m.triangles) != 0
 }
@@ -167,6 +372,28 @@ func (m *Mesh) SetUvs(uvs []mgl32.Vec2) {
 	m.uvs = uvs
 }
 
+// SetTriangles sets the vertex indices of this mesh. Each group of three
+// indices forms a triangle.
+func (m *Mesh) SetTriangles(triangles []uint32) {
+	m.triangles = triangles
+}
+
+// SetJoints sets the indices of the four joints influencing each vertex.
+func (m *Mesh) SetJoints(joints [][4]uint16) {
+	m.joints = joints
+}
+
+// SetWeights sets the weights of the joints influencing each vertex. The
+// weights of a vertex should sum to one.
+func (m *Mesh) SetWeights(weights []mgl32.Vec4) {
+	m.weights = weights
+}
+
+// SetSubMeshes sets the sub-meshes of this mesh.
+func (m *Mesh) SetSubMeshes(subMeshes []SubMesh) {
+	m.subMeshes = subMeshes
+}
+
 func (m *Mesh) SetReversedWinding(reverse bool) {
 	m.reverseWinding = reverse
 }
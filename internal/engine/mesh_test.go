@@ -0,0 +1,97 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestMesh_Weld(t *testing.T) {
+	a := newHeadlessApp(t)
+	defer a.Teardown()
+
+	// Two triangles of a quad, sharing an edge.
+	v := []mgl32.Vec3{{0, 0, 0}, {1, 0, 0}, {1, 1, 0}, {0, 0, 0}, {1, 1, 0}, {0, 1, 0}}
+	n := make([]mgl32.Vec3, len(v))
+	u := []mgl32.Vec2{{0, 0}, {1, 0}, {1, 1}, {0, 0}, {1, 1}, {0, 1}}
+	for i := range n {
+		n[i] = mgl32.Vec3{0, 0, 1}
+	}
+
+	m := NewMesh()
+	m.SetVertices(v)
+	m.SetNormals(n)
+	m.SetUvs(u)
+	m.Weld()
+
+	if !m.Indexed() {
+		t.Fatal("m.Indexed() expected true")
+	}
+	if len(m.Vertices()) != 4 || len(m.Normals()) != 4 || len(m.Uvs()) != 4 {
+		t.Fatalf("expected 4 vertices, got: %d", len(m.Vertices()))
+	}
+
+	triangles := m.Triangles()
+	if len(triangles) != len(v) {
+		t.Fatalf("expected %d indices, got: %d", len(v), len(triangles))
+	}
+	for i := range triangles {
+		if m.Vertices()[triangles[i]] != v[i] || m.Uvs()[triangles[i]] != u[i] {
+			t.Errorf("index %d does not reference the original vertex", i)
+		}
+	}
+
+	// Vertices which differ only in uv must not be merged.
+	m.SetVertices([]mgl32.Vec3{{0, 0, 0}, {0, 0, 0}, {0, 0, 0}})
+	m.SetNormals(n[:3])
+	m.SetUvs([]mgl32.Vec2{{0, 0}, {1, 0}, {0, 0}})
+	m.SetTriangles(nil)
+	m.Weld()
+
+	if len(m.Vertices()) != 2 {
+		t.Errorf("expected 2 vertices, got: %d", len(m.Vertices()))
+	}
+}
+
+func TestMesh_WeldSkinned(t *testing.T) {
+	a := newHeadlessApp(t)
+	defer a.Teardown()
+
+	m := NewMesh()
+	m.SetVertices([]mgl32.Vec3{{0, 0, 0}, {0, 0, 0}, {0, 0, 0}})
+	m.SetNormals([]mgl32.Vec3{{0, 1, 0}, {0, 1, 0}, {0, 1, 0}})
+	m.SetUvs([]mgl32.Vec2{{}, {}, {}})
+	m.SetJoints([][4]uint16{{0}, {0}, {1}})
+	m.SetWeights([]mgl32.Vec4{{1}, {1}, {1}})
+
+	m.Weld()
+
+	if len(m.Vertices()) != 2 || len(m.Joints()) != 2 || len(m.Weights()) != 2 {
+		t.Fatalf("expected 2 welded vertices, got: %d", len(m.Vertices()))
+	}
+	if tri := m.Triangles(); tri[0] != tri[1] || tri[1] == tri[2] {
+		t.Errorf("expected vertices with other joints to stay apart, got: %v", tri)
+	}
+}
@@ -0,0 +1,202 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"fmt"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Model is a hierarchy of nodes with meshes and materials, such as a scene
+// imported from glTF.
+type Model struct {
+	BaseObject
+
+	nodes []ModelNode
+	roots []int
+	parts []modelPart
+}
+
+// modelPart is a mesh or material of a model tracked by its handler.
+type modelPart struct {
+	kind string
+	name string
+	id   uint32
+}
+
+// ModelNode is a node in a model hierarchy. Each mesh is drawn with the
+// material at the same index. Meshes of nodes with a skeleton are skinned by
+// the nodes named by its joints.
+type ModelNode struct {
+	Name      string
+	Position  mgl32.Vec3
+	Rotation  mgl32.Quat
+	Scale     mgl32.Vec3
+	Meshes    []*Mesh
+	Materials []*Material
+	Skeleton  *Skeleton
+	Children  []int
+}
+
+// NewModel creates a new empty model.
+func NewModel() *Model {
+	m := &Model{}
+
+	m.SetName("Model")
+	GetInstance().MustAssign(m)
+
+	return m
+}
+
+// Nodes returns all nodes of the model.
+func (m *Model) Nodes() []ModelNode {
+	return m.nodes
+}
+
+// Roots returns the indices of the root nodes of the model.
+func (m *Model) Roots() []int {
+	return m.roots
+}
+
+// SetNodes sets the nodes of the model and the indices of its root nodes.
+func (m *Model) SetNodes(nodes []ModelNode, roots []int) {
+	m.nodes = nodes
+	m.roots = roots
+}
+
+// addParts tracks the meshes and materials of the model with their handlers,
+// so that scenes can refer to them by name. They are named by the model and
+// their order in it, such as "robot.gltf#mesh3" and "robot.gltf#material0".
+func (m *Model) addParts(name string) error {
+	seen := make(map[uint32]bool)
+	var meshes, materials int
+
+	add := func(kind string, o Object, index *int) error {
+		if o.ID() == 0 || seen[o.ID()] {
+			return nil
+		}
+		seen[o.ID()] = true
+
+		h, err := GetAsset().GetHandler(kind)
+		if err != nil {
+			return err
+		}
+
+		part := modelPart{kind: kind, name: fmt.Sprintf("%s#%s%d", name, kind, *index), id: o.ID()}
+		*index++
+
+		var base *BaseAssetHandler
+		switch v := h.(type) {
+		case *MeshHandler:
+			base = &v.BaseAssetHandler
+		case *MaterialHandler:
+			base = &v.BaseAssetHandler
+		default:
+			return ErrAssetType(part.name)
+		}
+
+		if err := base.addPart(part.name, part.id); err != nil {
+			return err
+		}
+
+		m.parts = append(m.parts, part)
+
+		return nil
+	}
+
+	for i := range m.nodes {
+		for _, mesh := range m.nodes[i].Meshes {
+			if err := add(AssetNameMesh, mesh, &meshes); err != nil {
+				m.removeParts()
+				return err
+			}
+		}
+		for _, material := range m.nodes[i].Materials {
+			if err := add(AssetNameMaterial, material, &materials); err != nil {
+				m.removeParts()
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// removeParts stops tracking the parts of the model with their handlers, and
+// returns the IDs of those which were still tracked.
+func (m *Model) removeParts() []uint32 {
+	var ids []uint32
+
+	for _, p := range m.parts {
+		h, err := GetAsset().GetHandler(p.kind)
+		if err != nil {
+			continue
+		}
+
+		if id, err := h.Remove(p.name); err == nil && id == p.id {
+			ids = append(ids, id)
+		}
+	}
+
+	m.parts = nil
+
+	return ids
+}
+
+// Dealloc releases the meshes, materials and skeletons of the model. The
+// materials drop their references to their textures as they are released.
+func (m *Model) Dealloc() {
+	seen := make(map[uint32]bool)
+	for _, p := range m.parts {
+		seen[p.id] = true
+	}
+
+	// Parts which were removed from their handlers, such as by
+	// Asset.ReleaseAll, are released by whoever removed them.
+	ids := m.removeParts()
+
+	add := func(o Object) {
+		if o != nil && o.ID() != 0 && !seen[o.ID()] {
+			seen[o.ID()] = true
+			ids = append(ids, o.ID())
+		}
+	}
+
+	for i := range m.nodes {
+		for _, mesh := range m.nodes[i].Meshes {
+			add(mesh)
+		}
+		for _, material := range m.nodes[i].Materials {
+			add(material)
+		}
+		if m.nodes[i].Skeleton != nil {
+			add(m.nodes[i].Skeleton)
+		}
+	}
+
+	m.nodes = nil
+	m.roots = nil
+
+	GetInstance().Release(ids...)
+}
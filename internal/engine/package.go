@@ -39,6 +39,8 @@ var (
 	pkgRoot      = "assets"
 )
 
+var _ FileSource = &Package{}
+
 type Package struct {
 	name   string
 	path   string
@@ -83,6 +85,15 @@ func NewPackage(name string) *Package {
 	return p
 }
 
+// NewPackageFile creates a package for the file at the given path. The package
+// is named by the filename without its extension.
+func NewPackageFile(filename string) *Package {
+	return &Package{
+		name: strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename)),
+		path: filename,
+	}
+}
+
 func (p *Package) Mount() error {
 	if p.reader != nil {
 		return ErrPackageMounted(p.name)
@@ -90,7 +101,7 @@ func (p *Package) Mount() error {
 
 	reader, err := zip.OpenReader(p.path)
 	if err != nil {
-		panic(err)
+		return err
 	}
 
 	p.reader = reader
@@ -117,6 +128,21 @@ func (p *Package) Path() string {
 	return p.path
 }
 
+// Has reports if the package contains the file.
+func (p *Package) Has(filename string) bool {
+	if p.reader == nil {
+		return false
+	}
+
+	for _, f := range p.reader.File {
+		if f.Name == filename {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (p *Package) Read(filename string, w io.Writer) error {
 	if p.reader == nil {
 		return ErrPackageNotMounted(p.name)
@@ -145,7 +171,7 @@ func (p *Package) Read(filename string, w io.Writer) error {
 
 	_, err = io.Copy(w, fReader)
 
-	return nil
+	return err
 }
 
 func IsPackagePath(filename string) bool {
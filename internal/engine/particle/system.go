@@ -38,11 +38,29 @@ import (
 )
 
 var _ engine.Renderer = &System{}
+var _ engine.SceneDocumenter = &System{}
 
 const (
 	workgroupSize = uint32(128)
 )
 
+// DefaultMaxParticles is the particle capacity of systems created from scene
+// documents, before their settings are applied.
+const DefaultMaxParticles = uint32(1000000)
+
+// Names of the input actions of the particle controls.
+const (
+	ActionRateUp       = "ParticleRateUp"
+	ActionRateDown     = "ParticleRateDown"
+	ActionRateClear    = "ParticleRateClear"
+	ActionBurst        = "ParticleBurst"
+	ActionLifetimeUp   = "ParticleLifetimeUp"
+	ActionLifetimeDown = "ParticleLifetimeDown"
+	ActionAttractors   = "ParticleAttractors"
+	ActionSpeedUp      = "ParticleSpeedUp"
+	ActionSpeedDown    = "ParticleSpeedDown"
+)
+
 type System struct {
 	engine.BaseScriptComponent
 
@@ -141,42 +159,55 @@ func (s *System) Simulate() {
 	s.swapBuffers()
 }
 
+// Awake defines the actions of the particle controls.
+func (s *System) Awake() {
+	input.DefineAction(ActionRateUp, engine.KeyBinding(glfw.Key9))
+	input.DefineAction(ActionRateDown, engine.KeyBinding(glfw.Key8))
+	input.DefineAction(ActionRateClear, engine.KeyBinding(glfw.Key0))
+	input.DefineAction(ActionBurst, engine.KeyBinding(glfw.KeySpace))
+	input.DefineAction(ActionLifetimeUp, engine.KeyBinding(glfw.Key7))
+	input.DefineAction(ActionLifetimeDown, engine.KeyBinding(glfw.Key6))
+	input.DefineAction(ActionAttractors, engine.KeyBinding(glfw.Key5))
+	input.DefineAction(ActionSpeedUp, engine.KeyBinding(glfw.Key4))
+	input.DefineAction(ActionSpeedDown, engine.KeyBinding(glfw.Key3))
+}
+
 func (s *System) Update() {
-	if input.KeyDown(glfw.Key9) {
+	if input.Pressed(ActionRateUp) {
 		s.Emission.Rate += 500.0
-	} else if input.KeyDown(glfw.Key8) {
+	} else if input.Pressed(ActionRateDown) {
 		s.Emission.Rate -= 500.0
 		if s.Emission.Rate < 0 {
 			s.Emission.Rate = 0
 		}
-	} else if input.KeyDown(glfw.Key0) {
+	} else if input.Pressed(ActionRateClear) {
 		s.Emission.Rate = 0.0
-	} else if input.KeyDown(glfw.KeySpace) {
+	} else if input.Pressed(ActionBurst) {
 		s.Emission.Rate = 10000.0
 	}
 
-	if input.KeyUp(glfw.KeySpace) {
+	if input.Released(ActionBurst) {
 		s.Emission.Rate = 0.0
 	}
 
-	if input.KeyDown(glfw.Key6) {
+	if input.Pressed(ActionLifetimeDown) {
 		s.Core.StartLifetime -= 1.0
 
 		if s.Core.StartLifetime <= 0 {
 			s.Core.StartLifetime = 1
 		}
-	} else if input.KeyDown(glfw.Key7) {
+	} else if input.Pressed(ActionLifetimeUp) {
 		s.Core.StartLifetime += 1.0
 	}
 
-	if input.KeyDown(glfw.Key5) {
+	if input.Pressed(ActionAttractors) {
 		s.Force.EnableAttractors = !s.Force.EnableAttractors
 		fmt.Printf("EnableAttractors: %v\n", s.Force.EnableAttractors)
 	}
 
-	if input.KeyDown(glfw.Key4) {
+	if input.Pressed(ActionSpeedUp) {
 		s.Core.PlaybackSpeed += 0.1
-	} else if input.KeyDown(glfw.Key3) {
+	} else if input.Pressed(ActionSpeedDown) {
 		s.Core.PlaybackSpeed -= 0.1
 		if s.Core.PlaybackSpeed < 0 {
 			s.Core.PlaybackSpeed = 0
@@ -217,6 +248,84 @@ func (s *System) swapBuffers() {
 	}
 }
 
+// systemDocument is the serialized form of a System.
+type systemDocument struct {
+	MaxParticles     uint32
+	StartColor       engine.Color
+	Duration         float32
+	StartDelay       float32
+	StartLifetime    float32
+	StartSpeed       float32
+	StartSize        float32
+	PlaybackSpeed    float32
+	RandomSeed       uint32
+	Looping          bool
+	EmissionRate     float32
+	Shape            ShapeType
+	EnableAttractors bool
+	Attractors       []Attractor
+}
+
+// SceneDocument returns the settings of the system and its modules.
+func (s *System) SceneDocument() (interface{}, error) {
+	doc := &systemDocument{
+		MaxParticles:     s.Core.maxParticles,
+		StartColor:       s.Core.StartColor,
+		Duration:         s.Core.Duration,
+		StartDelay:       s.Core.StartDelay,
+		StartLifetime:    s.Core.StartLifetime,
+		StartSpeed:       s.Core.StartSpeed,
+		StartSize:        s.Core.StartSize,
+		PlaybackSpeed:    s.Core.PlaybackSpeed,
+		RandomSeed:       s.Core.RandomSeed,
+		Looping:          s.Core.Looping,
+		EmissionRate:     s.Emission.Rate,
+		Shape:            s.Shape.Shape,
+		EnableAttractors: s.Force.EnableAttractors,
+	}
+
+	for _, a := range s.Force.attractors {
+		doc.Attractors = append(doc.Attractors, *a)
+	}
+
+	return doc, nil
+}
+
+// ApplySceneDocument sets the settings of the system and its modules. The
+// particles are cleared if the capacity changes.
+func (s *System) ApplySceneDocument(doc interface{}) error {
+	d := doc.(*systemDocument)
+
+	if d.MaxParticles != s.Core.maxParticles {
+		s.Core.SetMaxParticles(d.MaxParticles)
+
+		s.bufferFlip = false
+		s.inOffset = 0
+		s.outOffset = d.MaxParticles
+	}
+
+	s.Core.StartColor = d.StartColor
+	s.Core.Duration = d.Duration
+	s.Core.StartDelay = d.StartDelay
+	s.Core.StartLifetime = d.StartLifetime
+	s.Core.StartSpeed = d.StartSpeed
+	s.Core.StartSize = d.StartSize
+	s.Core.PlaybackSpeed = d.PlaybackSpeed
+	s.Core.RandomSeed = d.RandomSeed
+	s.Core.Looping = d.Looping
+	s.Emission.Rate = d.EmissionRate
+	s.Shape.Shape = d.Shape
+	s.Force.EnableAttractors = d.EnableAttractors
+
+	s.Force.attractors = s.Force.attractors[:0]
+	for i := range d.Attractors {
+		a := d.Attractors[i]
+		s.Force.AddAttractor(&a)
+	}
+
+	return nil
+}
+
 func NewParticleSystem(maxParticles uint32) *System {
 	s := &System{
 		inOffset:  0,
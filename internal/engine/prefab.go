@@ -0,0 +1,272 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Prefab is a template for a game object hierarchy, described by a game
+// object document. Instances are created with Scene.Instantiate.
+type Prefab struct {
+	BaseObject
+
+	doc       GameObjectDocument
+	instances map[uint32]*PrefabInstance
+}
+
+// PrefabOverride is a component field of a prefab instance which differs from
+// the prefab. Path is the slash separated path of names from the instance
+// root to the game object, or empty for the instance root. Component is the
+// registered component type name.
+type PrefabOverride struct {
+	Path      string      `json:"path,omitempty" yaml:"path,omitempty"`
+	Component string      `json:"component" yaml:"component"`
+	Field     string      `json:"field" yaml:"field"`
+	Value     interface{} `json:"value" yaml:"value"`
+}
+
+// PrefabInstance is a component attached to the root of each prefab instance.
+// It records the overrides of the instance, which are kept when the prefab
+// changes.
+type PrefabInstance struct {
+	BaseComponent
+
+	prefab    *Prefab
+	overrides []PrefabOverride
+}
+
+// NewPrefab creates a new prefab from the document.
+func NewPrefab(doc *GameObjectDocument) *Prefab {
+	p := &Prefab{
+		doc:       *doc,
+		instances: make(map[uint32]*PrefabInstance),
+	}
+
+	p.SetName(doc.Name)
+	GetInstance().MustAssign(p)
+
+	return p
+}
+
+// Document returns the document describing the prefab.
+func (p *Prefab) Document() *GameObjectDocument {
+	return &p.doc
+}
+
+// SetDocument replaces the document describing the prefab, and updates the
+// component fields and transforms of its instances. The roots of instances
+// keep their transforms, and overridden fields keep their values. Game objects
+// and components added to or removed from the prefab are only reflected in
+// new instances.
+func (p *Prefab) SetDocument(doc *GameObjectDocument) error {
+	p.doc = *doc
+
+	for _, instance := range p.instances {
+		if err := instance.refresh(); err != nil {
+			return fmt.Errorf("prefab %s: %v", p.Name(), err)
+		}
+	}
+
+	return nil
+}
+
+// Instances returns the number of live instances of the prefab.
+func (p *Prefab) Instances() int {
+	return len(p.instances)
+}
+
+// instantiate creates a new game object hierarchy from the prefab.
+func (p *Prefab) instantiate() (*GameObject, error) {
+	o, err := newGameObjectFromDocument(&p.doc)
+	if err != nil {
+		return nil, err
+	}
+
+	instance := &PrefabInstance{prefab: p}
+	instance.SetName("PrefabInstance")
+	GetInstance().MustAssign(instance)
+
+	o.AddComponent(instance)
+
+	p.instances[instance.ID()] = instance
+	GetAsset().Ref(p)
+
+	return o, nil
+}
+
+// Prefab returns the prefab the game object was instantiated from.
+func (c *PrefabInstance) Prefab() *Prefab {
+	return c.prefab
+}
+
+// Overrides returns the overrides of the instance.
+func (c *PrefabInstance) Overrides() []PrefabOverride {
+	return c.overrides
+}
+
+// SetOverride sets a component field of the instance, and keeps it when the
+// prefab changes.
+func (c *PrefabInstance) SetOverride(path, component, field string, value interface{}) error {
+	o := PrefabOverride{Path: path, Component: component, Field: field, Value: value}
+	if err := c.apply(&o); err != nil {
+		return err
+	}
+
+	for i := range c.overrides {
+		if c.overrides[i].Path == path && c.overrides[i].Component == component && c.overrides[i].Field == field {
+			c.overrides[i] = o
+			return nil
+		}
+	}
+
+	c.overrides = append(c.overrides, o)
+
+	return nil
+}
+
+// RemoveOverride removes an override, and reverts the instance to the prefab.
+func (c *PrefabInstance) RemoveOverride(path, component, field string) error {
+	for i := range c.overrides {
+		if c.overrides[i].Path == path && c.overrides[i].Component == component && c.overrides[i].Field == field {
+			c.overrides = append(c.overrides[:i], c.overrides[i+1:]...)
+			return c.refresh()
+		}
+	}
+
+	return nil
+}
+
+// Dealloc detaches the instance from its prefab.
+func (c *PrefabInstance) Dealloc() {
+	if c.prefab == nil {
+		return
+	}
+
+	delete(c.prefab.instances, c.ID())
+	GetAsset().Unref(c.prefab)
+
+	c.prefab = nil
+}
+
+// refresh sets the component fields and transforms of the instance to those
+// of the prefab, then applies the overrides.
+func (c *PrefabInstance) refresh() error {
+	if c.prefab == nil || c.GameObject() == nil {
+		return nil
+	}
+
+	if err := refreshPrefabObject(c.GameObject(), &c.prefab.doc, true); err != nil {
+		return err
+	}
+
+	for i := range c.overrides {
+		if err := c.apply(&c.overrides[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// apply sets the field of the override.
+func (c *PrefabInstance) apply(o *PrefabOverride) error {
+	object := c.GameObject()
+	if object == nil {
+		return ErrSceneGraphNotFound
+	}
+
+	if o.Path != "" {
+		for _, name := range strings.Split(o.Path, "/") {
+			object = childByName(object, name)
+			if object == nil {
+				return fmt.Errorf("prefab: no game object at %s", o.Path)
+			}
+		}
+	}
+
+	for _, component := range object.Components() {
+		if name, ok := ComponentTypeName(component); ok && name == o.Component {
+			return setComponentFields(component, name, map[string]interface{}{o.Field: o.Value})
+		}
+	}
+
+	return fmt.Errorf("prefab: no component %s at %q", o.Component, o.Path)
+}
+
+// refreshPrefabObject sets the component fields and transforms of the game
+// object and its descendants to those of the document. Game objects and
+// components are matched by position, and skipped if they do not match.
+func refreshPrefabObject(o *GameObject, doc *GameObjectDocument, root bool) error {
+	if !root {
+		doc.Transform.apply(o.Transform())
+	}
+
+	// The first component is always the transform.
+	components := o.Components()[1:]
+	for i := range doc.Components {
+		if i >= len(components) {
+			break
+		}
+
+		name, ok := ComponentTypeName(components[i])
+		if !ok || name != doc.Components[i].Type {
+			continue
+		}
+
+		if err := setComponentFields(components[i], name, doc.Components[i].Fields); err != nil {
+			return err
+		}
+	}
+
+	children := o.Children()
+	for i := range doc.Children {
+		if i >= len(children) || children[i].Name() != doc.Children[i].Name {
+			continue
+		}
+
+		if err := refreshPrefabObject(children[i], &doc.Children[i], false); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// childByName returns the first child of the game object with the name.
+func childByName(o *GameObject, name string) *GameObject {
+	for _, c := range o.Children() {
+		if c.Name() == name {
+			return c
+		}
+	}
+
+	return nil
+}
+
+// PrefabInstanceComponent gets the first occurrence of PrefabInstance from
+// the game object.
+func PrefabInstanceComponent(g *GameObject) *PrefabInstance {
+	return GetComponent[*PrefabInstance](g)
+}
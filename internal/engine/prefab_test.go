@@ -0,0 +1,168 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const testPrefab = `{
+	"name": "enemy",
+	"active": true,
+	"tags": ["enemy"],
+	"transform": {"position": [0, 0, 0], "rotation": [0, 0, 0, 1], "scale": [1, 1, 1]},
+	"components": [{"type": "docComponent", "fields": {"Speed": 1, "Label": "grunt"}}],
+	"children": [{
+		"name": "turret",
+		"active": true,
+		"transform": {"position": [0, 1, 0], "rotation": [0, 0, 0, 1], "scale": [1, 1, 1]},
+		"components": [{"type": "docComponent", "fields": {"Speed": 2}}]
+	}]
+}`
+
+func TestScene_Instantiate(t *testing.T) {
+	a := newHeadlessApp(t)
+	defer a.Teardown()
+
+	dir, err := ioutil.TempDir("", "prefab")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "enemy.json")
+	if err := ioutil.WriteFile(filename, []byte(testPrefab), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	asset := GetAsset()
+	if err := asset.Load(AssetNamePrefab, filename); err != nil {
+		t.Fatal(err)
+	}
+	p := asset.MustGet(AssetNamePrefab, "enemy.json").(*Prefab)
+
+	s := NewScene("test")
+	if err := s.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := s.Instantiate(p, nil, &TransformDocument{
+		Position: mgl32.Vec3{5, 0, 0},
+		Rotation: mgl32.Vec4{0, 0, 0, 1},
+		Scale:    mgl32.Vec3{1, 1, 1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := s.Instantiate(p, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if first.ID() == second.ID() || first.Children()[0].ID() == second.Children()[0].ID() {
+		t.Error("expected instances to have distinct IDs")
+	}
+	if n := len(s.Graph().FindByTag("enemy")); n != 2 {
+		t.Errorf("expected 2 enemies, got: %d", n)
+	}
+	if pos := s.Graph().Find("enemy/turret").Transform().WorldPosition(); !pos.ApproxEqual(mgl32.Vec3{5, 1, 0}) {
+		t.Errorf("expected turret at [5 1 0], got: %v", pos)
+	}
+	if n := p.Instances(); n != 2 {
+		t.Errorf("expected 2 instances, got: %d", n)
+	}
+
+	// Overrides survive changes to the prefab.
+	instance := PrefabInstanceComponent(first)
+	if err := instance.SetOverride("turret", "docComponent", "Speed", 10); err != nil {
+		t.Fatal(err)
+	}
+
+	doc := *p.Document()
+	doc.Components = []ComponentDocument{{Type: "docComponent", Fields: map[string]interface{}{"Speed": 3, "Label": "elite"}}}
+	doc.Children = append([]GameObjectDocument{}, doc.Children...)
+	doc.Children[0].Components = []ComponentDocument{{Type: "docComponent", Fields: map[string]interface{}{"Speed": 4}}}
+	if err := p.SetDocument(&doc); err != nil {
+		t.Fatal(err)
+	}
+
+	speed := func(o *GameObject) float32 {
+		return GetComponent[*docComponent](o).Speed
+	}
+
+	if c := GetComponent[*docComponent](first); c.Label != "elite" || c.Speed != 3 {
+		t.Errorf("expected elite with speed 3, got: %+v", c)
+	}
+	if v := speed(first.Children()[0]); v != 10 {
+		t.Errorf("expected overridden speed 10, got: %v", v)
+	}
+	if v := speed(second.Children()[0]); v != 4 {
+		t.Errorf("expected speed 4, got: %v", v)
+	}
+	if pos := first.Transform().Position(); !pos.ApproxEqual(mgl32.Vec3{5, 0, 0}) {
+		t.Errorf("expected instance to keep its position, got: %v", pos)
+	}
+
+	// Scene documents record instances by prefab and overrides.
+	buf := &bytes.Buffer{}
+	if err := SaveScene(buf, s, SceneFormatJSON); err != nil {
+		t.Fatal(err)
+	}
+	sd, err := DecodeSceneDocument(buf, SceneFormatJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dst := NewScene("dst")
+	if err := dst.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if err := dst.LoadDocument(sd); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := dst.Graph().FindByTag("enemy")
+	if len(loaded) != 2 {
+		t.Fatalf("expected 2 enemies, got: %d", len(loaded))
+	}
+	if v := speed(loaded[0].Children()[0]); v != 10 {
+		t.Errorf("expected overridden speed 10, got: %v", v)
+	}
+	if n := p.Instances(); n != 4 {
+		t.Errorf("expected 4 instances, got: %d", n)
+	}
+
+	// Destroyed instances are detached from the prefab.
+	if err := s.Graph().Destroy(second); err != nil {
+		t.Fatal(err)
+	}
+	if n := p.Instances(); n != 3 {
+		t.Errorf("expected 3 instances, got: %d", n)
+	}
+}
@@ -40,6 +40,7 @@ const (
 	ResourceFile    ResourceType = iota // ResourceFile is a file located on the local filesystem.
 	ResourcePackage                     // ResourcePackage is a file located in a package.
 	ResourceBindata                     // ResourceBindata is a file built in to the binary.
+	ResourceVFS                         // ResourceVFS is a file resolved through the virtual filesystem.
 )
 
 // Resource is a represents a read-only file that has an added layer of abstraction
@@ -53,7 +54,9 @@ type Resource struct {
 }
 
 // NewResource creates a new Resource object for the given filename. The type
-// of the resource will be derived from its path.
+// of the resource will be derived from its path. Relative paths are resolved
+// through the virtual filesystem, and read from the working directory if no
+// mounted source has them.
 func NewResource(filename string) (*Resource, error) {
 	r := &Resource{
 		buffer: bytes.NewBuffer([]byte{}),
@@ -68,9 +71,12 @@ func NewResource(filename string) (*Resource, error) {
 		r.resType = ResourcePackage
 		r.container, r.location = SplitPackagePath(filename)
 		r.location = r.Path(r.location)
-	} else {
+	} else if filepath.IsAbs(filename) {
 		r.resType = ResourceFile
 		r.location = filename
+	} else {
+		r.resType = ResourceVFS
+		r.location = filename
 	}
 
 	return r, nil
@@ -128,7 +134,7 @@ func (r *Resource) DirPrefix() string {
 }
 
 func (r *Resource) Path(value string) string {
-	if r.resType == ResourceFile {
+	if r.resType == ResourceFile || r.resType == ResourceVFS {
 		return value
 	}
 
@@ -30,8 +30,8 @@ type Scene struct {
 	onActivateFunc   func()
 	onDeactivateFunc func()
 	name             string
+	file             string
 	loaded           bool
-	started          bool
 }
 
 func (s *Scene) Setup() {
@@ -66,6 +66,12 @@ func (s *Scene) Load() error {
 	s.graph = NewSceneGraph(s)
 	s.environment = NewEnvironment()
 
+	if s.file != "" {
+		if err := s.loadFile(); err != nil {
+			return err
+		}
+	}
+
 	if s.loadFunc != nil {
 		s.loadFunc()
 	}
@@ -97,11 +103,42 @@ func (s *Scene) OnSceneGraphUpdate() {
 	}
 }
 
+// Instantiate creates an instance of the prefab, and adds it to the scene
+// graph under the parent, or at the top of the graph if parent is nil. If
+// transform is not nil, it replaces the transform of the prefab.
+func (s *Scene) Instantiate(prefab *Prefab, parent *GameObject, transform *TransformDocument) (*GameObject, error) {
+	o, err := prefab.instantiate()
+	if err != nil {
+		return nil, err
+	}
+
+	if transform != nil {
+		transform.apply(o.Transform())
+	}
+
+	if err := s.graph.AddGameObject(o, parent); err != nil {
+		return nil, err
+	}
+
+	return o, nil
+}
+
 // Graph gets the SceneGraph for this Scene.
 func (s *Scene) Environment() *Environment {
 	return s.environment
 }
 
+// File returns the scene document this scene is loaded from, if any.
+func (s *Scene) File() string {
+	return s.file
+}
+
+// SetFile sets the scene document to load the scene from. The document is
+// loaded before the load function is called.
+func (s *Scene) SetFile(filename string) {
+	s.file = filename
+}
+
 func (s *Scene) SetLoadFunc(fn func() error) {
 	s.loadFunc = fn
 }
@@ -124,3 +161,11 @@ func NewScene(name string) *Scene {
 
 	return s
 }
+
+// NewSceneFromFile creates a new scene which is loaded from a scene document.
+func NewSceneFromFile(name, filename string) *Scene {
+	s := NewScene(name)
+	s.file = filename
+
+	return s
+}
@@ -29,8 +29,16 @@ import (
 	"github.com/haakenlabs/forge/internal/engine/scene/effects"
 	"github.com/haakenlabs/forge/internal/engine/system/input"
 	"github.com/haakenlabs/forge/internal/engine/system/instance"
+	"github.com/haakenlabs/forge/internal/engine/system/time"
 )
 
+// ActionExposure is the input action which adjusts the exposure.
+const ActionExposure = "Exposure"
+
+// exposureSpeed is the change of exposure per second at full input. The
+// exposure keeps responding while the game is paused.
+const exposureSpeed = 1.0
+
 type ControlExposure struct {
 	engine.BaseScriptComponent
 
@@ -61,14 +69,21 @@ func (c *ControlExposure) SetTonemapper(t *effects.Tonemapper) {
 	c.tonemapper = t
 }
 
+// Awake defines the exposure action.
+func (c *ControlExposure) Awake() {
+	input.DefineAction(ActionExposure,
+		engine.KeyBinding(glfw.KeyEqual),
+		engine.KeyBinding(glfw.KeyMinus).WithScale(-1),
+	)
+}
+
 func (c *ControlExposure) Update() {
 	if c.tonemapper == nil {
 		return
 	}
 
-	if input.KeyDown(glfw.KeyMinus) {
-		c.tonemapper.SetExposure(c.tonemapper.Exposure() - 0.05)
-	} else if input.KeyDown(glfw.KeyEqual) {
-		c.tonemapper.SetExposure(c.tonemapper.Exposure() + 0.05)
+	if v := input.Value(ActionExposure); v != 0 {
+		dt := float32(time.UnscaledDeltaTime())
+		c.tonemapper.SetExposure(c.tonemapper.Exposure() + v*exposureSpeed*dt)
 	}
 }
@@ -80,14 +80,7 @@ func NewControlOrbit() *ControlOrbit {
 }
 
 func ControlOrbitComponent(g *engine.GameObject) *ControlOrbit {
-	c := g.Components()
-	for i := range c {
-		if ct, ok := c[i].(*ControlOrbit); ok {
-			return ct
-		}
-	}
-
-	return nil
+	return engine.GetComponent[*ControlOrbit](g)
 }
 
 func (c *ControlOrbit) move() {
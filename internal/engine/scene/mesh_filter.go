@@ -24,6 +24,7 @@ package scene
 
 import (
 	"github.com/haakenlabs/forge/internal/engine"
+	"github.com/haakenlabs/forge/internal/engine/system/asset"
 	"github.com/haakenlabs/forge/internal/engine/system/instance"
 )
 
@@ -34,29 +35,29 @@ type MeshFilter struct {
 	mesh *engine.Mesh
 }
 
+var _ engine.SceneDocumenter = &MeshFilter{}
+
+// meshFilterDocument is the serialized form of a MeshFilter. The mesh is
+// recorded by the name of its asset.
+type meshFilterDocument struct {
+	Mesh string
+}
 
 // NewMeshFilter creates a new MeshFilter component.
 func NewMeshFilter(mesh *engine.Mesh) *MeshFilter {
-	m := &MeshFilter{
-		mesh: mesh,
-	}
+	m := &MeshFilter{}
 
 	m.SetName("MeshFilter")
 	instance.MustAssign(m)
 
+	m.SetMesh(mesh)
+
 	return m
 }
 
 // MeshFilterComponent gets the first occurrence of MeshFilter from the entity.
 func MeshFilterComponent(g *engine.GameObject) *MeshFilter {
-	c := g.Components()
-	for i := range c {
-		if ct, ok := c[i].(*MeshFilter); ok {
-			return ct
-		}
-	}
-
-	return nil
+	return engine.GetComponent[*MeshFilter](g)
 }
 
 // Mesh gets the Mesh associated with this MeshFilter.
@@ -64,7 +65,54 @@ func (m *MeshFilter) Mesh() *engine.Mesh {
 	return m.mesh
 }
 
-// SetMesh sets the Mesh for this MeshFilter.
+// SetMesh sets the Mesh for this MeshFilter. The MeshFilter holds a reference
+// to the Mesh until it is replaced or the MeshFilter is released.
 func (m *MeshFilter) SetMesh(mesh *engine.Mesh) {
+	if m.mesh != nil {
+		asset.Unref(m.mesh)
+	}
+	if mesh != nil {
+		asset.Ref(mesh)
+	}
+
 	m.mesh = mesh
 }
+
+// SceneDocument returns the asset name of the Mesh.
+func (m *MeshFilter) SceneDocument() (interface{}, error) {
+	doc := &meshFilterDocument{}
+
+	if m.mesh != nil {
+		name, err := asset.Name(engine.AssetNameMesh, m.mesh)
+		if err != nil {
+			return nil, err
+		}
+		doc.Mesh = name
+	}
+
+	return doc, nil
+}
+
+// ApplySceneDocument sets the Mesh to the asset named by the document.
+func (m *MeshFilter) ApplySceneDocument(doc interface{}) error {
+	d := doc.(*meshFilterDocument)
+
+	if d.Mesh == "" {
+		m.SetMesh(nil)
+		return nil
+	}
+
+	mesh, err := asset.Get(engine.AssetNameMesh, d.Mesh)
+	if err != nil {
+		return err
+	}
+
+	m.SetMesh(mesh.(*engine.Mesh))
+
+	return nil
+}
+
+// Dealloc drops the reference to the Mesh.
+func (m *MeshFilter) Dealloc() {
+	m.SetMesh(nil)
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/go-gl/gl/v4.3-core/gl"
 
 	"github.com/haakenlabs/forge/internal/engine"
+	"github.com/haakenlabs/forge/internal/engine/system/asset"
 	"github.com/haakenlabs/forge/internal/engine/system/instance"
 )
 
@@ -39,6 +40,18 @@ type MeshRenderer struct {
 }
 
 var _ engine.Renderer = &MeshRenderer{}
+var _ engine.SceneDocumenter = &MeshRenderer{}
+
+// meshRendererDocument is the serialized form of a MeshRenderer. Materials
+// which are assets are recorded by name. Other materials are recorded by the
+// name of their shader, and recreated with it.
+type meshRendererDocument struct {
+	Material   string
+	Shader     string
+	CullFace   bool
+	DepthWrite bool
+	Wireframe  bool
+}
 
 func NewMeshRenderer() *MeshRenderer {
 	c := &MeshRenderer{
@@ -54,51 +67,179 @@ func NewMeshRenderer() *MeshRenderer {
 
 // MeshRenderer Functions
 
+// SetMaterial sets the material to render with. The renderer holds a
+// reference to the material until it is replaced or the renderer is released.
 func (m *MeshRenderer) SetMaterial(material *engine.Material) {
+	if m.material != nil {
+		asset.Unref(m.material)
+	}
+	if material != nil {
+		asset.Ref(material)
+	}
+
 	m.material = material
 }
 
+// SceneDocument returns the settings of the renderer, and the asset names of
+// its material or shader.
+func (m *MeshRenderer) SceneDocument() (interface{}, error) {
+	doc := &meshRendererDocument{
+		CullFace:   m.cullFace,
+		DepthWrite: m.depthWrite,
+		Wireframe:  m.wireframe,
+	}
+
+	if m.material == nil {
+		return doc, nil
+	}
+
+	if name, err := asset.Name(engine.AssetNameMaterial, m.material); err == nil {
+		doc.Material = name
+		return doc, nil
+	}
+
+	if shader := m.material.Shader(); shader != nil {
+		name, err := asset.Name(engine.AssetNameShader, shader)
+		if err != nil {
+			return nil, err
+		}
+		doc.Shader = name
+	}
+
+	return doc, nil
+}
+
+// ApplySceneDocument sets the settings of the renderer, and its material to
+// the asset named by the document, or to a new material with the named
+// shader.
+func (m *MeshRenderer) ApplySceneDocument(doc interface{}) error {
+	d := doc.(*meshRendererDocument)
+
+	m.cullFace = d.CullFace
+	m.depthWrite = d.DepthWrite
+	m.wireframe = d.Wireframe
+
+	switch {
+	case d.Material != "":
+		material, err := asset.Get(engine.AssetNameMaterial, d.Material)
+		if err != nil {
+			return err
+		}
+		m.SetMaterial(material.(*engine.Material))
+	case d.Shader != "":
+		shader, err := asset.Get(engine.AssetNameShader, d.Shader)
+		if err != nil {
+			return err
+		}
+		material := engine.NewMaterial()
+		material.SetShader(shader.(*engine.Shader))
+		m.SetMaterial(material)
+	default:
+		m.SetMaterial(nil)
+	}
+
+	return nil
+}
+
+// Dealloc drops the reference to the material.
+func (m *MeshRenderer) Dealloc() {
+	m.SetMaterial(nil)
+}
+
 func (m *MeshRenderer) GetMaterial() *engine.Material {
 	return m.material
 }
 
+// meshDraw is a part of a mesh drawn by the renderer.
+type meshDraw struct {
+	mesh *engine.Mesh
+	engine.MeshDraw
+}
+
+// draws returns the parts of the meshes of the game object to draw. Sub-meshes
+// without a material are drawn with the material of the renderer.
+func (m *MeshRenderer) draws() []meshDraw {
+	if m.GameObject() == nil {
+		return nil
+	}
+
+	var draws []meshDraw
+	components := m.GameObject().Components()
+	for i := range components {
+		if meshFilter, ok := components[i].(*MeshFilter); ok {
+			if mesh := meshFilter.Mesh(); mesh != nil {
+				for _, d := range mesh.Draws(m.material) {
+					draws = append(draws, meshDraw{mesh: mesh, MeshDraw: d})
+				}
+			}
+		}
+	}
+
+	return draws
+}
+
+// Render draws the meshes of the game object. Consecutive draws sharing a
+// material are drawn with a single bind of the material.
 func (m *MeshRenderer) Render(camera *engine.Camera) {
 	if !m.enabled && m.material == nil {
 		return
 	}
 
-	m.material.Bind()
+	draws := m.draws()
+
+	for start := 0; start < len(draws); {
+		material := draws[start].Material
+
+		end := start + 1
+		for end < len(draws) && draws[end].Material == material {
+			end++
+		}
+
+		if material != nil {
+			shader := m.bindMaterial(material, camera)
+			m.renderDraws(shader, camera, draws[start:end])
+			material.Unbind()
+		}
+
+		start = end
+	}
+}
 
-	if m.material.SupportsDeferredPath() {
+// bindMaterial binds the material, and returns the shader it was bound with.
+func (m *MeshRenderer) bindMaterial(material *engine.Material, camera *engine.Camera) *engine.Shader {
+	// Skinned meshes are drawn with the skinning variant of the shader.
+	shader := material.Shader()
+	if skin := engine.SkinComponent(m.GameObject()); skin != nil && shader != nil {
+		if v := shader.Variant(engine.ShaderVariantSkinned); v != nil {
+			shader = v
+		}
+	}
+
+	material.BindShader(shader)
+
+	if material.SupportsDeferredPath() {
 		if camera.ActiveRenderPath() == engine.RenderPathForward {
-			m.material.Shader().SetSubroutine(engine.ShaderComponentFragment, "forward_pass")
+			shader.SetSubroutine(engine.ShaderComponentFragment, "forward_pass")
 		} else {
-			m.material.Shader().SetSubroutine(engine.ShaderComponentFragment, "deferred_pass_geometry")
+			shader.SetSubroutine(engine.ShaderComponentFragment, "deferred_pass_geometry")
 		}
 	}
 
-	m.RenderShader(m.material.Shader(), camera)
-
-	m.material.Unbind()
+	return shader
 }
 
+// RenderShader draws the meshes of the game object with the shader, ignoring
+// their materials.
 func (m *MeshRenderer) RenderShader(shader *engine.Shader, camera *engine.Camera) {
-	if shader == nil && m.GameObject() == nil {
+	if shader == nil {
 		return
 	}
 
-	// FIXME: Move this somewhere out of the render loop
-	var meshes []*engine.Mesh
-	components := m.GameObject().Components()
-	for i := range components {
-		if meshFilter, ok := components[i].(*MeshFilter); ok {
-			if mesh := meshFilter.Mesh(); mesh != nil {
-				meshes = append(meshes, mesh)
-			}
-		}
-	}
+	m.renderDraws(shader, camera, m.draws())
+}
 
-	if len(meshes) == 0 {
+func (m *MeshRenderer) renderDraws(shader *engine.Shader, camera *engine.Camera, draws []meshDraw) {
+	if shader == nil || len(draws) == 0 {
 		return
 	}
 
@@ -107,6 +248,9 @@ func (m *MeshRenderer) RenderShader(shader *engine.Shader, camera *engine.Camera
 	shader.SetUniform("v_projection_matrix", camera.ProjectionMatrix())
 	shader.SetUniform("v_normal_matrix", camera.NormalMatrix())
 	shader.SetUniform("f_camera", camera.CameraPosition())
+	if skin := engine.SkinComponent(m.GameObject()); skin != nil {
+		shader.SetUniform("v_joint_matrices", skin.Palette())
+	}
 
 	if !m.cullFace {
 		gl.Disable(gl.CULL_FACE)
@@ -118,18 +262,16 @@ func (m *MeshRenderer) RenderShader(shader *engine.Shader, camera *engine.Camera
 		gl.PolygonMode(gl.FRONT_AND_BACK, gl.LINE)
 	}
 
-	for i := range meshes {
-		meshes[i].Bind()
-
-		if meshes[i].Indexed() {
-			gl.DrawElements(gl.TRIANGLES, int32(len(meshes[i].Triangles())), gl.UNSIGNED_INT, nil)
-		} else {
-			gl.DrawArrays(gl.TRIANGLES, 0, int32(len(meshes[i].Vertices())))
+	var bound *engine.Mesh
+	for i := range draws {
+		if draws[i].mesh != bound {
+			bound = draws[i].mesh
+			bound.Bind()
 		}
 
-		meshes[i].Unbind()
-
+		bound.DrawPart(draws[i].MeshDraw)
 	}
+	bound.Unbind()
 
 	if m.wireframe {
 		gl.PolygonMode(gl.FRONT_AND_BACK, gl.FILL)
@@ -0,0 +1,61 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package scene
+
+import (
+	"testing"
+
+	"github.com/haakenlabs/forge/internal/engine"
+)
+
+func TestMeshRenderer_Draws(t *testing.T) {
+	a := newHeadlessApp(t)
+	defer a.Teardown()
+
+	red, fallback := engine.NewMaterial(), engine.NewMaterial()
+
+	mesh := engine.NewMesh()
+	mesh.SetSubMeshes([]engine.SubMesh{
+		{Name: "a", Material: red, Offset: 0, Count: 3},
+		{Name: "b", Offset: 3, Count: 3},
+	})
+
+	renderer := NewMeshRenderer()
+	renderer.SetMaterial(fallback)
+
+	object := engine.NewGameObject("mesh")
+	object.AddComponent(renderer)
+	object.AddComponent(NewMeshFilter(mesh))
+
+	// Sub-meshes without a material use the material of the renderer.
+	draws := renderer.draws()
+	if len(draws) != 2 {
+		t.Fatalf("expected 2 draws, got: %d", len(draws))
+	}
+	if draws[0].mesh != mesh || draws[0].SubMesh != 0 || draws[0].Material != red {
+		t.Errorf("expected sub-mesh 0 with red, got: %+v", draws[0])
+	}
+	if draws[1].mesh != mesh || draws[1].SubMesh != 1 || draws[1].Material != fallback {
+		t.Errorf("expected sub-mesh 1 with the renderer material, got: %+v", draws[1])
+	}
+}
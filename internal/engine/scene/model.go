@@ -0,0 +1,193 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package scene
+
+import (
+	"fmt"
+
+	"github.com/haakenlabs/forge/internal/engine"
+	"github.com/haakenlabs/forge/internal/engine/system/asset"
+	"github.com/haakenlabs/forge/internal/engine/system/instance"
+)
+
+// ModelInstance is a component holding a reference to the model a
+// GameObject hierarchy was created from, so that the model is not unloaded
+// while the hierarchy exists.
+type ModelInstance struct {
+	engine.BaseComponent
+
+	model *engine.Model
+}
+
+var _ engine.SceneDocumenter = &ModelInstance{}
+
+// modelInstanceDocument is the serialized form of a ModelInstance. The model
+// is recorded by the name of its asset.
+type modelInstanceDocument struct {
+	Model string
+}
+
+// NewModelInstance creates a new ModelInstance component.
+func NewModelInstance(model *engine.Model) *ModelInstance {
+	m := &ModelInstance{}
+
+	m.SetName("ModelInstance")
+	instance.MustAssign(m)
+
+	m.SetModel(model)
+
+	return m
+}
+
+// ModelInstanceComponent gets the first occurrence of ModelInstance from the
+// entity.
+func ModelInstanceComponent(g *engine.GameObject) *ModelInstance {
+	return engine.GetComponent[*ModelInstance](g)
+}
+
+// Model gets the Model of this ModelInstance.
+func (m *ModelInstance) Model() *engine.Model {
+	return m.model
+}
+
+// SetModel sets the Model of this ModelInstance. The ModelInstance holds a
+// reference to the Model until it is replaced or the ModelInstance is
+// released.
+func (m *ModelInstance) SetModel(model *engine.Model) {
+	if m.model != nil {
+		asset.Unref(m.model)
+	}
+	if model != nil {
+		asset.Ref(model)
+	}
+
+	m.model = model
+}
+
+// SceneDocument returns the asset name of the Model.
+func (m *ModelInstance) SceneDocument() (interface{}, error) {
+	doc := &modelInstanceDocument{}
+
+	if m.model != nil {
+		name, err := asset.Name(engine.AssetNameModel, m.model)
+		if err != nil {
+			return nil, err
+		}
+		doc.Model = name
+	}
+
+	return doc, nil
+}
+
+// ApplySceneDocument sets the Model to the asset named by the document.
+func (m *ModelInstance) ApplySceneDocument(doc interface{}) error {
+	d := doc.(*modelInstanceDocument)
+
+	if d.Model == "" {
+		m.SetModel(nil)
+		return nil
+	}
+
+	model, err := asset.Get(engine.AssetNameModel, d.Model)
+	if err != nil {
+		return err
+	}
+
+	m.SetModel(model.(*engine.Model))
+
+	return nil
+}
+
+// Dealloc drops the reference to the Model.
+func (m *ModelInstance) Dealloc() {
+	m.SetModel(nil)
+}
+
+// CreateModel creates a GameObject hierarchy from a model. Each node becomes
+// a GameObject. A node with one mesh is given a MeshFilter and MeshRenderer,
+// and a node with several meshes is given a child object for each. Meshes of
+// nodes with a skeleton are also given a Skin, whose joints are found below
+// the model. The root object is given a ModelInstance referencing the model.
+// Invalid node indices, and nodes which were already created, are skipped.
+func CreateModel(name string, model *engine.Model) *engine.GameObject {
+	object := engine.NewGameObject(name)
+	object.AddComponent(NewModelInstance(model))
+
+	nodes := model.Nodes()
+	created := make([]bool, len(nodes))
+
+	for _, i := range model.Roots() {
+		if child := createModelNode(object, nodes, i, created); child != nil {
+			object.AddChild(child)
+		}
+	}
+
+	return object
+}
+
+func createModelNode(root *engine.GameObject, nodes []engine.ModelNode, index int, created []bool) *engine.GameObject {
+	if index < 0 || index >= len(nodes) || created[index] {
+		return nil
+	}
+	created[index] = true
+
+	n := &nodes[index]
+
+	object := engine.NewGameObject(n.Name)
+	object.Transform().SetPosition(n.Position)
+	object.Transform().SetRotation(n.Rotation)
+	object.Transform().SetScale(n.Scale)
+
+	if len(n.Meshes) == 1 {
+		addModelMesh(object, n.Meshes[0], n.Materials[0], n.Skeleton, root)
+	} else {
+		for i := range n.Meshes {
+			child := engine.NewGameObject(fmt.Sprintf("%s.%d", n.Name, i))
+			addModelMesh(child, n.Meshes[i], n.Materials[i], n.Skeleton, root)
+			object.AddChild(child)
+		}
+	}
+
+	for _, c := range n.Children {
+		if child := createModelNode(root, nodes, c, created); child != nil {
+			object.AddChild(child)
+		}
+	}
+
+	return object
+}
+
+func addModelMesh(object *engine.GameObject, mesh *engine.Mesh, material *engine.Material, skeleton *engine.Skeleton, root *engine.GameObject) {
+	meshRenderer := NewMeshRenderer()
+	meshRenderer.SetMaterial(material)
+
+	object.AddComponent(meshRenderer)
+	object.AddComponent(NewMeshFilter(mesh))
+
+	if skeleton != nil {
+		skin := engine.NewSkin()
+		skin.SetSkeleton(skeleton)
+		skin.SetRoot(root)
+		object.AddComponent(skin)
+	}
+}
@@ -0,0 +1,85 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package scene
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/haakenlabs/forge/internal/engine"
+)
+
+func newHeadlessApp(t testing.TB) *engine.App {
+	a := engine.NewApp(&engine.AppConfig{
+		Name:     "test",
+		Headless: true,
+	})
+
+	if err := a.Setup(); err != nil {
+		t.Fatal(err)
+	}
+
+	return a
+}
+
+func TestCreateModel_InvalidNodes(t *testing.T) {
+	a := newHeadlessApp(t)
+	defer a.Teardown()
+
+	node := func(name string, children ...int) engine.ModelNode {
+		return engine.ModelNode{Name: name, Rotation: mgl32.QuatIdent(), Scale: mgl32.Vec3{1, 1, 1}, Children: children}
+	}
+
+	// Node 1 lists an invalid child and its own ancestor, and node 3 is
+	// listed as a root twice.
+	model := engine.NewModel()
+	model.SetNodes([]engine.ModelNode{
+		node("root", 1),
+		node("child", 0, 2, 5, -1),
+		node("leaf"),
+		node("other"),
+	}, []int{0, 3, 3, 7})
+
+	object := CreateModel("model", model)
+
+	if instance := ModelInstanceComponent(object); instance == nil || instance.Model() != model {
+		t.Error("expected ModelInstance of the model on the root")
+	}
+	if n := engine.GetAsset().RefCount(model); n != 1 {
+		t.Error("RefCount(model) expected 1, got:", n)
+	}
+
+	roots := object.Children()
+	if len(roots) != 2 || roots[0].Name() != "root" || roots[1].Name() != "other" {
+		t.Fatalf("expected roots root and other, got: %v", roots)
+	}
+
+	child := roots[0].Children()
+	if len(child) != 1 || child[0].Name() != "child" {
+		t.Fatalf("expected child of root, got: %v", child)
+	}
+	if leaf := child[0].Children(); len(leaf) != 1 || leaf[0].Name() != "leaf" {
+		t.Errorf("expected only leaf as child, got: %v", leaf)
+	}
+}
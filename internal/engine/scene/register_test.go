@@ -0,0 +1,235 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package scene
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/haakenlabs/forge/internal/engine"
+)
+
+// trackAsset adds the object to the handler of the kind without allocating
+// it, since headless apps cannot upload meshes or shaders.
+func trackAsset(t *testing.T, kind, name string, object engine.Object) {
+	h, err := engine.GetAsset().GetHandler(kind)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var base *engine.BaseAssetHandler
+	switch v := h.(type) {
+	case *engine.MeshHandler:
+		base = &v.BaseAssetHandler
+	case *engine.ShaderHandler:
+		base = &v.BaseAssetHandler
+	default:
+		t.Fatalf("unexpected handler %T", h)
+	}
+
+	base.Mu.Lock()
+	base.Items[name] = object.ID()
+	base.Mu.Unlock()
+}
+
+func TestSaveScene_Builtins(t *testing.T) {
+	for _, format := range []engine.SceneFormat{engine.SceneFormatJSON, engine.SceneFormatYAML} {
+		a := newHeadlessApp(t)
+
+		mesh := engine.NewMesh()
+		trackAsset(t, engine.AssetNameMesh, "test/box", mesh)
+		shader := engine.NewShader()
+		trackAsset(t, engine.AssetNameShader, "test/shader", shader)
+
+		src := engine.NewScene("src")
+		if err := src.Load(); err != nil {
+			t.Fatal(err)
+		}
+
+		camera := CreateCamera("camera", true, engine.RenderPathForward)
+		engine.CameraComponent(camera).SetFov(1)
+
+		box := engine.NewGameObject("box")
+		material := engine.NewMaterial()
+		material.SetShader(shader)
+		renderer := NewMeshRenderer()
+		renderer.SetMaterial(material)
+		renderer.SetWireframeEnabled(true)
+		box.AddComponent(renderer)
+		box.AddComponent(NewMeshFilter(mesh))
+
+		for _, o := range []*engine.GameObject{camera, box} {
+			if err := src.Graph().AddGameObject(o, nil); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		buf := &bytes.Buffer{}
+		if err := engine.SaveScene(buf, src, format); err != nil {
+			t.Fatal(err)
+		}
+
+		doc, err := engine.DecodeSceneDocument(buf, format)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		dst := engine.NewScene("dst")
+		if err := dst.Load(); err != nil {
+			t.Fatal(err)
+		}
+		if err := dst.LoadDocument(doc); err != nil {
+			t.Fatal(err)
+		}
+
+		var c *engine.Camera
+		var filter *MeshFilter
+		var r *MeshRenderer
+		for _, component := range dst.Graph().Components() {
+			switch v := component.(type) {
+			case *engine.Camera:
+				c = v
+			case *MeshFilter:
+				filter = v
+			case *MeshRenderer:
+				r = v
+			}
+		}
+
+		if c == nil || filter == nil || r == nil {
+			t.Fatalf("format %d: components not restored: %v %v %v", format, c, filter, r)
+		}
+		if !c.HDR() || c.RenderPath() != engine.RenderPathForward || c.Fov() != 1 {
+			t.Errorf("format %d: camera mismatch: hdr %v path %d fov %f", format, c.HDR(), c.RenderPath(), c.Fov())
+		}
+		if filter.Mesh() != mesh {
+			t.Errorf("format %d: expected mesh %d, got: %v", format, mesh.ID(), filter.Mesh())
+		}
+		if m := r.GetMaterial(); m == nil || m.Shader() != shader {
+			t.Errorf("format %d: expected material with shader %d, got: %v", format, shader.ID(), m)
+		}
+		if !r.WireframeEnabled() || !r.CullFaceEnabled() {
+			t.Errorf("format %d: renderer settings mismatch", format)
+		}
+
+		a.Teardown()
+	}
+}
+
+func TestSaveScene_UntrackedMesh(t *testing.T) {
+	a := newHeadlessApp(t)
+	defer a.Teardown()
+
+	s := engine.NewScene("test")
+	if err := s.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	o := engine.NewGameObject("box")
+	o.AddComponent(NewMeshFilter(engine.NewMesh()))
+	if err := s.Graph().AddGameObject(o, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := engine.SaveScene(&bytes.Buffer{}, s, engine.SceneFormatJSON); err == nil {
+		t.Error("expected error saving a mesh which is not an asset")
+	}
+}
+
+func TestSaveScene_ModelInstance(t *testing.T) {
+	a := newHeadlessApp(t)
+	defer a.Teardown()
+
+	meshes := []*engine.Mesh{engine.NewMesh(), engine.NewMesh()}
+	material := engine.NewMaterial()
+
+	model := engine.NewModel()
+	model.SetNodes([]engine.ModelNode{
+		{Name: "body", Rotation: mgl32.QuatIdent(), Scale: mgl32.Vec3{1, 1, 1}, Meshes: meshes, Materials: []*engine.Material{material, material}},
+	}, []int{0})
+
+	h, err := engine.GetAsset().GetHandler(engine.AssetNameModel)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := h.(*engine.ModelHandler).Add("robot.gltf", model); err != nil {
+		t.Fatal(err)
+	}
+
+	src := engine.NewScene("src")
+	if err := src.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if err := src.Graph().AddGameObject(CreateModel("robot", model), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := engine.SaveScene(buf, src, engine.SceneFormatJSON); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"robot.gltf", "robot.gltf#mesh0", "robot.gltf#mesh1", "robot.gltf#material0"} {
+		if !bytes.Contains(buf.Bytes(), []byte(`"`+name+`"`)) {
+			t.Errorf("expected %s in the scene file", name)
+		}
+	}
+
+	doc, err := engine.DecodeSceneDocument(buf, engine.SceneFormatJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dst := engine.NewScene("dst")
+	if err := dst.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if err := dst.LoadDocument(doc); err != nil {
+		t.Fatal(err)
+	}
+
+	var instance *ModelInstance
+	var filters []*MeshFilter
+	var renderers []*MeshRenderer
+	for _, component := range dst.Graph().Components() {
+		switch v := component.(type) {
+		case *ModelInstance:
+			instance = v
+		case *MeshFilter:
+			filters = append(filters, v)
+		case *MeshRenderer:
+			renderers = append(renderers, v)
+		}
+	}
+
+	if instance == nil || instance.Model() != model {
+		t.Errorf("expected ModelInstance of the model, got: %v", instance)
+	}
+	if len(filters) != 2 || filters[0].Mesh() != meshes[0] || filters[1].Mesh() != meshes[1] {
+		t.Errorf("expected mesh filters of the model meshes, got: %v", filters)
+	}
+	if len(renderers) != 2 || renderers[0].GetMaterial() != material || renderers[1].GetMaterial() != material {
+		t.Errorf("expected mesh renderers of the model material, got: %v", renderers)
+	}
+}
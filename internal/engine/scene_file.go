@@ -0,0 +1,583 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"gopkg.in/yaml.v2"
+)
+
+// SceneFormat is an encoding used by scene documents.
+type SceneFormat int
+
+const (
+	SceneFormatJSON SceneFormat = iota
+	SceneFormatYAML
+)
+
+// ErrSceneFormat reports that a scene document format is not supported.
+type ErrSceneFormat string
+
+func (e ErrSceneFormat) Error() string {
+	return "scene: unsupported document format: " + string(e)
+}
+
+// ErrComponentNotRegistered reports that a component type is not registered.
+type ErrComponentNotRegistered string
+
+func (e ErrComponentNotRegistered) Error() string {
+	return "scene: component type not registered: " + string(e)
+}
+
+// ErrComponentRegistered reports that a component type is already registered.
+type ErrComponentRegistered string
+
+func (e ErrComponentRegistered) Error() string {
+	return "scene: component type already registered: " + string(e)
+}
+
+// SceneDocument is the serialized form of a Scene.
+type SceneDocument struct {
+	Name    string               `json:"name" yaml:"name"`
+	Objects []GameObjectDocument `json:"objects" yaml:"objects"`
+}
+
+// GameObjectDocument is the serialized form of a GameObject and its children.
+// Prefab instances are stored by the name of their prefab and their
+// overrides, without components or children.
+type GameObjectDocument struct {
+	Name       string               `json:"name" yaml:"name"`
+	Active     bool                 `json:"active" yaml:"active"`
+	Tags       []string             `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Layer      *uint32              `json:"layer,omitempty" yaml:"layer,omitempty"`
+	Transform  TransformDocument    `json:"transform" yaml:"transform"`
+	Components []ComponentDocument  `json:"components,omitempty" yaml:"components,omitempty"`
+	Children   []GameObjectDocument `json:"children,omitempty" yaml:"children,omitempty"`
+	Prefab     string               `json:"prefab,omitempty" yaml:"prefab,omitempty"`
+	Overrides  []PrefabOverride     `json:"overrides,omitempty" yaml:"overrides,omitempty"`
+}
+
+// TransformDocument is the serialized form of a Transform. Rotation is a
+// quaternion stored as x, y, z, w.
+type TransformDocument struct {
+	Position mgl32.Vec3 `json:"position" yaml:"position"`
+	Rotation mgl32.Vec4 `json:"rotation" yaml:"rotation"`
+	Scale    mgl32.Vec3 `json:"scale" yaml:"scale"`
+}
+
+// ComponentDocument is the serialized form of a Component. Fields holds the
+// exported fields of the component by name.
+type ComponentDocument struct {
+	Type   string                 `json:"type" yaml:"type"`
+	Fields map[string]interface{} `json:"fields,omitempty" yaml:"fields,omitempty"`
+}
+
+// SceneDocumenter is implemented by components whose state is not held in
+// exported fields, such as references to assets. SceneDocument returns a
+// pointer to a struct whose exported fields are recorded in place of those of
+// the component. ApplySceneDocument restores the component from a struct of
+// the same type, read from a scene document.
+type SceneDocumenter interface {
+	SceneDocument() (interface{}, error)
+	ApplySceneDocument(doc interface{}) error
+}
+
+type componentType struct {
+	name    string
+	typ     reflect.Type
+	factory func() Component
+}
+
+var (
+	componentTypes   = make(map[string]*componentType)
+	componentNames   = make(map[reflect.Type]string)
+	componentTypesMu = &sync.RWMutex{}
+)
+
+// RegisterComponent registers a component type so that it can be written to
+// and read from scene documents. The component argument is only used for its
+// type, so a typed nil pointer may be given. The factory must return a new
+// component which has been assigned an instance ID. It panics if the name or
+// type has already been registered.
+func RegisterComponent(name string, component Component, factory func() Component) {
+	componentTypesMu.Lock()
+	defer componentTypesMu.Unlock()
+
+	typ := reflect.TypeOf(component)
+
+	if _, dup := componentTypes[name]; dup {
+		panic(ErrComponentRegistered(name))
+	}
+	if _, dup := componentNames[typ]; dup {
+		panic(ErrComponentRegistered(typ.String()))
+	}
+
+	componentTypes[name] = &componentType{
+		name:    name,
+		typ:     typ,
+		factory: factory,
+	}
+	componentNames[typ] = name
+}
+
+// ComponentTypeName returns the registered name of the component's type.
+func ComponentTypeName(component Component) (string, bool) {
+	componentTypesMu.RLock()
+	defer componentTypesMu.RUnlock()
+
+	name, ok := componentNames[reflect.TypeOf(component)]
+
+	return name, ok
+}
+
+// NewComponentByName creates a new component of the registered type name.
+func NewComponentByName(name string) (Component, error) {
+	componentTypesMu.RLock()
+	t, ok := componentTypes[name]
+	componentTypesMu.RUnlock()
+
+	if !ok {
+		return nil, ErrComponentNotRegistered(name)
+	}
+
+	return t.factory(), nil
+}
+
+// SceneFormatFromFilename returns the document format for the given filename
+// based on its extension.
+func SceneFormatFromFilename(filename string) (SceneFormat, error) {
+	switch ext := strings.ToLower(filepath.Ext(filename)); ext {
+	case ".json":
+		return SceneFormatJSON, nil
+	case ".yaml", ".yml":
+		return SceneFormatYAML, nil
+	default:
+		return 0, ErrSceneFormat(ext)
+	}
+}
+
+// DecodeSceneDocument reads a scene document from the reader.
+func DecodeSceneDocument(r io.Reader, format SceneFormat) (*SceneDocument, error) {
+	doc := &SceneDocument{}
+
+	if err := decodeDocument(r, format, doc); err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+// decodeDocument reads a document in the format from the reader into v.
+func decodeDocument(r io.Reader, format SceneFormat, v interface{}) error {
+	switch format {
+	case SceneFormatJSON:
+		return json.NewDecoder(r).Decode(v)
+	case SceneFormatYAML:
+		return yaml.NewDecoder(r).Decode(v)
+	default:
+		return ErrSceneFormat(fmt.Sprintf("%d", format))
+	}
+}
+
+// EncodeSceneDocument writes a scene document to the writer.
+func EncodeSceneDocument(w io.Writer, doc *SceneDocument, format SceneFormat) error {
+	switch format {
+	case SceneFormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "    ")
+
+		return enc.Encode(doc)
+	case SceneFormatYAML:
+		enc := yaml.NewEncoder(w)
+		if err := enc.Encode(doc); err != nil {
+			return err
+		}
+
+		return enc.Close()
+	default:
+		return ErrSceneFormat(fmt.Sprintf("%d", format))
+	}
+}
+
+// SaveScene writes the scene to the writer.
+func SaveScene(w io.Writer, s *Scene, format SceneFormat) error {
+	doc, err := s.Document()
+	if err != nil {
+		return err
+	}
+
+	return EncodeSceneDocument(w, doc, format)
+}
+
+// SaveSceneToFile writes the scene to a file. The format is derived from the
+// extension of the filename.
+func SaveSceneToFile(filename string, s *Scene) error {
+	format, err := SceneFormatFromFilename(filename)
+	if err != nil {
+		return err
+	}
+
+	w, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	return SaveScene(w, s, format)
+}
+
+// Document builds a scene document from the current contents of the scene.
+func (s *Scene) Document() (*SceneDocument, error) {
+	doc := &SceneDocument{
+		Name: s.name,
+	}
+
+	if s.graph == nil {
+		return doc, nil
+	}
+
+	for _, o := range s.graph.Children(s.graph.root) {
+		od, err := gameObjectDocument(s.graph, o)
+		if err != nil {
+			return nil, err
+		}
+
+		doc.Objects = append(doc.Objects, od)
+	}
+
+	return doc, nil
+}
+
+// LoadDocument creates the objects described by the document and adds them
+// to the scene graph.
+func (s *Scene) LoadDocument(doc *SceneDocument) error {
+	for i := range doc.Objects {
+		o, err := newGameObjectFromDocument(&doc.Objects[i])
+		if err != nil {
+			return err
+		}
+
+		if err := s.graph.AddGameObject(o, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// loadFile reads the scene file and loads its contents into the scene.
+func (s *Scene) loadFile() error {
+	format, err := SceneFormatFromFilename(s.file)
+	if err != nil {
+		return err
+	}
+
+	r, err := NewResource(s.file)
+	if err != nil {
+		return err
+	}
+	if err := GetAsset().ReadResource(r); err != nil {
+		return err
+	}
+
+	doc, err := DecodeSceneDocument(r.Reader(), format)
+	if err != nil {
+		return err
+	}
+
+	return s.LoadDocument(doc)
+}
+
+func gameObjectDocument(graph *SceneGraph, o *GameObject) (GameObjectDocument, error) {
+	t := o.Transform()
+	r := t.Rotation()
+
+	doc := GameObjectDocument{
+		Name:   o.Name(),
+		Active: o.Active(),
+		Tags:   o.Tags(),
+		Transform: TransformDocument{
+			Position: t.Position(),
+			Rotation: r.V.Vec4(r.W),
+			Scale:    t.Scale(),
+		},
+	}
+
+	if layer := o.Layer(); layer != LayerDefault {
+		doc.Layer = &layer
+	}
+
+	// Prefab instances are recorded by prefab name and overrides.
+	if instance := PrefabInstanceComponent(o); instance != nil && instance.Prefab() != nil {
+		doc.Prefab = instance.Prefab().Name()
+		doc.Overrides = instance.Overrides()
+		return doc, nil
+	}
+
+	// The first component is always the transform.
+	components := o.Components()
+	for i := 1; i < len(components); i++ {
+		cd, err := componentDocument(components[i])
+		if err != nil {
+			return doc, err
+		}
+
+		doc.Components = append(doc.Components, cd)
+	}
+
+	for _, c := range graph.Children(o) {
+		cd, err := gameObjectDocument(graph, c)
+		if err != nil {
+			return doc, err
+		}
+
+		doc.Children = append(doc.Children, cd)
+	}
+
+	return doc, nil
+}
+
+func newGameObjectFromDocument(doc *GameObjectDocument) (*GameObject, error) {
+	if doc.Prefab != "" {
+		return newPrefabInstanceFromDocument(doc)
+	}
+
+	o := NewGameObject(doc.Name)
+
+	for i := range doc.Components {
+		c, err := newComponentFromDocument(&doc.Components[i])
+		if err != nil {
+			return nil, err
+		}
+
+		o.AddComponent(c)
+	}
+
+	for i := range doc.Children {
+		c, err := newGameObjectFromDocument(&doc.Children[i])
+		if err != nil {
+			return nil, err
+		}
+
+		o.AddChild(c)
+	}
+
+	doc.apply(o)
+
+	return o, nil
+}
+
+// newPrefabInstanceFromDocument instantiates the prefab named by the document,
+// and applies the overrides of the document.
+func newPrefabInstanceFromDocument(doc *GameObjectDocument) (*GameObject, error) {
+	h, err := GetAsset().GetHandler(AssetNamePrefab)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := h.(*PrefabHandler).Get(doc.Prefab)
+	if err != nil {
+		return nil, err
+	}
+
+	o, err := p.instantiate()
+	if err != nil {
+		return nil, err
+	}
+	o.SetName(doc.Name)
+
+	instance := PrefabInstanceComponent(o)
+	for _, v := range doc.Overrides {
+		if err := instance.SetOverride(v.Path, v.Component, v.Field, v.Value); err != nil {
+			return nil, err
+		}
+	}
+
+	doc.apply(o)
+
+	return o, nil
+}
+
+// apply sets the transform, tags, layer and active state of the game object.
+func (d *GameObjectDocument) apply(o *GameObject) {
+	d.Transform.apply(o.Transform())
+
+	for _, tag := range d.Tags {
+		o.AddTag(tag)
+	}
+	if d.Layer != nil {
+		o.SetLayer(*d.Layer)
+	}
+
+	o.SetActive(d.Active)
+}
+
+// apply sets the position, rotation and scale of the transform.
+func (d *TransformDocument) apply(t Transform) {
+	t.SetPosition(d.Position)
+	t.SetRotation(mgl32.Quat{W: d.Rotation.W(), V: d.Rotation.Vec3()})
+	t.SetScale(d.Scale)
+}
+
+func componentDocument(c Component) (ComponentDocument, error) {
+	name, ok := ComponentTypeName(c)
+	if !ok {
+		return ComponentDocument{}, ErrComponentNotRegistered(reflect.TypeOf(c).String())
+	}
+
+	doc := ComponentDocument{
+		Type:   name,
+		Fields: make(map[string]interface{}),
+	}
+
+	var src interface{} = c
+	if d, ok := c.(SceneDocumenter); ok {
+		var err error
+		if src, err = d.SceneDocument(); err != nil {
+			return doc, fmt.Errorf("scene: component %s: %v", name, err)
+		}
+	}
+
+	v := reflect.Indirect(reflect.ValueOf(src))
+	if v.Kind() != reflect.Struct {
+		return doc, nil
+	}
+
+	for i := 0; i < v.NumField(); i++ {
+		if f := v.Type().Field(i); serializableField(f) {
+			doc.Fields[f.Name] = v.Field(i).Interface()
+		}
+	}
+
+	return doc, nil
+}
+
+func newComponentFromDocument(doc *ComponentDocument) (Component, error) {
+	c, err := NewComponentByName(doc.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := setComponentFields(c, doc.Type, doc.Fields); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// setComponentFields sets the fields of the component to the document values.
+// Fields of components implementing SceneDocumenter are set on their document,
+// which is then applied to the component.
+func setComponentFields(c Component, typeName string, fields map[string]interface{}) error {
+	d, ok := c.(SceneDocumenter)
+	if !ok {
+		return setDocumentFields(c, typeName, fields)
+	}
+
+	doc, err := d.SceneDocument()
+	if err != nil {
+		return fmt.Errorf("scene: component %s: %v", typeName, err)
+	}
+	if err := setDocumentFields(doc, typeName, fields); err != nil {
+		return err
+	}
+	if err := d.ApplySceneDocument(doc); err != nil {
+		return fmt.Errorf("scene: component %s: %v", typeName, err)
+	}
+
+	return nil
+}
+
+// setDocumentFields sets the exported fields of the struct pointed to by dst
+// to the document values.
+func setDocumentFields(dst interface{}, typeName string, fields map[string]interface{}) error {
+	v := reflect.Indirect(reflect.ValueOf(dst))
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	for name, value := range fields {
+		f, ok := v.Type().FieldByName(name)
+		if !ok || !serializableField(f) {
+			return fmt.Errorf("scene: component %s has no field %s", typeName, name)
+		}
+
+		// Values are decoded generically, so round trip them through JSON to
+		// convert them to the type of the field.
+		data, err := json.Marshal(normalizeDocumentValue(value))
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(data, v.FieldByIndex(f.Index).Addr().Interface()); err != nil {
+			return fmt.Errorf("scene: component %s field %s: %v", typeName, name, err)
+		}
+	}
+
+	return nil
+}
+
+// serializableField reports if the struct field should be recorded in a scene
+// document. Only exported data fields are recorded. Fields may be excluded with
+// the tag `scene:"-"`.
+func serializableField(f reflect.StructField) bool {
+	if f.PkgPath != "" || f.Anonymous || f.Tag.Get("scene") == "-" {
+		return false
+	}
+
+	switch f.Type.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return false
+	}
+
+	return true
+}
+
+// normalizeDocumentValue converts maps decoded from YAML, which are keyed by
+// interface{}, into maps keyed by string so they can be encoded as JSON.
+func normalizeDocumentValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key := range v {
+			m[fmt.Sprint(key)] = normalizeDocumentValue(v[key])
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(v))
+		for i := range v {
+			s[i] = normalizeDocumentValue(v[i])
+		}
+		return s
+	default:
+		return v
+	}
+}
@@ -0,0 +1,170 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+type docComponent struct {
+	BaseComponent
+
+	Speed  float32
+	Offset mgl32.Vec3
+	Label  string
+	Tags   []string
+
+	hidden int
+}
+
+func init() {
+	RegisterComponent("docComponent", (*docComponent)(nil), func() Component {
+		c := &docComponent{}
+		GetInstance().MustAssign(c)
+
+		return c
+	})
+}
+
+func TestScene_Document(t *testing.T) {
+	for _, format := range []SceneFormat{SceneFormatJSON, SceneFormatYAML} {
+		a := newHeadlessApp(t)
+
+		src := NewScene("src")
+		if err := src.Load(); err != nil {
+			t.Fatal(err)
+		}
+
+		c := &docComponent{Speed: 2.5, Offset: mgl32.Vec3{1, 2, 3}, Label: "doc", Tags: []string{"a", "b"}, hidden: 7}
+		GetInstance().MustAssign(c)
+
+		parent := NewGameObject("parent")
+		parent.Transform().SetPosition(mgl32.Vec3{4, 5, 6})
+		parent.Transform().SetRotation(mgl32.QuatRotate(1, mgl32.Vec3{0, 1, 0}))
+		parent.AddComponent(c)
+		parent.AddTag("player")
+
+		child := NewGameObject("child")
+		child.Transform().SetScale(mgl32.Vec3{2, 2, 2})
+		child.SetActive(false)
+		child.SetLayer(LayerUI)
+		parent.AddChild(child)
+
+		if err := src.Graph().AddGameObject(parent, nil); err != nil {
+			t.Fatal(err)
+		}
+
+		buf := &bytes.Buffer{}
+		if err := SaveScene(buf, src, format); err != nil {
+			t.Fatal(err)
+		}
+
+		doc, err := DecodeSceneDocument(buf, format)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		dst := NewScene("dst")
+		if err := dst.Load(); err != nil {
+			t.Fatal(err)
+		}
+		if err := dst.LoadDocument(doc); err != nil {
+			t.Fatal(err)
+		}
+
+		objects := dst.Graph().Children(dst.Graph().root)
+		if len(objects) != 1 {
+			t.Fatalf("format %d: expected 1 object, got: %d", format, len(objects))
+		}
+
+		p := objects[0]
+		if p.Name() != "parent" {
+			t.Errorf("format %d: expected name parent, got: %s", format, p.Name())
+		}
+		if !p.HasTag("player") || p.Layer() != LayerDefault {
+			t.Errorf("format %d: tags or layer mismatch: %v %d", format, p.Tags(), p.Layer())
+		}
+		if !p.Transform().Position().ApproxEqual(mgl32.Vec3{4, 5, 6}) {
+			t.Errorf("format %d: position mismatch: %v", format, p.Transform().Position())
+		}
+		if !p.Transform().Rotation().ApproxEqual(parent.Transform().Rotation()) {
+			t.Errorf("format %d: rotation mismatch: %v", format, p.Transform().Rotation())
+		}
+
+		var dc *docComponent
+		for _, pc := range p.Components() {
+			if v, ok := pc.(*docComponent); ok {
+				dc = v
+			}
+		}
+		if dc == nil {
+			t.Fatalf("format %d: docComponent not restored", format)
+		}
+		if dc.Speed != 2.5 || dc.Offset != (mgl32.Vec3{1, 2, 3}) || dc.Label != "doc" || len(dc.Tags) != 2 || dc.Tags[1] != "b" {
+			t.Errorf("format %d: fields mismatch: %+v", format, dc)
+		}
+		if dc.hidden != 0 {
+			t.Errorf("format %d: unexported field restored: %d", format, dc.hidden)
+		}
+
+		children := dst.Graph().Children(p)
+		if len(children) != 1 {
+			t.Fatalf("format %d: expected 1 child, got: %d", format, len(children))
+		}
+		if children[0].Layer() != LayerUI {
+			t.Errorf("format %d: expected child layer %d, got: %d", format, LayerUI, children[0].Layer())
+		}
+		if children[0].Active() {
+			t.Errorf("format %d: expected child to be inactive", format)
+		}
+		if !children[0].Transform().Scale().ApproxEqual(mgl32.Vec3{2, 2, 2}) {
+			t.Errorf("format %d: scale mismatch: %v", format, children[0].Transform().Scale())
+		}
+
+		a.Teardown()
+	}
+}
+
+func TestSceneFormatFromFilename(t *testing.T) {
+	tests := map[string]SceneFormat{
+		"level.json": SceneFormatJSON,
+		"level.yaml": SceneFormatYAML,
+		"level.YML":  SceneFormatYAML,
+	}
+
+	for filename, want := range tests {
+		got, err := SceneFormatFromFilename(filename)
+		if err != nil {
+			t.Error(filename, err)
+		} else if got != want {
+			t.Errorf("%s: expected %d, got: %d", filename, want, got)
+		}
+	}
+
+	if _, err := SceneFormatFromFilename("level.txt"); err == nil {
+		t.Error("expected error for level.txt")
+	}
+}
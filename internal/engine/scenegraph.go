@@ -23,11 +23,19 @@ SOFTWARE.
 package engine
 
 import (
+	"sort"
+
 	"github.com/sirupsen/logrus"
 
 	"github.com/haakenlabs/forge/internal/sg"
 )
 
+// SceneGraph errors
+const (
+	ErrSceneGraphNotFound = Error("game object is not in the scene graph")
+	ErrSceneGraphRoot     = Error("cannot change the root of the scene graph")
+)
+
 type SceneGraphListener interface {
 	// OnSceneGraphUpdate is called when the SceneGraph has been updated.
 	OnSceneGraphUpdate()
@@ -38,6 +46,9 @@ type SceneGraph struct {
 	graph          *sg.Graph
 	active         []*GameObject
 	componentCache []Component
+	scripts        []ScriptComponent
+	dfs            []sg.VertexDescriptor
+	index          sceneGraphIndex
 	scene          *Scene
 	dirty          bool
 }
@@ -45,6 +56,7 @@ type SceneGraph struct {
 func NewSceneGraph(scene *Scene) *SceneGraph {
 	s := &SceneGraph{
 		graph:          sg.NewGraph(),
+		index:          newSceneGraphIndex(),
 		scene:          scene,
 		dirty:          true,
 		active:         []*GameObject{},
@@ -57,22 +69,50 @@ func NewSceneGraph(scene *Scene) *SceneGraph {
 }
 
 func (s *SceneGraph) Update() {
-	dfs := s.graph.DepthFirstSearch(1, false)
+	s.dfs = s.graph.AppendDepthFirstSearch(s.dfs[:0], 1, false)
 	s.active = s.active[:0]
 	s.componentCache = s.componentCache[:0]
+	s.index.reset()
 
-	for _, v := range dfs {
+	// The script list is replaced rather than reused, as it may be rebuilt
+	// while a message is sent.
+	s.scripts = make([]ScriptComponent, 0, len(s.scripts))
+
+	for _, v := range s.dfs {
 		o := s.graph.GetObjectAtVertex(v).(*GameObject)
 		s.active = append(s.active, o)
 		s.componentCache = append(s.componentCache, o.Components()...)
+
+		for _, c := range o.Components() {
+			if sc, ok := c.(ScriptComponent); ok {
+				s.scripts = append(s.scripts, sc)
+			}
+		}
+
+		if o != s.root {
+			s.index.add(o)
+		}
 	}
 
+	sort.SliceStable(s.scripts, func(i, j int) bool {
+		return componentPriority(s.scripts[i]) < componentPriority(s.scripts[j])
+	})
+
 	s.dirty = false
 	s.notifyListeners()
 
 	logrus.Debugf("SceneGraph updated. activeObjects: %d componentCache: %d", len(s.active), len(s.componentCache))
 }
 
+// ResolveTransforms recomputes the invalid world matrices of the active game
+// objects. Game objects are visited parent-first, so each matrix is computed
+// once.
+func (s *SceneGraph) ResolveTransforms() {
+	for i := range s.active {
+		s.active[i].Transform().ActiveMatrix()
+	}
+}
+
 // Dirty returns the state of the graph. If true, the graph needs an update.
 func (s *SceneGraph) Dirty() bool {
 	return s.dirty
@@ -84,6 +124,24 @@ func (s *SceneGraph) SetDirty() {
 }
 
 func (s *SceneGraph) AddGameObject(object, parent *GameObject) error {
+	if err := s.addGameObject(object, parent); err != nil {
+		return err
+	}
+
+	object.Transform().Recompute(true)
+
+	// Awaken and enable the scripts once the hierarchy is linked.
+	object.refreshScripts()
+
+	// Notify graph of update.
+	s.Update()
+
+	return nil
+}
+
+// addGameObject adds the game object and its children to the graph, without
+// recomputing transforms or updating the graph.
+func (s *SceneGraph) addGameObject(object, parent *GameObject) error {
 	var err error
 	var u sg.VertexDescriptor // parent
 	var v sg.VertexDescriptor // object
@@ -111,7 +169,7 @@ func (s *SceneGraph) AddGameObject(object, parent *GameObject) error {
 	// Add children, if any.
 	children := object.Children()
 	for i := range children {
-		if err := s.AddGameObject(children[i], object); err != nil {
+		if err := s.addGameObject(children[i], object); err != nil {
 			return err
 		}
 	}
@@ -119,17 +177,153 @@ func (s *SceneGraph) AddGameObject(object, parent *GameObject) error {
 	// Build associations.
 	object.SetScene(s.scene)
 	s.updateReferences(object)
-	object.SendMessage(MessageActivate)
+	object.activate()
+
+	return nil
+}
+
+// RemoveGameObject removes the game object and its descendants from the graph,
+// without releasing them. The game object keeps its local transform and its
+// children, so it can be added to a graph again.
+func (s *SceneGraph) RemoveGameObject(object *GameObject) error {
+	v, err := s.vertexOf(object)
+	if err != nil {
+		return err
+	}
+
+	descendants := s.subtree(v)[1:]
+
+	if err := s.graph.RemoveVertex(v); err != nil {
+		return err
+	}
+
+	if parent := object.Parent(); parent != nil {
+		parent.RemoveChild(object.ID())
+	}
+
+	object.parent = nil
+	object.scene = nil
+	for i := range descendants {
+		descendants[i].scene = nil
+	}
+
+	object.refreshScripts()
+	object.Transform().Recompute(true)
 
-	// Notify graph of update.
 	s.Update()
 
 	return nil
 }
 
+// SetParent moves the game object under a new parent. If newParent is nil, the
+// game object is moved to the root of the graph. If keepWorldTransform is
+// set, the local transform is changed so the game object keeps its position,
+// rotation and scale in world space; otherwise it keeps its local transform.
+func (s *SceneGraph) SetParent(object, newParent *GameObject, keepWorldTransform bool) error {
+	v, err := s.vertexOf(object)
+	if err != nil {
+		return err
+	}
+
+	var u sg.VertexDescriptor
+	if newParent == nil {
+		newParent = s.root
+		u, err = s.graph.GetVertexByObject(s.root)
+	} else {
+		u, err = s.vertexOf(newParent)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := s.graph.MoveVertex(v, u); err != nil {
+		return err
+	}
+
+	world := object.Transform().ActiveMatrix()
+
+	if oldParent := object.Parent(); oldParent != nil {
+		oldParent.RemoveChild(object.ID())
+	}
+	newParent.AddChild(object)
+	object.parent = newParent
+
+	if keepWorldTransform {
+		local := newParent.Transform().ActiveMatrix().Inv().Mul4(world)
+		position, rotation, scale := decomposeMatrix(local)
+
+		t := object.Transform()
+		t.SetPosition(position)
+		t.SetRotation(rotation)
+		t.SetScale(scale)
+	}
+
+	object.OnParentChanged()
+	object.refreshScripts()
+
+	s.Update()
+
+	return nil
+}
+
+// Destroy removes the game object and its descendants from the graph, and
+// releases them and their components. Components implementing DestroyListener
+// are notified first.
+func (s *SceneGraph) Destroy(object *GameObject) error {
+	v, err := s.vertexOf(object)
+	if err != nil {
+		return err
+	}
+
+	objects := s.subtree(v)
+
+	for i := range objects {
+		objects[i].disableScripts()
+	}
+	for i := range objects {
+		objects[i].SendMessage(MessageDestroy)
+	}
+
+	if e := events(); e != nil {
+		e.unsubscribeObjects(objects)
+	}
+	if sc := scheduler(); sc != nil {
+		sc.cancelObjects(objects)
+	}
+
+	if err := s.RemoveGameObject(object); err != nil {
+		return err
+	}
+
+	var ids []uint32
+	for i := range objects {
+		for _, c := range objects[i].Components() {
+			ids = append(ids, c.ID())
+		}
+		ids = append(ids, objects[i].ID())
+	}
+
+	GetInstance().Release(ids...)
+
+	return nil
+}
+
+// SendMessage sends the message to the active game objects. Per-frame
+// messages are sent to the script components in order of priority.
 func (s *SceneGraph) SendMessage(message Message) {
-	for i := range s.active {
-		s.active[i].SendMessage(message)
+	// Scripts may change the graph while handling the message, which
+	// replaces the lists, so the lists of this call are kept.
+	switch message {
+	case MessageStart, MessageUpdate, MessageLateUpdate, MessageFixedUpdate, MessageGUIRender:
+		scripts := s.scripts
+		for i := range scripts {
+			sendScriptMessage(scripts[i], message)
+		}
+	default:
+		active := append([]*GameObject(nil), s.active...)
+		for i := range active {
+			active[i].SendMessage(message)
+		}
 	}
 }
 
@@ -191,6 +385,36 @@ func (s *SceneGraph) Components() []Component {
 	return s.componentCache
 }
 
+// subtree returns the game object at the vertex and all of its descendants,
+// including inactive ones.
+func (s *SceneGraph) subtree(u sg.VertexDescriptor) []*GameObject {
+	var objects []*GameObject
+
+	for _, v := range s.graph.DepthFirstSearch(u, true) {
+		objects = append(objects, s.objectAt(v))
+	}
+
+	return objects
+}
+
+// vertexOf returns the vertex of a game object in the graph. Unlike
+// sg.Graph.GetVertexByObject, no vertex is added for unknown objects.
+func (s *SceneGraph) vertexOf(object *GameObject) (sg.VertexDescriptor, error) {
+	if object == nil {
+		return 0, ErrSceneGraphNotFound
+	}
+	if object == s.root {
+		return 0, ErrSceneGraphRoot
+	}
+
+	v, err := s.graph.GetVertexById(object.ID())
+	if err != nil {
+		return 0, ErrSceneGraphNotFound
+	}
+
+	return v, nil
+}
+
 func (s *SceneGraph) objectAt(u sg.VertexDescriptor) *GameObject {
 	obj := s.graph.GetObjectAtVertex(u)
 	if obj == nil {
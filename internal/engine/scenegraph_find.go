@@ -0,0 +1,149 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"reflect"
+	"strings"
+)
+
+// sceneGraphIndex indexes the active game objects and components of a scene
+// graph by name, tag and component type. It is rebuilt by SceneGraph.Update,
+// after game objects are renamed or retagged.
+type sceneGraphIndex struct {
+	names map[string][]*GameObject
+	tags  map[string][]*GameObject
+	types map[reflect.Type][]Component
+}
+
+func newSceneGraphIndex() sceneGraphIndex {
+	return sceneGraphIndex{
+		names: make(map[string][]*GameObject),
+		tags:  make(map[string][]*GameObject),
+		types: make(map[reflect.Type][]Component),
+	}
+}
+
+func (x *sceneGraphIndex) reset() {
+	clear(x.names)
+	clear(x.tags)
+	clear(x.types)
+}
+
+func (x *sceneGraphIndex) add(o *GameObject) {
+	x.names[o.Name()] = append(x.names[o.Name()], o)
+
+	for _, tag := range o.Tags() {
+		x.tags[tag] = append(x.tags[tag], o)
+	}
+
+	for _, c := range o.Components() {
+		t := reflect.TypeOf(c)
+		x.types[t] = append(x.types[t], c)
+	}
+}
+
+// Find returns the active game object at the path, or nil if there is none.
+// A path is a list of game object names separated by slashes, starting at
+// the top of the graph, such as "player/camera".
+func (s *SceneGraph) Find(path string) *GameObject {
+	names := strings.Split(strings.Trim(path, "/"), "/")
+
+	for _, o := range s.lookup().names[names[len(names)-1]] {
+		if s.matchPath(o, names) {
+			return o
+		}
+	}
+
+	return nil
+}
+
+// FindByName returns the active game objects with the name.
+func (s *SceneGraph) FindByName(name string) []*GameObject {
+	return append([]*GameObject(nil), s.lookup().names[name]...)
+}
+
+// FindByTag returns the active game objects with the tag.
+func (s *SceneGraph) FindByTag(tag string) []*GameObject {
+	return append([]*GameObject(nil), s.lookup().tags[tag]...)
+}
+
+// FindInLayers returns the active game objects in any of the layers of mask.
+func (s *SceneGraph) FindInLayers(mask uint32) []*GameObject {
+	var objects []*GameObject
+
+	s.lookup()
+	for _, o := range s.active {
+		if o != s.root && o.InLayers(mask) {
+			objects = append(objects, o)
+		}
+	}
+
+	return objects
+}
+
+// FindAllWithComponent returns the active components of type T in the scene
+// graph. Concrete types are looked up in the index, while interface types
+// are matched against every active component.
+func FindAllWithComponent[T any](s *SceneGraph) []T {
+	var found []T
+
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if t.Kind() != reflect.Interface {
+		for _, c := range s.lookup().types[t] {
+			found = append(found, c.(T))
+		}
+		return found
+	}
+
+	s.lookup()
+	for _, c := range s.componentCache {
+		if v, ok := c.(T); ok {
+			found = append(found, v)
+		}
+	}
+
+	return found
+}
+
+// lookup returns the index, updating the graph first if required.
+func (s *SceneGraph) lookup() *sceneGraphIndex {
+	if s.dirty {
+		s.Update()
+	}
+
+	return &s.index
+}
+
+// matchPath reports whether the names of the game object and its ancestors
+// match the path names, and the last ancestor is at the top of the graph.
+func (s *SceneGraph) matchPath(o *GameObject, names []string) bool {
+	for i := len(names) - 1; i >= 0; i-- {
+		if o == nil || o == s.root || o.Name() != names[i] {
+			return false
+		}
+		o = o.Parent()
+	}
+
+	return o == s.root
+}
@@ -0,0 +1,142 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import "testing"
+
+type findComponent struct {
+	BaseScriptComponent
+}
+
+func TestSceneGraph_Find(t *testing.T) {
+	app := newHeadlessApp(t)
+	defer app.Teardown()
+
+	s := NewScene("test")
+	s.Setup()
+	g := s.Graph()
+
+	player := NewGameObject("player")
+	player.AddTag("player")
+	camera := NewGameObject("camera")
+	camera.SetLayer(LayerDefault | LayerUI)
+	enemy := NewGameObject("enemy")
+	enemy.AddTag("enemy")
+	other := NewGameObject("camera")
+
+	c := &findComponent{}
+	GetInstance().MustAssign(c)
+	camera.AddComponent(c)
+
+	if err := g.AddGameObject(player, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.AddGameObject(camera, player); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.AddGameObject(enemy, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.AddGameObject(other, enemy); err != nil {
+		t.Fatal(err)
+	}
+
+	if o := g.Find("player/camera"); o != camera {
+		t.Errorf("expected player/camera, got: %v", o)
+	}
+	if o := g.Find("/enemy/camera"); o != other {
+		t.Errorf("expected enemy/camera, got: %v", o)
+	}
+	if o := g.Find("camera"); o != nil {
+		t.Errorf("expected no top-level camera, got: %v", o)
+	}
+	if n := len(g.FindByName("camera")); n != 2 {
+		t.Errorf("expected 2 cameras, got: %d", n)
+	}
+	if n := len(g.FindInLayers(LayerUI)); n != 1 {
+		t.Errorf("expected 1 object in the UI layer, got: %d", n)
+	}
+
+	// Tags are indexed again after they change.
+	if o := g.FindByTag("enemy"); len(o) != 1 || o[0] != enemy {
+		t.Errorf("expected enemy, got: %v", o)
+	}
+	other.AddTag("enemy")
+	if n := len(g.FindByTag("enemy")); n != 2 {
+		t.Errorf("expected 2 enemies, got: %d", n)
+	}
+
+	other.SetTags("friend")
+	if o := g.FindByTag("friend"); len(o) != 1 || o[0] != other {
+		t.Errorf("expected friend, got: %v", o)
+	}
+	other.SetTags("enemy")
+
+	// Renamed objects are indexed again.
+	other.SetName("scope")
+	if n := len(g.FindByName("camera")); n != 1 {
+		t.Errorf("expected 1 camera after rename, got: %d", n)
+	}
+	if o := g.Find("enemy/scope"); o != other {
+		t.Errorf("expected enemy/scope, got: %v", o)
+	}
+
+	// Results are copies of the index.
+	found := g.FindByTag("enemy")
+	found[0] = nil
+	if o := g.FindByTag("enemy"); o[0] == nil {
+		t.Error("expected FindByTag to return a copy")
+	}
+	found = g.FindByName("camera")
+	found[0] = nil
+	if o := g.FindByName("camera"); o[0] != camera {
+		t.Error("expected FindByName to return a copy")
+	}
+
+	// Inactive objects and their descendants are not found.
+	player.SetActive(false)
+	if o := g.Find("player/camera"); o != nil {
+		t.Errorf("expected inactive player/camera not to be found, got: %v", o)
+	}
+	if n := len(FindAllWithComponent[*findComponent](g)); n != 0 {
+		t.Errorf("expected no components, got: %d", n)
+	}
+
+	player.SetActive(true)
+	if f := FindAllWithComponent[*findComponent](g); len(f) != 1 || f[0] != c {
+		t.Errorf("expected findComponent, got: %v", f)
+	}
+	if n := len(FindAllWithComponent[ScriptComponent](g)); n != 1 {
+		t.Errorf("expected 1 script component, got: %d", n)
+	}
+
+	if GetComponent[*findComponent](player) != nil {
+		t.Error("expected no findComponent on player")
+	}
+	if GetComponentInChildren[*findComponent](player) != c {
+		t.Error("expected findComponent in children of player")
+	}
+	if GetComponent[Transform](camera) != camera.Transform() {
+		t.Error("expected transform of camera")
+	}
+}
@@ -0,0 +1,180 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package engine
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+type lifecycleComponent struct {
+	BaseScriptComponent
+
+	destroyed     int
+	parentChanged int
+}
+
+func (c *lifecycleComponent) OnDestroy()       { c.destroyed++ }
+func (c *lifecycleComponent) OnParentChanged() { c.parentChanged++ }
+
+func newLifecycleObject(name string) (*GameObject, *lifecycleComponent) {
+	c := &lifecycleComponent{}
+	GetInstance().MustAssign(c)
+
+	o := NewGameObject(name)
+	o.AddComponent(c)
+
+	return o, c
+}
+
+func TestSceneGraph_SetParent(t *testing.T) {
+	app := newHeadlessApp(t)
+	defer app.Teardown()
+
+	s := NewScene("test")
+	s.Setup()
+	g := s.Graph()
+
+	a, _ := newLifecycleObject("a")
+	b, bc := newLifecycleObject("b")
+	c, _ := newLifecycleObject("c")
+
+	a.Transform().SetPosition(mgl32.Vec3{1, 0, 0})
+	b.Transform().SetPosition(mgl32.Vec3{0, 2, 0})
+	c.Transform().SetPosition(mgl32.Vec3{0, 0, 3})
+
+	if err := g.AddGameObject(a, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.AddGameObject(b, a); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.AddGameObject(c, b); err != nil {
+		t.Fatal(err)
+	}
+
+	if p := c.Transform().WorldPosition(); !p.ApproxEqual(mgl32.Vec3{1, 2, 3}) {
+		t.Error("c world position expected [1 2 3], got:", p)
+	}
+
+	// A game object cannot be moved under its own descendant.
+	if err := g.SetParent(a, c, false); err == nil {
+		t.Error("g.SetParent(a, c) expected error")
+	}
+
+	// Keep the world transform when moving b to the root.
+	if err := g.SetParent(b, nil, true); err != nil {
+		t.Fatal(err)
+	}
+
+	if p := b.Transform().Position(); !p.ApproxEqual(mgl32.Vec3{1, 2, 0}) {
+		t.Error("b position expected [1 2 0], got:", p)
+	}
+	if p := c.Transform().WorldPosition(); !p.ApproxEqual(mgl32.Vec3{1, 2, 3}) {
+		t.Error("c world position expected [1 2 3], got:", p)
+	}
+	if len(a.Children()) != 0 {
+		t.Error("a.Children() expected empty, got:", a.Children())
+	}
+	if len(g.Children(a)) != 0 {
+		t.Error("g.Children(a) expected empty, got:", g.Children(a))
+	}
+	if bc.parentChanged != 1 {
+		t.Error("bc.parentChanged expected 1, got:", bc.parentChanged)
+	}
+
+	// Keep the local transform when moving b back under a.
+	if err := g.SetParent(b, a, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if b.Parent() != a || g.Parent(b) != a {
+		t.Error("b parent expected a")
+	}
+	if p := c.Transform().WorldPosition(); !p.ApproxEqual(mgl32.Vec3{2, 2, 3}) {
+		t.Error("c world position expected [2 2 3], got:", p)
+	}
+}
+
+func TestSceneGraph_RemoveAndDestroy(t *testing.T) {
+	app := newHeadlessApp(t)
+	defer app.Teardown()
+
+	s := NewScene("test")
+	s.Setup()
+	g := s.Graph()
+
+	a, ac := newLifecycleObject("a")
+	b, bc := newLifecycleObject("b")
+	c, cc := newLifecycleObject("c")
+
+	if err := g.AddGameObject(a, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.AddGameObject(b, a); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.AddGameObject(c, b); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := g.RemoveGameObject(b); err != nil {
+		t.Fatal(err)
+	}
+
+	if b.Parent() != nil || b.Scene() != nil || c.Scene() != nil {
+		t.Error("b expected detached from the scene")
+	}
+	if len(a.Children()) != 0 || len(g.Children(a)) != 0 {
+		t.Error("a expected no children")
+	}
+	if len(b.Children()) != 1 || b.Children()[0] != c {
+		t.Error("b.Children() expected [c]")
+	}
+	if err := g.RemoveGameObject(b); err != ErrSceneGraphNotFound {
+		t.Error("g.RemoveGameObject(b) expected ErrSceneGraphNotFound, got:", err)
+	}
+
+	// Add the subtree back, then destroy it.
+	if err := g.AddGameObject(b, a); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Destroy(b); err != nil {
+		t.Fatal(err)
+	}
+
+	if bc.destroyed != 1 || cc.destroyed != 1 {
+		t.Errorf("destroyed expected 1, got: b=%d c=%d", bc.destroyed, cc.destroyed)
+	}
+	if ac.destroyed != 0 {
+		t.Error("ac.destroyed expected 0, got:", ac.destroyed)
+	}
+	if b.ID() != 0 || c.ID() != 0 || bc.ID() != 0 {
+		t.Error("destroyed objects expected released")
+	}
+
+	// The root and a remain.
+	if len(g.active) != 2 {
+		t.Error("len(g.active) expected 2, got:", len(g.active))
+	}
+}
@@ -0,0 +1,314 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+var _ System = &Scheduler{}
+
+const SysNameScheduler = "scheduler"
+
+// Wait is an instruction yielded by a coroutine, which resumes it once the
+// condition is met. Conditions are first checked in the frame after the
+// yield. The zero Wait resumes the coroutine in the next frame.
+type Wait struct {
+	seconds float64
+	frames  int
+	until   func() bool
+}
+
+// WaitSeconds resumes the coroutine once d seconds of scaled time have passed.
+func WaitSeconds(d float64) Wait {
+	return Wait{seconds: d}
+}
+
+// WaitFrames resumes the coroutine after n frames.
+func WaitFrames(n int) Wait {
+	return Wait{frames: n}
+}
+
+// WaitUntil resumes the coroutine once the predicate returns true. The
+// predicate is called once per frame.
+func WaitUntil(predicate func() bool) Wait {
+	return Wait{until: predicate}
+}
+
+// Coroutine is a function run by the scheduler across frames. It suspends
+// itself by yielding a Wait; if yield returns false, the coroutine has been
+// canceled and must return.
+//
+// With Go 1.23 or later, coroutines are built on iter.Pull and run on the
+// thread calling Scheduler.Update, so they may use the GL context like any
+// other script. Built with older versions of Go, coroutines run on their own
+// goroutine and must not call GL functions or anything else bound to the main
+// thread; only one of the coroutine and the scheduler runs at a time, so game
+// state may still be changed without locking.
+type Coroutine func(yield func(Wait) bool)
+
+// Task is a timer or coroutine run by the scheduler.
+type Task struct {
+	owner    *GameObject
+	fn       func()
+	interval float64
+	repeat   bool
+	co       *coroutine
+	wait     Wait
+	elapsed  float64
+	frames   int
+	running  bool
+}
+
+// SetOwner binds the task to the game object, so it is canceled when the game
+// object is destroyed.
+func (t *Task) SetOwner(owner *GameObject) *Task {
+	t.owner = owner
+
+	return t
+}
+
+// Owner returns the game object owning the task, or nil if there is none.
+func (t *Task) Owner() *GameObject {
+	return t.owner
+}
+
+// Running reports whether the task is scheduled.
+func (t *Task) Running() bool {
+	return t.running
+}
+
+// Cancel stops the task. A suspended coroutine is resumed with yield returning
+// false, and Cancel waits for it to return.
+func (t *Task) Cancel() {
+	if !t.running {
+		return
+	}
+
+	t.running = false
+
+	if t.co != nil {
+		t.co.cancel()
+	}
+}
+
+// update advances the task by dt seconds.
+func (t *Task) update(dt float64) {
+	t.elapsed += dt
+	t.frames++
+
+	if t.co != nil {
+		if t.ready() {
+			t.resume()
+		}
+		return
+	}
+
+	if !t.repeat {
+		if t.elapsed >= t.interval {
+			t.running = false
+			t.fn()
+		}
+		return
+	}
+
+	if t.interval <= 0 {
+		t.fn()
+		return
+	}
+
+	for t.running && t.elapsed >= t.interval {
+		t.elapsed -= t.interval
+		t.fn()
+	}
+}
+
+// ready reports whether the wait of the coroutine is over.
+func (t *Task) ready() bool {
+	switch {
+	case t.wait.until != nil:
+		return t.wait.until()
+	case t.wait.seconds > 0:
+		return t.elapsed >= t.wait.seconds
+	case t.wait.frames > 0:
+		return t.frames >= t.wait.frames
+	}
+
+	return true
+}
+
+// resume runs the coroutine until its next yield.
+func (t *Task) resume() {
+	w, ok := t.co.step()
+	if !ok {
+		t.running = false
+		return
+	}
+
+	t.wait = w
+	t.elapsed = 0
+	t.frames = 0
+}
+
+// Scheduler is a system which runs timers and coroutines. It is advanced by
+// the scaled frame time, so tasks follow the time scale and do not run while
+// the time is paused.
+type Scheduler struct {
+	tasks    []*Task
+	pending  []*Task
+	updating bool
+}
+
+// Setup sets up the System.
+func (s *Scheduler) Setup() error {
+	return nil
+}
+
+// Teardown tears down the System.
+func (s *Scheduler) Teardown() {
+	s.CancelAll()
+}
+
+// Name returns the name of the System.
+func (s *Scheduler) Name() string {
+	return SysNameScheduler
+}
+
+// After calls fn once, after d seconds.
+func (s *Scheduler) After(d float64, fn func()) *Task {
+	return s.add(&Task{fn: fn, interval: d})
+}
+
+// Every calls fn every d seconds, until the task is canceled. If d is not
+// positive, fn is called every frame.
+func (s *Scheduler) Every(d float64, fn func()) *Task {
+	return s.add(&Task{fn: fn, interval: d, repeat: true})
+}
+
+// StartCoroutine runs the coroutine until its first yield, and resumes it in
+// later frames as its waits are over.
+func (s *Scheduler) StartCoroutine(fn Coroutine) *Task {
+	t := s.add(&Task{co: newCoroutine(fn)})
+	t.resume()
+
+	return t
+}
+
+// Update advances the tasks by dt seconds. Tasks scheduled during an update
+// are first advanced by the next update.
+func (s *Scheduler) Update(dt float64) {
+	s.updating = true
+
+	for _, t := range s.tasks {
+		if t.running {
+			t.update(dt)
+		}
+	}
+
+	s.updating = false
+
+	tasks := s.tasks[:0]
+	for _, t := range s.tasks {
+		if t.running {
+			tasks = append(tasks, t)
+		}
+	}
+	for i := len(tasks); i < len(s.tasks); i++ {
+		s.tasks[i] = nil
+	}
+	s.tasks = append(tasks, s.pending...)
+	s.pending = s.pending[:0]
+}
+
+// Count returns the number of scheduled tasks.
+func (s *Scheduler) Count() int {
+	n := 0
+	for _, t := range s.tasks {
+		if t.running {
+			n++
+		}
+	}
+	for _, t := range s.pending {
+		if t.running {
+			n++
+		}
+	}
+
+	return n
+}
+
+// CancelAll cancels all tasks.
+func (s *Scheduler) CancelAll() {
+	for _, t := range s.tasks {
+		t.Cancel()
+	}
+	for _, t := range s.pending {
+		t.Cancel()
+	}
+}
+
+// cancelObjects cancels the tasks owned by the game objects.
+func (s *Scheduler) cancelObjects(objects []*GameObject) {
+	set := make(map[*GameObject]struct{}, len(objects))
+	for i := range objects {
+		set[objects[i]] = struct{}{}
+	}
+
+	for _, list := range [][]*Task{s.tasks, s.pending} {
+		for _, t := range list {
+			if _, ok := set[t.owner]; ok && t.owner != nil {
+				t.Cancel()
+			}
+		}
+	}
+}
+
+func (s *Scheduler) add(t *Task) *Task {
+	t.running = true
+
+	if s.updating {
+		s.pending = append(s.pending, t)
+	} else {
+		s.tasks = append(s.tasks, t)
+	}
+
+	return t
+}
+
+// NewScheduler creates a new scheduler system.
+func NewScheduler() *Scheduler {
+	return &Scheduler{}
+}
+
+// GetScheduler gets the scheduler system from the current app.
+func GetScheduler() *Scheduler {
+	return CurrentApp().MustSystem(SysNameScheduler).(*Scheduler)
+}
+
+// scheduler returns the scheduler system of the current app, or nil if there
+// is none.
+func scheduler() *Scheduler {
+	if a := CurrentApp(); a != nil {
+		if s, err := a.System(SysNameScheduler); err == nil {
+			return s.(*Scheduler)
+		}
+	}
+
+	return nil
+}
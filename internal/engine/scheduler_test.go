@@ -0,0 +1,172 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestScheduler_Timers(t *testing.T) {
+	app := newHeadlessApp(t)
+	defer app.Teardown()
+
+	s := GetScheduler()
+
+	after, every := 0, 0
+	s.After(1, func() { after++ })
+	tick := s.Every(0.5, func() { every++ })
+
+	s.Update(0.75)
+	if after != 0 || every != 1 {
+		t.Errorf("expected 0 and 1 calls, got: %d %d", after, every)
+	}
+	s.Update(0.75)
+	if after != 1 || every != 3 {
+		t.Errorf("expected 1 and 3 calls, got: %d %d", after, every)
+	}
+
+	tick.Cancel()
+	s.Update(1)
+	if after != 1 || every != 3 || s.Count() != 0 {
+		t.Errorf("expected no calls after completion and cancel, got: %d %d, %d running", after, every, s.Count())
+	}
+}
+
+func TestScheduler_Coroutine(t *testing.T) {
+	app := newHeadlessApp(t)
+	defer app.Teardown()
+
+	s := GetScheduler()
+
+	var log []string
+	ready := false
+
+	task := s.StartCoroutine(func(yield func(Wait) bool) {
+		log = append(log, "start")
+		if !yield(WaitSeconds(1)) {
+			return
+		}
+		log = append(log, "seconds")
+		if !yield(WaitFrames(2)) {
+			return
+		}
+		log = append(log, "frames")
+		if !yield(WaitUntil(func() bool { return ready })) {
+			return
+		}
+		log = append(log, "until")
+	})
+
+	expect := func(step string, want ...string) {
+		t.Helper()
+		if !reflect.DeepEqual(log, want) {
+			t.Errorf("%s: expected %v, got: %v", step, want, log)
+		}
+	}
+
+	expect("started", "start")
+	s.Update(0.5)
+	expect("half a second", "start")
+	s.Update(0.5)
+	expect("a second", "start", "seconds")
+	s.Update(0)
+	expect("a frame", "start", "seconds")
+	s.Update(0)
+	expect("two frames", "start", "seconds", "frames")
+	s.Update(0)
+	ready = true
+	s.Update(0)
+	expect("until", "start", "seconds", "frames", "until")
+	if task.Running() {
+		t.Error("expected finished coroutine")
+	}
+
+	// Canceled coroutines see yield return false.
+	canceled := false
+	task = s.StartCoroutine(func(yield func(Wait) bool) {
+		for yield(Wait{}) {
+		}
+		canceled = true
+	})
+	s.Update(0)
+	task.Cancel()
+	if !canceled || task.Running() {
+		t.Error("expected canceled coroutine to return")
+	}
+
+	// Coroutines may cancel their own task.
+	var self *Task
+	steps := 0
+	self = s.StartCoroutine(func(yield func(Wait) bool) {
+		for yield(Wait{}) {
+			steps++
+			if steps == 2 {
+				self.Cancel()
+			}
+		}
+	})
+	for i := 0; i < 4; i++ {
+		s.Update(0)
+	}
+	if steps != 2 || self.Running() {
+		t.Errorf("expected self-canceled coroutine after 2 steps, got: %d", steps)
+	}
+}
+
+func TestScheduler_TimeAndOwner(t *testing.T) {
+	app := newHeadlessApp(t)
+	defer app.Teardown()
+
+	scene := NewScene("scheduler")
+	if err := scene.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	o := NewGameObject("owner")
+	if err := scene.Graph().AddGameObject(o, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	calls := 0
+	task := GetScheduler().Every(0, func() { calls++ }).SetOwner(o)
+
+	// Paused time stops the scheduler.
+	GetTime().SetPaused(true)
+	app.Step(3)
+	if calls != 0 {
+		t.Errorf("expected no calls while paused, got: %d", calls)
+	}
+	GetTime().SetPaused(false)
+	app.Step(3)
+	if calls != 3 {
+		t.Errorf("expected 3 calls, got: %d", calls)
+	}
+
+	if err := scene.Graph().Destroy(o); err != nil {
+		t.Fatal(err)
+	}
+	if task.Running() {
+		t.Error("expected task to be canceled with its owner")
+	}
+}
@@ -0,0 +1,68 @@
+//go:build go1.23 && linux
+
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"runtime"
+	"syscall"
+	"testing"
+)
+
+func TestScheduler_CoroutineThread(t *testing.T) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	app := newHeadlessApp(t)
+	defer app.Teardown()
+
+	s := GetScheduler()
+	tid := syscall.Gettid()
+
+	// Coroutines run on the locked thread of the scheduler, so they can use
+	// the GL context.
+	var threads []int
+	s.StartCoroutine(func(yield func(Wait) bool) {
+		for i := 0; i < 3; i++ {
+			runtime.Gosched()
+			threads = append(threads, syscall.Gettid())
+			if !yield(Wait{}) {
+				return
+			}
+		}
+	})
+
+	for i := 0; i < 3; i++ {
+		s.Update(fixedTime)
+	}
+
+	if len(threads) != 3 {
+		t.Fatalf("expected 3 steps, got: %d", len(threads))
+	}
+	for i := range threads {
+		if threads[i] != tid {
+			t.Errorf("step %d expected thread %d, got: %d", i, tid, threads[i])
+		}
+	}
+}
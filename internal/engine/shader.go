@@ -43,6 +43,11 @@ const (
 	ShaderComponentTessEvaluation                 = gl.TESS_EVALUATION_SHADER
 )
 
+// Shader variants
+const (
+	ShaderVariantSkinned = "skinned"
+)
+
 var _ Object = &Shader{}
 
 type Shader struct {
@@ -51,11 +56,38 @@ type Shader struct {
 	programId       uint32
 	components      map[ShaderComponent]uint32
 	data            []byte
+	defines         []string
+	variantDefines  map[string][]string
+	variants        map[string]*Shader
 	deferredCapable bool
 }
 
+// Alloc builds the shader and its variants.
 func (s *Shader) Alloc() error {
-	return s.Build()
+	if err := s.Build(); err != nil {
+		return err
+	}
+
+	for name, defines := range s.variantDefines {
+		v := &Shader{
+			components:      make(map[ShaderComponent]uint32),
+			data:            s.data,
+			defines:         defines,
+			deferredCapable: s.deferredCapable,
+		}
+
+		if err := v.Build(); err != nil {
+			v.Dealloc()
+			return fmt.Errorf("shader variant %s: %v", name, err)
+		}
+
+		if s.variants == nil {
+			s.variants = make(map[string]*Shader)
+		}
+		s.variants[name] = v
+	}
+
+	return nil
 }
 
 // Dealloc releases builtin for this shader.
@@ -70,53 +102,83 @@ func (s *Shader) Dealloc() {
 
 		s.programId = 0
 	}
+
+	for name, v := range s.variants {
+		v.Dealloc()
+		delete(s.variants, name)
+	}
 }
 
 func (s *Shader) AddData(newData []byte) {
 	s.data = append(s.data, newData...)
 }
 
+// SetVariant declares a variant of the shader, built from the same sources
+// with the preprocessor symbols defined. Variants are built when the shader is
+// allocated.
+func (s *Shader) SetVariant(name string, defines ...string) {
+	if s.variantDefines == nil {
+		s.variantDefines = make(map[string][]string)
+	}
+
+	s.variantDefines[name] = defines
+}
+
+// Variant returns the built variant of the shader, or nil if there is none.
+func (s *Shader) Variant(name string) *Shader {
+	return s.variants[name]
+}
+
 func (s *Shader) Build() error {
 	// Create Program ID
 	s.programId = gl.CreateProgram()
 
-	if containsShaderType(ShaderComponentVertex, s.data) {
-		componentId, err := loadComponent(s.programId, ShaderComponentVertex, s.data)
+	data := s.data
+	if len(s.defines) != 0 {
+		var header []byte
+		for i := range s.defines {
+			header = append(header, []byte("#define "+s.defines[i]+"\n")...)
+		}
+		data = append(header, s.data...)
+	}
+
+	if containsShaderType(ShaderComponentVertex, data) {
+		componentId, err := loadComponent(s.programId, ShaderComponentVertex, data)
 		if err != nil {
 			return err
 		}
 		s.components[ShaderComponentVertex] = componentId
 	}
-	if containsShaderType(ShaderComponentGeometry, s.data) {
-		componentId, err := loadComponent(s.programId, ShaderComponentGeometry, s.data)
+	if containsShaderType(ShaderComponentGeometry, data) {
+		componentId, err := loadComponent(s.programId, ShaderComponentGeometry, data)
 		if err != nil {
 			return err
 		}
 		s.components[ShaderComponentGeometry] = componentId
 	}
-	if containsShaderType(ShaderComponentFragment, s.data) {
-		componentId, err := loadComponent(s.programId, ShaderComponentFragment, s.data)
+	if containsShaderType(ShaderComponentFragment, data) {
+		componentId, err := loadComponent(s.programId, ShaderComponentFragment, data)
 		if err != nil {
 			return err
 		}
 		s.components[ShaderComponentFragment] = componentId
 	}
-	if containsShaderType(ShaderComponentCompute, s.data) {
-		componentId, err := loadComponent(s.programId, ShaderComponentCompute, s.data)
+	if containsShaderType(ShaderComponentCompute, data) {
+		componentId, err := loadComponent(s.programId, ShaderComponentCompute, data)
 		if err != nil {
 			return err
 		}
 		s.components[ShaderComponentCompute] = componentId
 	}
-	if containsShaderType(ShaderComponentTessControl, s.data) {
-		componentId, err := loadComponent(s.programId, ShaderComponentTessControl, s.data)
+	if containsShaderType(ShaderComponentTessControl, data) {
+		componentId, err := loadComponent(s.programId, ShaderComponentTessControl, data)
 		if err != nil {
 			return err
 		}
 		s.components[ShaderComponentTessControl] = componentId
 	}
-	if containsShaderType(ShaderComponentTessEvaluation, s.data) {
-		componentId, err := loadComponent(s.programId, ShaderComponentTessEvaluation, s.data)
+	if containsShaderType(ShaderComponentTessEvaluation, data) {
+		componentId, err := loadComponent(s.programId, ShaderComponentTessEvaluation, data)
 		if err != nil {
 			return err
 		}
@@ -179,6 +241,10 @@ func (s *Shader) SetUniform(uniformName string, value interface{}) {
 		gl.UniformMatrix3fv(gl.GetUniformLocation(s.programId, gl.Str(uniformName+"\x00")), 1, false, &v[0])
 	case mgl32.Mat4:
 		gl.UniformMatrix4fv(gl.GetUniformLocation(s.programId, gl.Str(uniformName+"\x00")), 1, false, &v[0])
+	case []mgl32.Mat4:
+		if len(v) != 0 {
+			gl.UniformMatrix4fv(gl.GetUniformLocation(s.programId, gl.Str(uniformName+"\x00")), int32(len(v)), false, &v[0][0])
+		}
 	}
 }
 
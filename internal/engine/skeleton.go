@@ -0,0 +1,145 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Skeleton errors
+const (
+	ErrSkeletonParent = Error("skeleton: joint parent must precede the joint")
+	ErrSkeletonJoints = Error("skeleton: too many joints")
+)
+
+// MaxSkinJoints is the number of joint matrices supported by the skinning
+// shaders.
+const MaxSkinJoints = 64
+
+// Joint is a bone of a skeleton. Parent is the index of the parent joint, or
+// -1 for a root. InverseBind transforms from mesh space to the space of the
+// joint in its bind pose, and Pose is its local bind pose.
+type Joint struct {
+	Name        string
+	Parent      int
+	InverseBind mgl32.Mat4
+	Pose        JointPose
+}
+
+// JointPose is the local transform of a joint, relative to its parent.
+type JointPose struct {
+	Position mgl32.Vec3
+	Rotation mgl32.Quat
+	Scale    mgl32.Vec3
+}
+
+// Matrix returns the transform matrix of the pose.
+func (p JointPose) Matrix() mgl32.Mat4 {
+	return mgl32.Translate3D(p.Position[0], p.Position[1], p.Position[2]).
+		Mul4(p.Rotation.Mat4()).
+		Mul4(mgl32.Scale3D(p.Scale[0], p.Scale[1], p.Scale[2]))
+}
+
+// Skeleton is a hierarchy of joints which deform a skinned mesh. Joints are
+// ordered so that each parent comes before its children. Skeletons only
+// describe the joints; the Skin component poses them with game objects.
+type Skeleton struct {
+	BaseObject
+
+	joints []Joint
+	names  map[string]int
+}
+
+// NewSkeleton creates a new skeleton from the joints.
+func NewSkeleton(joints []Joint) (*Skeleton, error) {
+	if len(joints) > MaxSkinJoints {
+		return nil, ErrSkeletonJoints
+	}
+
+	s := &Skeleton{
+		joints: joints,
+		names:  make(map[string]int, len(joints)),
+	}
+
+	for i := range joints {
+		if joints[i].Parent >= i || joints[i].Parent < -1 {
+			return nil, ErrSkeletonParent
+		}
+		if joints[i].Name != "" {
+			s.names[joints[i].Name] = i
+		}
+	}
+
+	s.SetName("Skeleton")
+	GetInstance().MustAssign(s)
+
+	return s, nil
+}
+
+// Joints returns the joints of the skeleton.
+func (s *Skeleton) Joints() []Joint {
+	return s.joints
+}
+
+// JointIndex returns the index of the named joint, or -1 if there is none.
+func (s *Skeleton) JointIndex(name string) int {
+	if i, ok := s.names[name]; ok {
+		return i
+	}
+
+	return -1
+}
+
+// BindPose returns the bind pose of the joints, written into pose, which is
+// grown as required.
+func (s *Skeleton) BindPose(pose []JointPose) []JointPose {
+	pose = pose[:0]
+	for i := range s.joints {
+		pose = append(pose, s.joints[i].Pose)
+	}
+
+	return pose
+}
+
+// Palette returns the skinning matrices of the pose, written into palette,
+// which is grown as required. Each matrix transforms a vertex from mesh space
+// in the bind pose to mesh space in the posed skeleton.
+func (s *Skeleton) Palette(pose []JointPose, palette []mgl32.Mat4) []mgl32.Mat4 {
+	palette = palette[:0]
+
+	// Resolve the joint matrices in mesh space first. Parents precede their
+	// children, so the parent matrix is always available.
+	for i := range s.joints {
+		m := pose[i].Matrix()
+		if p := s.joints[i].Parent; p >= 0 {
+			m = palette[p].Mul4(m)
+		}
+		palette = append(palette, m)
+	}
+
+	for i := range palette {
+		palette[i] = palette[i].Mul4(s.joints[i].InverseBind)
+	}
+
+	return palette
+}
@@ -0,0 +1,166 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// newTestSkeleton creates a chain of two joints: a root at the origin, and an
+// arm one unit above it.
+func newTestSkeleton(t *testing.T) *Skeleton {
+	identity := JointPose{Rotation: mgl32.QuatIdent(), Scale: mgl32.Vec3{1, 1, 1}}
+	arm := identity
+	arm.Position = mgl32.Vec3{0, 1, 0}
+
+	s, err := NewSkeleton([]Joint{
+		{Name: "root", Parent: -1, InverseBind: mgl32.Ident4(), Pose: identity},
+		{Name: "arm", Parent: 0, InverseBind: mgl32.Translate3D(0, -1, 0), Pose: arm},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return s
+}
+
+func TestSkeleton_Palette(t *testing.T) {
+	a := newHeadlessApp(t)
+	defer a.Teardown()
+
+	s := newTestSkeleton(t)
+
+	// The bind pose does not deform the mesh.
+	for i, m := range s.Palette(s.BindPose(nil), nil) {
+		if !m.ApproxEqualThreshold(mgl32.Ident4(), 1e-5) {
+			t.Errorf("joint %d: expected identity in bind pose, got: %v", i, m)
+		}
+	}
+
+	// Turn the root, and scale the arm.
+	pose := s.BindPose(nil)
+	pose[0].Rotation = mgl32.QuatRotate(mgl32.DegToRad(90), mgl32.Vec3{0, 0, 1})
+	pose[1].Scale = mgl32.Vec3{2, 2, 2}
+
+	palette := s.Palette(pose, nil)
+	if len(palette) != 2 {
+		t.Fatalf("expected 2 matrices, got: %d", len(palette))
+	}
+
+	// A vertex at the tip of the arm is rotated with the root, and moved away
+	// by the scale of the arm.
+	tip := palette[1].Mul4x1(mgl32.Vec4{0, 2, 0, 1}).Vec3()
+	if !nearVec3(tip, mgl32.Vec3{-3, 0, 0}) {
+		t.Errorf("expected tip at (-3, 0, 0), got: %v", tip)
+	}
+
+	root := palette[0].Mul4x1(mgl32.Vec4{1, 0, 0, 1}).Vec3()
+	if !nearVec3(root, mgl32.Vec3{0, 1, 0}) {
+		t.Errorf("expected root vertex at (0, 1, 0), got: %v", root)
+	}
+}
+
+func TestSkeleton_Invalid(t *testing.T) {
+	a := newHeadlessApp(t)
+	defer a.Teardown()
+
+	if _, err := NewSkeleton([]Joint{{Name: "child", Parent: 1}, {Name: "root", Parent: -1}}); err != ErrSkeletonParent {
+		t.Errorf("expected ErrSkeletonParent, got: %v", err)
+	}
+	if _, err := NewSkeleton(make([]Joint, MaxSkinJoints+1)); err != ErrSkeletonJoints {
+		t.Errorf("expected ErrSkeletonJoints, got: %v", err)
+	}
+}
+
+func TestSkin_Animator(t *testing.T) {
+	a := newHeadlessApp(t)
+	defer a.Teardown()
+
+	skin := NewSkin()
+	skin.SetSkeleton(newTestSkeleton(t))
+
+	clip := NewAnimationClip([]AnimationCurve{
+		{Path: "armature/root", Property: AnimationPosition, Keys: []Keyframe{
+			{Time: 0, Value: []float32{0, 0, 0}},
+			{Time: 2, Value: []float32{4, 0, 0}},
+		}},
+	})
+	animator := NewAnimator()
+
+	// The joints are game objects under an armature, beside the skinned mesh.
+	// Both are moved away, to check that the palette is in mesh space.
+	model := NewGameObject("model")
+	model.AddComponent(animator)
+	skin.SetRoot(model)
+
+	armature := NewGameObject("armature")
+	armature.Transform().SetPosition(mgl32.Vec3{0, 0, 5})
+	root := NewGameObject("root")
+	arm := NewGameObject("arm")
+	arm.Transform().SetPosition(mgl32.Vec3{0, 1, 0})
+	root.AddChild(arm)
+	armature.AddChild(root)
+	model.AddChild(armature)
+
+	mesh := NewGameObject("mesh")
+	mesh.Transform().SetPosition(mgl32.Vec3{0, 0, 5})
+	mesh.AddComponent(skin)
+	model.AddChild(mesh)
+
+	s := NewScene("test")
+	s.SetLoadFunc(func() error {
+		return s.Graph().AddGameObject(model, nil)
+	})
+	if err := a.RegisterScene(s); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.PushScene("test"); err != nil {
+		t.Fatal(err)
+	}
+
+	joints := skin.Joints()
+	if len(joints) != 2 || joints[0] != root || joints[1] != arm {
+		t.Fatalf("expected joints root and arm, got: %v", joints)
+	}
+
+	// The bind pose does not deform the mesh.
+	for i, m := range skin.Palette() {
+		if !m.ApproxEqualThreshold(mgl32.Ident4(), 1e-5) {
+			t.Errorf("joint %d: expected identity in bind pose, got: %v", i, m)
+		}
+	}
+
+	animator.Play(clip, true)
+	animator.Advance(0.5)
+	if p := skin.Palette()[1].Col(3).Vec3(); !nearVec3(p, mgl32.Vec3{1, 0, 0}) {
+		t.Errorf("expected arm offset (1, 0, 0), got: %v", p)
+	}
+
+	// The skin follows the animator as the app steps.
+	a.Step(1)
+	if p := skin.Palette()[0].Col(3).Vec3(); !nearVec3(p, root.Transform().Position()) {
+		t.Errorf("expected root offset %v, got: %v", root.Transform().Position(), p)
+	}
+}
@@ -0,0 +1,161 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+var _ SceneGraphListener = &Skin{}
+
+// Skin is a component which deforms a skinned mesh with the joints of a
+// skeleton, and provides the joint matrices for the skinning shaders. Joints
+// are the game objects below the root named by the joints of the skeleton, so
+// they are posed like any other game object, such as by an Animator.
+type Skin struct {
+	BaseComponent
+
+	skeleton *Skeleton
+	root     *GameObject
+	joints   []*GameObject
+	resolved bool
+	palette  []mgl32.Mat4
+}
+
+// NewSkin creates a new Skin component.
+func NewSkin() *Skin {
+	s := &Skin{}
+
+	s.SetName("Skin")
+	GetInstance().MustAssign(s)
+
+	return s
+}
+
+// SkinComponent gets the first occurrence of Skin from the game object.
+func SkinComponent(g *GameObject) *Skin {
+	return GetComponent[*Skin](g)
+}
+
+// Skeleton returns the skeleton of the skin.
+func (s *Skin) Skeleton() *Skeleton {
+	return s.skeleton
+}
+
+// SetSkeleton sets the skeleton of the skin.
+func (s *Skin) SetSkeleton(skeleton *Skeleton) {
+	s.skeleton = skeleton
+	s.Rebind()
+}
+
+// Root returns the game object below which the joints are found. It is the
+// game object of the skin unless set.
+func (s *Skin) Root() *GameObject {
+	if s.root != nil {
+		return s.root
+	}
+
+	return s.GameObject()
+}
+
+// SetRoot sets the game object below which the joints are found.
+func (s *Skin) SetRoot(root *GameObject) {
+	s.root = root
+	s.Rebind()
+}
+
+// Rebind finds the joints again. It is called when the scene graph changes.
+func (s *Skin) Rebind() {
+	s.joints = s.joints[:0]
+	s.resolved = false
+}
+
+// OnSceneGraphUpdate finds the joints again, as they may have been replaced.
+func (s *Skin) OnSceneGraphUpdate() {
+	s.Rebind()
+}
+
+// Joints returns the game object of each joint of the skeleton, or nil for
+// joints which are not found.
+func (s *Skin) Joints() []*GameObject {
+	if s.resolved {
+		return s.joints
+	}
+	s.resolved = true
+
+	root := s.Root()
+	if s.skeleton == nil || root == nil {
+		return s.joints
+	}
+
+	joints := s.skeleton.Joints()
+	for i := range joints {
+		s.joints = append(s.joints, findDescendant(root, joints[i].Name))
+	}
+
+	return s.joints
+}
+
+// Palette returns the joint matrices, one for each joint of the skeleton. Each
+// matrix transforms a vertex from the space of the mesh in its bind pose to
+// the space of the mesh with the joints as currently posed. Joints which are
+// not found do not deform the mesh.
+func (s *Skin) Palette() []mgl32.Mat4 {
+	s.palette = s.palette[:0]
+	if s.skeleton == nil {
+		return s.palette
+	}
+
+	mesh := mgl32.Ident4()
+	if t := s.GetTransform(); t != nil {
+		mesh = t.ActiveMatrix().Inv()
+	}
+
+	joints := s.skeleton.Joints()
+	for i, o := range s.Joints() {
+		if o == nil {
+			s.palette = append(s.palette, mgl32.Ident4())
+			continue
+		}
+
+		m := mesh.Mul4(o.Transform().ActiveMatrix()).Mul4(joints[i].InverseBind)
+		s.palette = append(s.palette, m)
+	}
+
+	return s.palette
+}
+
+// findDescendant returns the first game object named name below o, searching
+// depth first.
+func findDescendant(o *GameObject, name string) *GameObject {
+	for _, c := range o.Children() {
+		if c.Name() == name {
+			return c
+		}
+		if d := findDescendant(c, name); d != nil {
+			return d
+		}
+	}
+
+	return nil
+}
@@ -41,6 +41,11 @@ func Get(kind, name string) (engine.Object, error) {
 	return engine.GetAsset().Get(kind, name)
 }
 
+// Name returns the name the object is tracked by in the handler of the kind.
+func Name(kind string, object engine.Object) (string, error) {
+	return engine.GetAsset().AssetName(kind, object)
+}
+
 // // MustGet is like Get, but panics if an error is encountered.
 func MustGet(kind, name string) engine.Object {
 	return engine.GetAsset().MustGet(kind, name)
@@ -68,3 +73,24 @@ func LoadManifest(files ...string) error {
 func ReadResource(r *engine.Resource) error {
 	return engine.GetAsset().ReadResource(r)
 }
+
+// Ref adds a reference to an asset.
+func Ref(object engine.Object) {
+	engine.GetAsset().Ref(object)
+}
+
+// Unref removes a reference to an asset.
+func Unref(object engine.Object) {
+	engine.GetAsset().Unref(object)
+}
+
+// Unload releases an asset which is no longer referenced.
+func Unload(kind, name string) error {
+	return engine.GetAsset().Unload(kind, name)
+}
+
+// UnloadUnused unloads all assets loaded from files which are no longer
+// referenced.
+func UnloadUnused() int {
+	return engine.GetAsset().UnloadUnused()
+}
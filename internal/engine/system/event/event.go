@@ -0,0 +1,49 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package event
+
+import "github.com/haakenlabs/forge/internal/engine"
+
+func Subscribe[E any](object *engine.GameObject, handler func(E)) *engine.Subscription {
+	return engine.Subscribe(object, handler)
+}
+
+func SubscribeScene[E any](scene *engine.Scene, handler func(E)) *engine.Subscription {
+	return engine.SubscribeScene(scene, handler)
+}
+
+func SubscribeGlobal[E any](handler func(E)) *engine.Subscription {
+	return engine.SubscribeGlobal(handler)
+}
+
+func Publish(scope engine.EventScope, event interface{}) {
+	engine.GetEvents().Publish(scope, event)
+}
+
+func Queue(scope engine.EventScope, event interface{}) {
+	engine.GetEvents().Queue(scope, event)
+}
+
+func Flush() {
+	engine.GetEvents().Flush()
+}
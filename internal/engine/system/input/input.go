@@ -73,6 +73,12 @@ func MousePosition() mgl32.Vec2 {
 	return engine.GetWindow().MousePosition()
 }
 
+// Cursor returns the position of the mouse cursor in window coordinates, as
+// of the last input update.
+func Cursor() mgl32.Vec2 {
+	return engine.GetInput().Cursor()
+}
+
 func WindowResized() bool {
 	return engine.GetWindow().WindowResized()
 }
@@ -88,3 +94,91 @@ func HandleEvents() {
 func HasEvents() bool {
 	return engine.GetWindow().HasEvents()
 }
+
+func DefineAction(name string, defaults ...engine.Binding) *engine.Action {
+	return engine.GetInput().DefineAction(name, defaults...)
+}
+
+func SetBindings(name string, bindings ...engine.Binding) *engine.Action {
+	return engine.GetInput().SetBindings(name, bindings...)
+}
+
+func Rebind(name string, index int, b engine.Binding) error {
+	return engine.GetInput().Rebind(name, index, b)
+}
+
+func Action(name string) *engine.Action {
+	return engine.GetInput().Action(name)
+}
+
+func Down(name string) bool {
+	return engine.GetInput().Down(name)
+}
+
+func Pressed(name string) bool {
+	return engine.GetInput().Pressed(name)
+}
+
+func Released(name string) bool {
+	return engine.GetInput().Released(name)
+}
+
+func Value(name string) float32 {
+	return engine.GetInput().Value(name)
+}
+
+func Vector(name string) mgl32.Vec2 {
+	return engine.GetInput().Vector(name)
+}
+
+func LastControl() (engine.Binding, bool) {
+	return engine.GetInput().LastControl()
+}
+
+func LoadBindingFile(filename string) error {
+	return engine.GetInput().LoadBindingFile(filename)
+}
+
+func Gamepad(joy glfw.Joystick) *engine.Gamepad {
+	return engine.GetInput().Gamepad(joy)
+}
+
+func Gamepads() []*engine.Gamepad {
+	return engine.GetInput().Gamepads()
+}
+
+func Connected() []*engine.Gamepad {
+	return engine.GetInput().Connected()
+}
+
+func Disconnected() []glfw.Joystick {
+	return engine.GetInput().Disconnected()
+}
+
+func SetGamepadMapping(name string, m engine.GamepadMapping) {
+	engine.GetInput().SetGamepadMapping(name, m)
+}
+
+func OnGamepadConnected(handler func(engine.GamepadConnectedEvent)) *engine.Subscription {
+	return engine.SubscribeGlobal(handler)
+}
+
+func OnGamepadDisconnected(handler func(engine.GamepadDisconnectedEvent)) *engine.Subscription {
+	return engine.SubscribeGlobal(handler)
+}
+
+func Text() string {
+	return engine.GetInput().Text()
+}
+
+func TextEvents() []engine.TextEvent {
+	return engine.GetInput().TextEvents()
+}
+
+func ClipboardText() string {
+	return engine.GetInput().ClipboardText()
+}
+
+func SetClipboardText(s string) {
+	engine.GetInput().SetClipboardText(s)
+}
@@ -25,17 +25,45 @@ package time
 import "github.com/haakenlabs/forge/internal/engine"
 
 func FrameTime() float64 {
-	return engine.GetTime().FixedTime()
+	return engine.GetTime().FrameTime()
 }
 
 func DeltaTime() float64 {
 	return engine.GetTime().DeltaTime()
 }
 
+func UnscaledDeltaTime() float64 {
+	return engine.GetTime().UnscaledDeltaTime()
+}
+
 func FixedTime() float64 {
 	return engine.GetTime().FixedTime()
 }
 
+func SetFixedTime(step float64) {
+	engine.GetTime().SetFixedTime(step)
+}
+
+func Scale() float64 {
+	return engine.GetTime().Scale()
+}
+
+func SetScale(scale float64) {
+	engine.GetTime().SetScale(scale)
+}
+
+func Paused() bool {
+	return engine.GetTime().Paused()
+}
+
+func SetPaused(paused bool) {
+	engine.GetTime().SetPaused(paused)
+}
+
+func Alpha() float64 {
+	return engine.GetTime().Alpha()
+}
+
 func Delta() float64 {
 	return engine.GetTime().Delta()
 }
@@ -0,0 +1,47 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package tween
+
+import "github.com/haakenlabs/forge/internal/engine"
+
+// To tweens the target from its value when the tween starts to end, over the
+// duration in seconds, with an easing function of the math package.
+func To[V engine.TweenValue](target *V, end V, duration float64, ease func(float64) float64) *engine.Tween {
+	return engine.TweenTo(target, end, duration, ease)
+}
+
+func Wait(duration float64) *engine.Tween {
+	return engine.TweenWait(duration)
+}
+
+func Sequence(steps ...*engine.Tween) *engine.Tween {
+	return engine.TweenSequence(steps...)
+}
+
+func Count() int {
+	return engine.GetTweens().Count()
+}
+
+func CancelAll() {
+	engine.GetTweens().CancelAll()
+}
@@ -23,6 +23,8 @@ SOFTWARE.
 package engine
 
 import (
+	"math"
+
 	"github.com/go-gl/glfw/v3.2/glfw"
 )
 
@@ -30,12 +32,64 @@ var _ System = &Time{}
 
 const SysNameTime = "time"
 
-// Time implements a time system.
+// Clock is a source of time, in seconds.
+type Clock interface {
+	// Now returns the current time of this clock.
+	Now() float64
+}
+
+// glfwClock is a Clock backed by the GLFW timer.
+type glfwClock struct{}
+
+// Now returns the current time of this clock.
+func (c *glfwClock) Now() float64 {
+	return glfw.GetTime()
+}
+
+// ManualClock is a deterministic Clock which only advances when told to.
+type ManualClock struct {
+	now float64
+}
+
+// Now returns the current time of this clock.
+func (c *ManualClock) Now() float64 {
+	return c.now
+}
+
+// Advance moves the clock forward by d seconds.
+func (c *ManualClock) Advance(d float64) {
+	c.now += d
+}
+
+// Set sets the current time of this clock.
+func (c *ManualClock) Set(now float64) {
+	c.now = now
+}
+
+// NewManualClock creates a new ManualClock starting at zero.
+func NewManualClock() *ManualClock {
+	return &ManualClock{}
+}
+
+// fixedTimeEpsilon is the tolerance of fixed steps, so rounding errors of
+// the frame times do not delay a step by a whole frame.
+const fixedTimeEpsilon = 1e-9
+
+// Time implements a time system. Frame times are scaled by the time scale,
+// and fixed steps are taken at a regular interval of scaled time. While
+// paused, the scaled time does not advance.
 type Time struct {
+	clock         Clock
 	frameTime     float64
 	deltaTime     float64
-	nextLogicTick float64
+	unscaledDelta float64
+	fixedTime     float64
+	accumulator   float64
+	scale         float64
+	maxFrameSkip  int
+	ticks         int
 	frame         uint64
+	paused        bool
 }
 
 // Setup sets up the System.
@@ -53,16 +107,90 @@ func (t *Time) Name() string {
 	return SysNameTime
 }
 
+// FrameTime returns the clock time at the start of the frame.
 func (t *Time) FrameTime() float64 {
 	return t.frameTime
 }
 
+// DeltaTime returns the scaled duration of the last frame, or zero while
+// paused.
 func (t *Time) DeltaTime() float64 {
 	return t.deltaTime
 }
 
+// UnscaledDeltaTime returns the duration of the last frame, regardless of the
+// time scale and pause.
+func (t *Time) UnscaledDeltaTime() float64 {
+	return t.unscaledDelta
+}
+
+// FixedTime returns the interval of the fixed steps, in seconds of scaled
+// time.
 func (t *Time) FixedTime() float64 {
-	return fixedTime
+	return t.fixedTime
+}
+
+// SetFixedTime sets the interval of the fixed steps. Intervals which are not
+// positive are ignored.
+func (t *Time) SetFixedTime(step float64) {
+	if step > 0 {
+		t.fixedTime = step
+	}
+}
+
+// MaxFrameSkip returns the maximum number of fixed steps taken in a frame.
+func (t *Time) MaxFrameSkip() int {
+	return t.maxFrameSkip
+}
+
+// SetMaxFrameSkip sets the maximum number of fixed steps taken in a frame. If
+// more steps are due, the remaining time is dropped, so a slow frame does not
+// make the following frames slower. Values below one are ignored.
+func (t *Time) SetMaxFrameSkip(n int) {
+	if n > 0 {
+		t.maxFrameSkip = n
+	}
+}
+
+// Scale returns the time scale.
+func (t *Time) Scale() float64 {
+	return t.scale
+}
+
+// SetScale sets the time scale, such as 0.5 for slow motion. Negative scales
+// are clamped to zero.
+func (t *Time) SetScale(scale float64) {
+	if scale < 0 {
+		scale = 0
+	}
+
+	t.scale = scale
+}
+
+// Paused reports whether the scaled time is paused.
+func (t *Time) Paused() bool {
+	return t.paused
+}
+
+// SetPaused pauses or resumes the scaled time. While paused, the delta time is
+// zero and no fixed steps are taken, but frames are still updated.
+func (t *Time) SetPaused(paused bool) {
+	t.paused = paused
+}
+
+// Alpha returns the fraction of a fixed step elapsed since the last fixed
+// step, between 0 and 1. It is used to interpolate between the states of the
+// last two fixed steps when rendering.
+func (t *Time) Alpha() float64 {
+	a := t.accumulator / t.fixedTime
+	if a < 0 {
+		return 0
+	}
+	if a > 1 {
+		return 1
+	}
+
+	return a
 }
 
 func (t *Time) Delta() float64 {
@@ -70,15 +198,29 @@ func (t *Time) Delta() float64 {
 }
 
 func (t *Time) Now() float64 {
-	return glfw.GetTime()
+	return t.clock.Now()
+}
+
+// Clock returns the time source of this system.
+func (t *Time) Clock() Clock {
+	return t.clock
 }
 
 func (t *Time) FrameStart() {
 	t.frameTime = t.Now()
+	t.ticks = 0
 }
 
 func (t *Time) FrameEnd() {
-	t.deltaTime = t.Now() - t.frameTime
+	t.unscaledDelta = t.Now() - t.frameTime
+
+	if t.paused {
+		t.deltaTime = 0
+	} else {
+		t.deltaTime = t.unscaledDelta * t.scale
+	}
+
+	t.accumulator += t.deltaTime
 	t.frame++
 }
 
@@ -86,17 +228,39 @@ func (t *Time) Frame() uint64 {
 	return t.frame
 }
 
+// LogicTick consumes the time of a fixed step.
 func (t *Time) LogicTick() {
-	t.nextLogicTick += fixedTime
+	t.accumulator -= t.fixedTime
+	t.ticks++
 }
 
+// LogicUpdate reports whether a fixed step is due in this frame.
 func (t *Time) LogicUpdate() bool {
-	return t.Now() > t.nextLogicTick
+	if t.accumulator+fixedTimeEpsilon < t.fixedTime {
+		return false
+	}
+
+	if t.ticks >= t.maxFrameSkip {
+		t.accumulator = math.Mod(t.accumulator, t.fixedTime)
+		return false
+	}
+
+	return true
 }
 
 // NewTime creates a new time system.
 func NewTime() *Time {
-	return &Time{}
+	return NewTimeWithClock(&glfwClock{})
+}
+
+// NewTimeWithClock creates a new time system driven by the given clock.
+func NewTimeWithClock(clock Clock) *Time {
+	return &Time{
+		clock:        clock,
+		fixedTime:    fixedTime,
+		maxFrameSkip: maxFrameSkip,
+		scale:        1,
+	}
 }
 
 // GetTime gets the time system from the current app.
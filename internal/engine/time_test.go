@@ -0,0 +1,98 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"testing"
+)
+
+// stepTime runs a frame of the time system lasting d seconds of the clock,
+// and returns the number of fixed steps taken.
+func stepTime(tm *Time, clock *ManualClock, d float64) int {
+	tm.FrameStart()
+
+	n := 0
+	for tm.LogicUpdate() {
+		tm.LogicTick()
+		n++
+	}
+
+	clock.Advance(d)
+	tm.FrameEnd()
+
+	return n
+}
+
+func TestTime_ScaleAndPause(t *testing.T) {
+	clock := NewManualClock()
+	tm := NewTimeWithClock(clock)
+	tm.SetFixedTime(0.1)
+
+	stepTime(tm, clock, 0.05)
+	if n := stepTime(tm, clock, 0.05); n != 0 {
+		t.Errorf("expected no fixed step after half a step, got: %d", n)
+	}
+	if a := tm.Alpha(); !nearFloat(float32(a), 1) {
+		t.Errorf("expected alpha 1, got: %v", a)
+	}
+	if n := stepTime(tm, clock, 0.05); n != 1 {
+		t.Errorf("expected 1 fixed step, got: %d", n)
+	}
+	if a := tm.Alpha(); !nearFloat(float32(a), 0.5) {
+		t.Errorf("expected alpha 0.5, got: %v", a)
+	}
+
+	// At half speed, fixed steps are taken half as often.
+	tm.SetScale(0.5)
+	steps := 0
+	for i := 0; i < 8; i++ {
+		steps += stepTime(tm, clock, 0.05)
+	}
+	if steps != 2 {
+		t.Errorf("expected 2 fixed steps at half speed, got: %d", steps)
+	}
+	if !nearFloat(float32(tm.DeltaTime()), 0.025) || !nearFloat(float32(tm.UnscaledDeltaTime()), 0.05) {
+		t.Errorf("expected delta 0.025 and unscaled delta 0.05, got: %v %v", tm.DeltaTime(), tm.UnscaledDeltaTime())
+	}
+
+	// Paused time does not advance.
+	tm.SetScale(1)
+	tm.SetPaused(true)
+	steps = 0
+	for i := 0; i < 8; i++ {
+		steps += stepTime(tm, clock, 0.05)
+	}
+	if steps != 0 || tm.DeltaTime() != 0 || !nearFloat(float32(tm.UnscaledDeltaTime()), 0.05) {
+		t.Errorf("expected no fixed steps while paused, got: %d, delta %v", steps, tm.DeltaTime())
+	}
+	tm.SetPaused(false)
+
+	// A long frame takes at most MaxFrameSkip steps, and drops the rest.
+	stepTime(tm, clock, 2)
+	if n := stepTime(tm, clock, 0); n != tm.MaxFrameSkip() {
+		t.Errorf("expected %d fixed steps, got: %d", tm.MaxFrameSkip(), n)
+	}
+	if n := stepTime(tm, clock, 0); n != 0 {
+		t.Errorf("expected dropped backlog, got: %d steps", n)
+	}
+}
@@ -34,14 +34,25 @@ type Transform interface {
 	Rotation() mgl32.Quat
 	Position() mgl32.Vec3
 	Scale() mgl32.Vec3
+	WorldRotation() mgl32.Quat
+	WorldPosition() mgl32.Vec3
 	SetRotation(mgl32.Quat)
 	SetPosition(mgl32.Vec3)
 	SetScale(mgl32.Vec3)
+	LookAt(target, up mgl32.Vec3)
+	TransformPoint(mgl32.Vec3) mgl32.Vec3
+	InverseTransformPoint(mgl32.Vec3) mgl32.Vec3
 	Recompute(bool)
+
+	invalidateWorld(force bool)
 }
 
 // Transform is a component which handles scale, rotation, and
 // position transformations.
+//
+// The local (model) and world (active) matrices are cached, and recomputed
+// when they are next read after a change. Changing a transform invalidates
+// the world matrices of its descendants, which are resolved parent-first.
 type BaseTransform struct {
 	BaseComponent
 
@@ -50,13 +61,36 @@ type BaseTransform struct {
 	rotation     mgl32.Quat
 	position     mgl32.Vec3
 	scale        mgl32.Vec3
+	modelDirty   bool
+	activeDirty  bool
 }
 
+// ModelMatrix returns the local transformation matrix.
 func (t *BaseTransform) ModelMatrix() mgl32.Mat4 {
+	if t.modelDirty {
+		tp := mgl32.Translate3D(t.position.X(), t.position.Y(), t.position.Z())
+		tr := t.rotation.Mat4()
+		ts := mgl32.Scale3D(t.scale.X(), t.scale.Y(), t.scale.Z())
+
+		t.modelMatrix = tp.Mul4(tr.Mul4(ts))
+		t.modelDirty = false
+	}
+
 	return t.modelMatrix
 }
 
+// ActiveMatrix returns the world transformation matrix.
 func (t *BaseTransform) ActiveMatrix() mgl32.Mat4 {
+	if t.activeDirty {
+		t.activeMatrix = t.ModelMatrix()
+
+		if parent := t.parent(); parent != nil {
+			t.activeMatrix = parent.ActiveMatrix().Mul4(t.activeMatrix)
+		}
+
+		t.activeDirty = false
+	}
+
 	return t.activeMatrix
 }
 
@@ -72,19 +106,33 @@ func (t *BaseTransform) Scale() mgl32.Vec3 {
 	return t.scale
 }
 
+// WorldRotation returns the rotation in world space.
+func (t *BaseTransform) WorldRotation() mgl32.Quat {
+	if parent := t.parent(); parent != nil {
+		return parent.WorldRotation().Mul(t.rotation).Normalize()
+	}
+
+	return t.rotation
+}
+
+// WorldPosition returns the position in world space.
+func (t *BaseTransform) WorldPosition() mgl32.Vec3 {
+	return t.ActiveMatrix().Col(3).Vec3()
+}
+
 func (t *BaseTransform) SetRotation(rotation mgl32.Quat) {
 	t.rotation = rotation
-	t.Recompute(true)
+	t.invalidate()
 }
 
 func (t *BaseTransform) SetPosition(position mgl32.Vec3) {
 	t.position = position
-	t.Recompute(true)
+	t.invalidate()
 }
 
 func (t *BaseTransform) SetScale(scale mgl32.Vec3) {
 	t.scale = scale
-	t.Recompute(true)
+	t.invalidate()
 }
 
 func (t *BaseTransform) SetRotationN(rotation mgl32.Quat) {
@@ -99,28 +147,112 @@ func (t *BaseTransform) SetScaleN(scale mgl32.Vec3) {
 	t.scale = scale
 }
 
+// LookAt rotates the transform so its forward axis (-Z) points at the target,
+// both in world space.
+func (t *BaseTransform) LookAt(target, up mgl32.Vec3) {
+	eye := t.WorldPosition()
+	if target.ApproxEqual(eye) {
+		return
+	}
+
+	rotation := lookRotation(target.Sub(eye).Normalize(), up)
+	if parent := t.parent(); parent != nil {
+		rotation = parent.WorldRotation().Inverse().Mul(rotation)
+	}
+
+	t.SetRotation(rotation.Normalize())
+}
+
+// TransformPoint transforms a point from local space to world space.
+func (t *BaseTransform) TransformPoint(point mgl32.Vec3) mgl32.Vec3 {
+	return mgl32.TransformCoordinate(point, t.ActiveMatrix())
+}
+
+// InverseTransformPoint transforms a point from world space to local space.
+func (t *BaseTransform) InverseTransformPoint(point mgl32.Vec3) mgl32.Vec3 {
+	return mgl32.TransformCoordinate(point, t.ActiveMatrix().Inv())
+}
+
+// Recompute marks the local matrix and the world matrices of the transform
+// and its descendants for recomputation. If updateChildren is set, every
+// descendant is invalidated, even if it was invalidated before; this is
+// required after the hierarchy changes.
 func (t *BaseTransform) Recompute(updateChildren bool) {
-	tp := mgl32.Translate3D(t.position.X(), t.position.Y(), t.position.Z())
-	tr := t.rotation.Mat4()
-	ts := mgl32.Scale3D(t.scale.X(), t.scale.Y(), t.scale.Z())
+	t.modelDirty = true
+	t.invalidateWorld(updateChildren)
+}
+
+// invalidate marks the local matrix for recomputation after a change.
+func (t *BaseTransform) invalidate() {
+	t.modelDirty = true
+	t.invalidateWorld(false)
+}
+
+// invalidateWorld marks the world matrices of the transform and its
+// descendants for recomputation. The world matrices of the descendants of an
+// invalid transform are invalid too, so unless force is set, the walk stops
+// at transforms which are already invalid.
+func (t *BaseTransform) invalidateWorld(force bool) {
+	if t.activeDirty && !force {
+		return
+	}
+	t.activeDirty = true
+
+	if t.GameObject() == nil {
+		return
+	}
 
-	t.modelMatrix = tp.Mul4(tr.Mul4(ts))
-	t.activeMatrix = t.modelMatrix
+	children := t.GameObject().Children()
+	for i := range children {
+		children[i].Transform().invalidateWorld(force)
+	}
+}
 
+// parent returns the transform of the parent game object, if any.
+func (t *BaseTransform) parent() Transform {
 	if t.GameObject() != nil {
 		if parent := t.GameObject().Parent(); parent != nil {
-			t.activeMatrix = parent.Transform().ActiveMatrix().Mul4(t.modelMatrix)
+			return parent.Transform()
 		}
+	}
 
-		if updateChildren {
-			childComponents := t.GameObject().ComponentsInChildren()
-			for idx := range childComponents {
-				if child, ok := childComponents[idx].(Transform); ok {
-					child.Recompute(false)
-				}
-			}
+	return nil
+}
+
+// lookRotation returns the rotation which turns the forward axis (-Z) to the
+// direction, keeping the up axis (+Y) as close to up as possible.
+func lookRotation(direction, up mgl32.Vec3) mgl32.Quat {
+	rotation := mgl32.QuatBetweenVectors(mgl32.Vec3{0, 0, -1}, direction)
+
+	right := direction.Cross(up)
+	if right.Len() < 1e-6 {
+		return rotation
+	}
+	up = right.Cross(direction).Normalize()
+
+	current := rotation.Rotate(mgl32.Vec3{0, 1, 0})
+
+	return mgl32.QuatBetweenVectors(current, up).Mul(rotation)
+}
+
+// decomposeMatrix splits an affine transformation matrix without shear into
+// its position, rotation and scale.
+func decomposeMatrix(m mgl32.Mat4) (position mgl32.Vec3, rotation mgl32.Quat, scale mgl32.Vec3) {
+	position = m.Col(3).Vec3()
+	scale = mgl32.Vec3{m.Col(0).Vec3().Len(), m.Col(1).Vec3().Len(), m.Col(2).Vec3().Len()}
+
+	var cols [3]mgl32.Vec3
+	for i := range cols {
+		cols[i] = m.Col(i).Vec3()
+		if scale[i] != 0 {
+			cols[i] = cols[i].Mul(1 / scale[i])
 		}
 	}
+
+	r := mgl32.Mat3FromCols(cols[0], cols[1], cols[2])
+	rotation = mgl32.Mat4ToQuat(r.Mat4()).Normalize()
+
+	return position, rotation, scale
 }
 
 func NewTransform() *BaseTransform {
@@ -0,0 +1,162 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func nearVec3(a, b mgl32.Vec3) bool {
+	return a.Sub(b).Len() < 1e-4
+}
+
+func TestTransform_Hierarchy(t *testing.T) {
+	app := newHeadlessApp(t)
+	defer app.Teardown()
+
+	s := NewScene("test")
+	s.Setup()
+	g := s.Graph()
+
+	a := NewGameObject("a")
+	b := NewGameObject("b")
+	c := NewGameObject("c")
+
+	if err := g.AddGameObject(a, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.AddGameObject(b, a); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.AddGameObject(c, b); err != nil {
+		t.Fatal(err)
+	}
+
+	a.Transform().SetPosition(mgl32.Vec3{1, 0, 0})
+	a.Transform().SetRotation(mgl32.QuatRotate(mgl32.DegToRad(90), mgl32.Vec3{0, 1, 0}))
+	b.Transform().SetScale(mgl32.Vec3{2, 2, 2})
+	c.Transform().SetPosition(mgl32.Vec3{0, 0, -1})
+
+	// -Z in c is -Z*2 in a, which is rotated to -X.
+	if p := c.Transform().WorldPosition(); !nearVec3(p, mgl32.Vec3{-1, 0, 0}) {
+		t.Errorf("expected world position [-1 0 0], got: %v", p)
+	}
+	if p := c.Transform().TransformPoint(mgl32.Vec3{0, 0, -1}); !nearVec3(p, mgl32.Vec3{-3, 0, 0}) {
+		t.Errorf("expected transformed point [-3 0 0], got: %v", p)
+	}
+	if p := c.Transform().InverseTransformPoint(mgl32.Vec3{-3, 0, 0}); !nearVec3(p, mgl32.Vec3{0, 0, -1}) {
+		t.Errorf("expected local point [0 0 -1], got: %v", p)
+	}
+	if r := c.Transform().WorldRotation(); !r.ApproxEqualThreshold(a.Transform().Rotation(), 1e-5) {
+		t.Errorf("expected world rotation %v, got: %v", a.Transform().Rotation(), r)
+	}
+
+	// Moving the root moves the cached world matrices of its descendants.
+	a.Transform().SetPosition(mgl32.Vec3{0, 5, 0})
+	if p := c.Transform().WorldPosition(); !nearVec3(p, mgl32.Vec3{-2, 5, 0}) {
+		t.Errorf("expected world position [-2 5 0], got: %v", p)
+	}
+
+	a.Transform().SetPosition(mgl32.Vec3{0, 6, 0})
+	g.ResolveTransforms()
+	if p := c.Transform().ActiveMatrix().Col(3).Vec3(); !nearVec3(p, mgl32.Vec3{-2, 6, 0}) {
+		t.Errorf("expected world position [-2 6 0], got: %v", p)
+	}
+}
+
+func TestTransform_LookAt(t *testing.T) {
+	app := newHeadlessApp(t)
+	defer app.Teardown()
+
+	s := NewScene("test")
+	s.Setup()
+	g := s.Graph()
+
+	a := NewGameObject("a")
+	b := NewGameObject("b")
+
+	if err := g.AddGameObject(a, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.AddGameObject(b, a); err != nil {
+		t.Fatal(err)
+	}
+
+	a.Transform().SetRotation(mgl32.QuatRotate(mgl32.DegToRad(45), mgl32.Vec3{0, 0, 1}))
+	b.Transform().SetPosition(mgl32.Vec3{1, 1, 1})
+
+	target := mgl32.Vec3{5, -2, 3}
+	up := mgl32.Vec3{0, 1, 0}
+	b.Transform().LookAt(target, up)
+
+	eye := b.Transform().WorldPosition()
+	forward := b.Transform().TransformPoint(mgl32.Vec3{0, 0, -1}).Sub(eye)
+	if want := target.Sub(eye).Normalize(); !nearVec3(forward, want) {
+		t.Errorf("expected forward %v, got: %v", want, forward)
+	}
+
+	right := b.Transform().TransformPoint(mgl32.Vec3{1, 0, 0}).Sub(eye)
+	if d := right.Dot(up); d > 1e-4 || d < -1e-4 {
+		t.Errorf("expected right axis to be level, got: %v", right)
+	}
+}
+
+// BenchmarkTransform_Animate moves every game object of a scene and resolves
+// the world matrices, as one frame of an animated scene.
+func BenchmarkTransform_Animate(b *testing.B) {
+	for _, n := range []int{1000, 10000} {
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			app := newHeadlessApp(b)
+			defer app.Teardown()
+
+			s := NewScene("bench")
+			s.Setup()
+			g := s.Graph()
+
+			objects := make([]*GameObject, n)
+			for i := range objects {
+				objects[i] = NewGameObject(fmt.Sprint(i))
+
+				var parent *GameObject
+				if i > 0 {
+					parent = objects[(i-1)/4]
+				}
+				if err := g.AddGameObject(objects[i], parent); err != nil {
+					b.Fatal(err)
+				}
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				for j := range objects {
+					objects[j].Transform().SetPosition(mgl32.Vec3{float32(i), 0, 0})
+				}
+				g.ResolveTransforms()
+			}
+			b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*n), "ns/object")
+		})
+	}
+}
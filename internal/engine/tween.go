@@ -0,0 +1,425 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/haakenlabs/forge/internal/math"
+)
+
+var _ System = &Tweens{}
+
+const SysNameTween = "tween"
+
+// Tween changes a value over time, or plays a sequence of tweens. Tweens are
+// started when they are created, and advanced by the tween system each frame.
+// The configuration methods return the tween, so calls can be chained.
+type Tween struct {
+	apply      func(u float64)
+	ease       func(float64) float64
+	steps      []*Tween
+	next       []*Tween
+	onComplete []func()
+	duration   float64
+	delay      float64
+	elapsed    float64
+	waited     float64
+	step       int
+	added      uint64
+	repeat     int
+	loops      int
+	yoyo       bool
+	reverse    bool
+	finished   bool
+	canceled   bool
+	running    bool
+	listed     bool
+}
+
+// Tweens is a system which advances the running tweens.
+type Tweens struct {
+	tweens   []*Tween
+	pending  []*Tween
+	frame    uint64
+	updating bool
+}
+
+// TweenValue is a type of value which can be tweened.
+type TweenValue interface {
+	float32 | mgl32.Vec3 | Color
+}
+
+// TweenTo tweens the target from its value when the tween starts to end, over
+// the duration in seconds. If ease is nil, the value changes linearly.
+func TweenTo[V TweenValue](target *V, end V, duration float64, ease func(float64) float64) *Tween {
+	var start V
+	started := false
+
+	t := newTween(duration, ease, func(u float64) {
+		if !started {
+			start = *target
+			started = true
+		}
+		*target = lerpTweenValue(start, end, float32(u))
+	})
+
+	return GetTweens().Add(t)
+}
+
+// TweenWait creates a tween which does nothing for the duration in seconds,
+// for use in sequences.
+func TweenWait(duration float64) *Tween {
+	return GetTweens().Add(newTween(duration, nil, nil))
+}
+
+// TweenSequence plays the tweens one after another. The tweens are removed
+// from the tween system, and advanced by the sequence instead.
+func TweenSequence(steps ...*Tween) *Tween {
+	t := newTween(0, nil, nil)
+	t.steps = steps
+
+	m := GetTweens()
+	for _, s := range steps {
+		m.remove(s)
+	}
+
+	return m.Add(t)
+}
+
+func newTween(duration float64, ease func(float64) float64, apply func(float64)) *Tween {
+	if ease == nil {
+		ease = math.EaseNone
+	}
+
+	return &Tween{
+		apply:    apply,
+		ease:     ease,
+		duration: duration,
+	}
+}
+
+// Delay waits for the delay in seconds before the tween starts.
+func (t *Tween) Delay(delay float64) *Tween {
+	t.delay = delay
+
+	return t
+}
+
+// Loop plays the tween again the number of times after it first completes.
+// If n is negative, the tween loops until it is canceled.
+func (t *Tween) Loop(n int) *Tween {
+	t.repeat = n
+	t.loops = n
+
+	return t
+}
+
+// Yoyo plays every other loop of the tween backwards.
+func (t *Tween) Yoyo(yoyo bool) *Tween {
+	t.yoyo = yoyo
+
+	return t
+}
+
+// OnComplete adds a function which is called when the tween completes. It is
+// not called if the tween is canceled.
+func (t *Tween) OnComplete(fn func()) *Tween {
+	t.onComplete = append(t.onComplete, fn)
+
+	return t
+}
+
+// Then starts the next tween when this tween completes. The next tween is
+// returned, so that chains can be built.
+func (t *Tween) Then(next *Tween) *Tween {
+	GetTweens().remove(next)
+	t.next = append(t.next, next)
+
+	return next
+}
+
+// Cancel stops the tween, the tweens chained to it, and the steps of a
+// sequence, leaving their values as they are.
+func (t *Tween) Cancel() {
+	t.canceled = true
+	t.running = false
+
+	for _, s := range t.steps {
+		s.Cancel()
+	}
+	for _, n := range t.next {
+		n.Cancel()
+	}
+}
+
+// Running reports whether the tween is being advanced.
+func (t *Tween) Running() bool {
+	return t.running
+}
+
+// Finished reports whether the tween has completed.
+func (t *Tween) Finished() bool {
+	return t.finished
+}
+
+// advance moves the tween forward by dt seconds, and returns the time left
+// over after it finished.
+func (t *Tween) advance(dt float64) float64 {
+	if t.finished || t.canceled {
+		return dt
+	}
+
+	if t.waited < t.delay {
+		t.waited += dt
+		if t.waited < t.delay {
+			return 0
+		}
+		dt = t.waited - t.delay
+	}
+
+	for {
+		var done bool
+		if t.steps != nil {
+			dt, done = t.advanceSteps(dt)
+		} else {
+			dt, done = t.advanceValue(dt)
+		}
+
+		if !done {
+			return 0
+		}
+
+		if t.loops == 0 {
+			t.finish()
+			return dt
+		}
+		if t.loops > 0 {
+			t.loops--
+		}
+
+		t.restart()
+		if t.yoyo {
+			t.reverse = !t.reverse
+		}
+
+		// Play at most one pass of zero length tweens per frame.
+		if dt <= 0 {
+			return 0
+		}
+	}
+}
+
+func (t *Tween) advanceValue(dt float64) (float64, bool) {
+	t.elapsed += dt
+
+	done := t.elapsed >= t.duration
+	if done {
+		dt = t.elapsed - t.duration
+		t.elapsed = t.duration
+	} else {
+		dt = 0
+	}
+
+	if t.apply != nil {
+		u := 1.0
+		if t.duration > 0 {
+			u = t.elapsed / t.duration
+		}
+		if t.reverse {
+			u = 1 - u
+		}
+		t.apply(t.ease(u))
+	}
+
+	return dt, done
+}
+
+func (t *Tween) advanceSteps(dt float64) (float64, bool) {
+	for t.step < len(t.steps) {
+		s := t.steps[t.step]
+		dt = s.advance(dt)
+		if !s.finished && !s.canceled {
+			return 0, false
+		}
+		t.step++
+	}
+
+	return dt, true
+}
+
+// restart rewinds the tween for another loop.
+func (t *Tween) restart() {
+	t.elapsed = 0
+	t.step = 0
+
+	for _, s := range t.steps {
+		s.reset()
+	}
+}
+
+// reset rewinds a step of a sequence, including its delay and loops.
+func (t *Tween) reset() {
+	t.restart()
+	t.waited = 0
+	t.loops = t.repeat
+	t.reverse = false
+	t.finished = false
+}
+
+func (t *Tween) finish() {
+	t.finished = true
+	t.running = false
+
+	for _, fn := range t.onComplete {
+		fn()
+	}
+
+	for _, n := range t.next {
+		if !n.canceled {
+			GetTweens().Add(n)
+		}
+	}
+}
+
+// Setup sets up the System.
+func (m *Tweens) Setup() error {
+	return nil
+}
+
+// Teardown tears down the System.
+func (m *Tweens) Teardown() {
+	m.CancelAll()
+}
+
+// Name returns the name of the System.
+func (m *Tweens) Name() string {
+	return SysNameTween
+}
+
+// Add starts advancing the tween. Tweens added during an update are first
+// advanced by the next update.
+func (m *Tweens) Add(t *Tween) *Tween {
+	t.running = true
+	t.added = m.frame
+
+	if !t.listed {
+		t.listed = true
+		if m.updating {
+			m.pending = append(m.pending, t)
+		} else {
+			m.tweens = append(m.tweens, t)
+		}
+	}
+
+	return t
+}
+
+// remove stops advancing the tween, without canceling it.
+func (m *Tweens) remove(t *Tween) {
+	t.running = false
+}
+
+// Update advances the running tweens by dt seconds.
+func (m *Tweens) Update(dt float64) {
+	m.frame++
+	m.updating = true
+
+	for _, t := range m.tweens {
+		if t.running && t.added != m.frame {
+			t.advance(dt)
+		}
+	}
+
+	m.updating = false
+
+	tweens := m.tweens[:0]
+	for _, t := range m.tweens {
+		if t.running {
+			tweens = append(tweens, t)
+		} else {
+			t.listed = false
+		}
+	}
+	m.tweens = append(tweens, m.pending...)
+	m.pending = m.pending[:0]
+}
+
+// Count returns the number of running tweens.
+func (m *Tweens) Count() int {
+	n := 0
+	for _, t := range m.tweens {
+		if t.running {
+			n++
+		}
+	}
+	for _, t := range m.pending {
+		if t.running {
+			n++
+		}
+	}
+
+	return n
+}
+
+// CancelAll cancels all running tweens.
+func (m *Tweens) CancelAll() {
+	for _, t := range m.tweens {
+		t.Cancel()
+	}
+	for _, t := range m.pending {
+		t.Cancel()
+	}
+}
+
+// NewTweens creates a new tween system.
+func NewTweens() *Tweens {
+	return &Tweens{}
+}
+
+// GetTweens gets the tween system from the current app.
+func GetTweens() *Tweens {
+	return CurrentApp().MustSystem(SysNameTween).(*Tweens)
+}
+
+// lerpTweenValue interpolates between tween values.
+func lerpTweenValue[V TweenValue](a, b V, u float32) V {
+	switch av := any(a).(type) {
+	case float32:
+		bv := any(b).(float32)
+		return any(av + (bv-av)*u).(V)
+	case mgl32.Vec3:
+		bv := any(b).(mgl32.Vec3)
+		return any(av.Add(bv.Sub(av).Mul(u))).(V)
+	case Color:
+		bv := any(b).(Color)
+		return any(Color{
+			R: av.R + (bv.R-av.R)*u,
+			G: av.G + (bv.G-av.G)*u,
+			B: av.B + (bv.B-av.B)*u,
+			A: av.A + (bv.A-av.A)*u,
+		}).(V)
+	}
+
+	return b
+}
@@ -0,0 +1,103 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/haakenlabs/forge/internal/math"
+)
+
+func TestTween(t *testing.T) {
+	app := newHeadlessApp(t)
+	defer app.Teardown()
+
+	m := GetTweens()
+
+	var f float32
+	done := 0
+	TweenTo(&f, 10, 1, nil).Delay(0.5).OnComplete(func() { done++ })
+
+	m.Update(0.5)
+	if f != 0 {
+		t.Errorf("expected delayed tween at 0, got: %v", f)
+	}
+	m.Update(0.25)
+	if !nearFloat(f, 2.5) {
+		t.Errorf("expected 2.5, got: %v", f)
+	}
+	m.Update(1)
+	if f != 10 || done != 1 || m.Count() != 0 {
+		t.Errorf("expected completed tween at 10, got: %v, %d callbacks, %d running", f, done, m.Count())
+	}
+
+	// Yoyo loops play every other pass backwards.
+	v := mgl32.Vec3{}
+	yoyo := TweenTo(&v, mgl32.Vec3{0, 4, 0}, 1, math.EaseInQuad).Loop(-1).Yoyo(true)
+	m.Update(1.5)
+	if !nearVec3(v, mgl32.Vec3{0, 1, 0}) {
+		t.Errorf("expected [0 1 0] on the way back, got: %v", v)
+	}
+	yoyo.Cancel()
+	m.Update(0.25)
+	if !nearVec3(v, mgl32.Vec3{0, 1, 0}) || yoyo.Running() {
+		t.Errorf("expected canceled tween to stay at [0 1 0], got: %v", v)
+	}
+
+	// Chained tweens start from the value left by the previous one.
+	var c Color
+	last := TweenTo(&c, Color{R: 1, A: 1}, 1, nil)
+	last.Then(TweenTo(&c, Color{R: 1, G: 1, A: 1}, 1, nil))
+	m.Update(1)
+	m.Update(0.5)
+	if !nearFloat(c.R, 1) || !nearFloat(c.G, 0.5) {
+		t.Errorf("expected chained color [1 0.5 0 1], got: %+v", c)
+	}
+
+	// Sequences play their steps in order, carrying over the time left.
+	var a, b float32
+	steps := 0
+	seq := TweenSequence(
+		TweenTo(&a, 1, 1, nil),
+		TweenWait(1),
+		TweenTo(&b, 1, 1, nil).OnComplete(func() { steps++ }),
+	).Loop(1)
+
+	m.Update(2.5)
+	if a != 1 || !nearFloat(b, 0.5) {
+		t.Errorf("expected a 1 and b 0.5, got: %v %v", a, b)
+	}
+	m.Update(1)
+	if steps != 1 || seq.Finished() {
+		t.Errorf("expected first loop to complete, got: %d steps, finished %v", steps, seq.Finished())
+	}
+	m.Update(3)
+	if steps != 2 || !seq.Finished() {
+		t.Errorf("expected sequence to finish, got: %d steps, finished %v", steps, seq.Finished())
+	}
+	if n := m.Count(); n != 0 {
+		t.Errorf("expected no running tweens, got: %d", n)
+	}
+}
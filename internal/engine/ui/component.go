@@ -35,6 +35,25 @@ type Component interface {
 	RectTransform() *RectTransform
 }
 
+// Clickable is implemented by components which handle clicks of the pointer
+// within their rect.
+type Clickable interface {
+	Component
+
+	Enabled() bool
+	OnClick()
+}
+
+// Focusable is implemented by components which receive input once focused.
+// Clicking a focusable component focuses it and blurs all others.
+type Focusable interface {
+	Component
+
+	Focus()
+	Blur()
+	Focused() bool
+}
+
 type BaseComponent struct {
 	engine.BaseScriptComponent
 }
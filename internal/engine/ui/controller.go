@@ -24,9 +24,15 @@ package ui
 
 import (
 	"github.com/go-gl/gl/v4.3-core/gl"
+	"github.com/go-gl/glfw/v3.2/glfw"
+	"github.com/go-gl/mathgl/mgl32"
+
 	"github.com/haakenlabs/forge/internal/engine"
 )
 
+// ActionClick is the action clicking UI components under the cursor.
+const ActionClick = "UIClick"
+
 type Controller struct {
 	engine.BaseScriptComponent
 
@@ -71,6 +77,11 @@ func (c *Controller) Resize() {
 	}
 }
 
+// Awake defines the click action.
+func (c *Controller) Awake() {
+	engine.GetInput().DefineAction(ActionClick, engine.MouseButtonBinding(glfw.MouseButtonLeft))
+}
+
 func (c *Controller) Start() {
 	c.Resize()
 	c.UpdateCache()
@@ -80,6 +91,42 @@ func (c *Controller) Update() {
 	if engine.GetWindow().WindowResized() {
 		c.Resize()
 	}
+
+	if in := engine.GetInput(); in.Pressed(ActionClick) {
+		c.Click(in.Cursor())
+	}
+}
+
+// Click clicks the topmost clickable component containing the point, in window
+// coordinates. Focusable components are focused if clicked and blurred
+// otherwise, so clicking outside of them removes the focus.
+func (c *Controller) Click(point mgl32.Vec2) {
+	if c.GameObject() == nil {
+		return
+	}
+
+	components := c.GameObject().ComponentsInChildren()
+
+	// Components later in the hierarchy are drawn on top.
+	var clicked Clickable
+	for i := len(components) - 1; i >= 0; i-- {
+		if w, ok := components[i].(Clickable); ok && w.Enabled() {
+			if r := w.RectTransform().ScreenRect(); r.Contains(point) {
+				clicked = w
+				break
+			}
+		}
+	}
+
+	for i := range components {
+		if w, ok := components[i].(Focusable); ok && w.Focused() && Component(w) != clicked {
+			w.Blur()
+		}
+	}
+
+	if clicked != nil {
+		clicked.OnClick()
+	}
 }
 
 func NewController() *Controller {
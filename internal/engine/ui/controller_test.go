@@ -0,0 +1,113 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package ui
+
+import (
+	"testing"
+
+	"github.com/go-gl/glfw/v3.2/glfw"
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/haakenlabs/forge/internal/engine"
+)
+
+func newHeadlessApp(t testing.TB) *engine.App {
+	a := engine.NewApp(&engine.AppConfig{
+		Name:     "test",
+		Headless: true,
+	})
+
+	if err := a.Setup(); err != nil {
+		t.Fatal(err)
+	}
+
+	return a
+}
+
+func TestController_ClickFocus(t *testing.T) {
+	a := newHeadlessApp(t)
+	defer a.Teardown()
+
+	first, second := NewTextbox(), NewTextbox()
+
+	s := engine.NewScene("test")
+	s.SetLoadFunc(func() error {
+		root := CreateController("controller")
+		if err := s.Graph().AddGameObject(root, nil); err != nil {
+			return err
+		}
+
+		for i, w := range []*Textbox{first, second} {
+			o := CreateGenericObject("textbox")
+			o.AddComponent(w)
+			w.RectTransform().SetPosition2D(mgl32.Vec2{10, 10 + float32(i)*40})
+			w.RectTransform().SetSize(mgl32.Vec2{100, 30})
+
+			if err := s.Graph().AddGameObject(o, root); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if err := a.RegisterScene(s); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.PushScene("test"); err != nil {
+		t.Fatal(err)
+	}
+
+	a.Step(1)
+
+	click := func(x, y float32) {
+		in := engine.GetInput()
+		in.InjectCursor(x, y)
+		in.InjectMouseButton(glfw.MouseButtonLeft, glfw.Press)
+		a.Step(1)
+		in.InjectMouseButton(glfw.MouseButtonLeft, glfw.Release)
+		a.Step(1)
+	}
+
+	click(20, 20)
+	if !first.Focused() || second.Focused() {
+		t.Error("click on first expected only first focused, got:", first.Focused(), second.Focused())
+	}
+
+	click(20, 60)
+	if first.Focused() || !second.Focused() {
+		t.Error("click on second expected only second focused, got:", first.Focused(), second.Focused())
+	}
+
+	// Text input only reaches the focused textbox.
+	engine.GetInput().InjectChar('!')
+	a.Step(1)
+	if first.Value() != "Text" || second.Value() != "Text!" {
+		t.Error("typing expected Text and Text!, got:", first.Value(), second.Value())
+	}
+
+	click(500, 500)
+	if first.Focused() || second.Focused() {
+		t.Error("click outside expected no focus, got:", first.Focused(), second.Focused())
+	}
+}
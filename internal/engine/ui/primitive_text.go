@@ -74,6 +74,15 @@ func (t *Text) SetValue(value string) {
 	t.Refresh()
 }
 
+// Width returns the advance of the value drawn with the font of the text.
+func (t *Text) Width(value string) float32 {
+	if t.font == nil {
+		return 0
+	}
+
+	return t.font.TextWidth(value, float64(t.fontSize))
+}
+
 func (t *Text) Refresh() {
 	if t.font == nil {
 		return
@@ -86,14 +86,7 @@ func NewRectTransform() *RectTransform {
 }
 
 func RectTransformComponent(g *engine.GameObject) *RectTransform {
-	c := g.Components()
-	for i := range c {
-		if ct, ok := c[i].(*RectTransform); ok {
-			return ct
-		}
-	}
-
-	return nil
+	return engine.GetComponent[*RectTransform](g)
 }
 
 func (t *RectTransform) Rect() Rect {
@@ -291,6 +284,16 @@ func (t *RectTransform) Recompute(updateChildren bool) {
 
 	t.BaseTransform.Recompute(updateChildren)
 
+	// Layouts of descendants depend on the size of this rect.
+	if updateChildren && t.GameObject() != nil {
+		childComponents := t.GameObject().ComponentsInChildren()
+		for idx := range childComponents {
+			if child, ok := childComponents[idx].(engine.Transform); ok {
+				child.Recompute(false)
+			}
+		}
+	}
+
 	//if t.GameObject() != nil {
 	//	x := t.GameObject().Components()
 	//	for i := range x {
@@ -320,10 +323,15 @@ func (t *RectTransform) ComputeOffsets() {
 	t.offsetMax = mgl32.Vec2{offsetMaxX, offsetMaxY}
 }
 
-func (t *RectTransform) WorldPosition() mgl32.Vec2 {
+func (t *RectTransform) WorldPosition2D() mgl32.Vec2 {
 	return t.ActiveMatrix().Col(3).Vec2()
 }
 
+// ScreenRect returns the rect in window coordinates.
+func (t *RectTransform) ScreenRect() Rect {
+	return NewRectFrom(t.WorldPosition2D(), t.Size())
+}
+
 func (t *RectTransform) ParentTransform() *RectTransform {
 	if t.GameObject() != nil {
 		if parent := t.GameObject().Parent(); parent != nil {
@@ -0,0 +1,278 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package ui
+
+import (
+	"unicode"
+)
+
+// TextEdit is the editing model of a single line of text: the text, a caret
+// and a selection between the caret and an anchor. Positions count runes.
+type TextEdit struct {
+	text      []rune
+	caret     int
+	anchor    int
+	maxLength int
+}
+
+// NewTextEdit creates a new TextEdit with the value.
+func NewTextEdit(value string) *TextEdit {
+	e := &TextEdit{}
+	e.SetValue(value)
+
+	return e
+}
+
+// Value returns the text.
+func (e *TextEdit) Value() string {
+	return string(e.text)
+}
+
+// SetValue replaces the text, and moves the caret to its end.
+func (e *TextEdit) SetValue(value string) {
+	e.text = e.text[:0]
+	e.caret = 0
+	e.anchor = 0
+	e.Insert(value)
+}
+
+// Len returns the length of the text in runes.
+func (e *TextEdit) Len() int {
+	return len(e.text)
+}
+
+// MaxLength returns the maximum length of the text, or 0 if unlimited.
+func (e *TextEdit) MaxLength() int {
+	return e.maxLength
+}
+
+// SetMaxLength sets the maximum length of the text, truncating it if
+// needed. A length of 0 is unlimited.
+func (e *TextEdit) SetMaxLength(n int) {
+	if n < 0 {
+		n = 0
+	}
+	e.maxLength = n
+
+	if n > 0 && len(e.text) > n {
+		e.text = e.text[:n]
+		e.caret = e.clamp(e.caret)
+		e.anchor = e.clamp(e.anchor)
+	}
+}
+
+// Caret returns the position of the caret.
+func (e *TextEdit) Caret() int {
+	return e.caret
+}
+
+// SetCaret moves the caret. If extend is set, the selection is extended to
+// it, otherwise the selection is cleared.
+func (e *TextEdit) SetCaret(pos int, extend bool) {
+	e.caret = e.clamp(pos)
+
+	if !extend {
+		e.anchor = e.caret
+	}
+}
+
+// Selection returns the start and end of the selection.
+func (e *TextEdit) Selection() (start, end int) {
+	if e.anchor < e.caret {
+		return e.anchor, e.caret
+	}
+
+	return e.caret, e.anchor
+}
+
+// HasSelection reports if any text is selected.
+func (e *TextEdit) HasSelection() bool {
+	return e.anchor != e.caret
+}
+
+// SelectedText returns the selected text.
+func (e *TextEdit) SelectedText() string {
+	start, end := e.Selection()
+
+	return string(e.text[start:end])
+}
+
+// SelectAll selects the whole text.
+func (e *TextEdit) SelectAll() {
+	e.anchor = 0
+	e.caret = len(e.text)
+}
+
+// MoveLeft moves the caret one rune left. Without extend, a selection
+// collapses to its start instead.
+func (e *TextEdit) MoveLeft(extend bool) {
+	if !extend && e.HasSelection() {
+		start, _ := e.Selection()
+		e.SetCaret(start, false)
+		return
+	}
+
+	e.SetCaret(e.caret-1, extend)
+}
+
+// MoveRight moves the caret one rune right. Without extend, a selection
+// collapses to its end instead.
+func (e *TextEdit) MoveRight(extend bool) {
+	if !extend && e.HasSelection() {
+		_, end := e.Selection()
+		e.SetCaret(end, false)
+		return
+	}
+
+	e.SetCaret(e.caret+1, extend)
+}
+
+// MoveWordLeft moves the caret to the start of the previous word.
+func (e *TextEdit) MoveWordLeft(extend bool) {
+	pos := e.caret
+	for pos > 0 && unicode.IsSpace(e.text[pos-1]) {
+		pos--
+	}
+	for pos > 0 && !unicode.IsSpace(e.text[pos-1]) {
+		pos--
+	}
+
+	e.SetCaret(pos, extend)
+}
+
+// MoveWordRight moves the caret to the end of the next word.
+func (e *TextEdit) MoveWordRight(extend bool) {
+	pos := e.caret
+	for pos < len(e.text) && unicode.IsSpace(e.text[pos]) {
+		pos++
+	}
+	for pos < len(e.text) && !unicode.IsSpace(e.text[pos]) {
+		pos++
+	}
+
+	e.SetCaret(pos, extend)
+}
+
+// MoveHome moves the caret to the start of the text.
+func (e *TextEdit) MoveHome(extend bool) {
+	e.SetCaret(0, extend)
+}
+
+// MoveEnd moves the caret to the end of the text.
+func (e *TextEdit) MoveEnd(extend bool) {
+	e.SetCaret(len(e.text), extend)
+}
+
+// Insert replaces the selection with s, or inserts it at the caret. Control
+// characters are dropped, and s is truncated to the maximum length. Insert
+// reports if the text changed.
+func (e *TextEdit) Insert(s string) bool {
+	changed := e.DeleteSelection()
+
+	runes := make([]rune, 0, len(s))
+	for _, r := range s {
+		if !unicode.IsControl(r) {
+			runes = append(runes, r)
+		}
+	}
+
+	if e.maxLength > 0 {
+		if room := e.maxLength - len(e.text); len(runes) > room {
+			runes = runes[:room]
+		}
+	}
+	if len(runes) == 0 {
+		return changed
+	}
+
+	text := make([]rune, 0, len(e.text)+len(runes))
+	text = append(text, e.text[:e.caret]...)
+	text = append(text, runes...)
+	text = append(text, e.text[e.caret:]...)
+
+	e.text = text
+	e.SetCaret(e.caret+len(runes), false)
+
+	return true
+}
+
+// DeleteSelection deletes the selected text, and reports if there was any.
+func (e *TextEdit) DeleteSelection() bool {
+	if !e.HasSelection() {
+		return false
+	}
+
+	start, end := e.Selection()
+	e.text = append(e.text[:start], e.text[end:]...)
+	e.SetCaret(start, false)
+
+	return true
+}
+
+// Backspace deletes the selection, or the rune before the caret. It
+// reports if the text changed.
+func (e *TextEdit) Backspace() bool {
+	if e.DeleteSelection() {
+		return true
+	}
+	if e.caret == 0 {
+		return false
+	}
+
+	e.SetCaret(e.caret-1, true)
+
+	return e.DeleteSelection()
+}
+
+// Delete deletes the selection, or the rune after the caret. It reports if
+// the text changed.
+func (e *TextEdit) Delete() bool {
+	if e.DeleteSelection() {
+		return true
+	}
+	if e.caret == len(e.text) {
+		return false
+	}
+
+	e.SetCaret(e.caret+1, true)
+
+	return e.DeleteSelection()
+}
+
+// Cut deletes the selected text and returns it.
+func (e *TextEdit) Cut() string {
+	s := e.SelectedText()
+	e.DeleteSelection()
+
+	return s
+}
+
+func (e *TextEdit) clamp(pos int) int {
+	if pos < 0 {
+		return 0
+	}
+	if pos > len(e.text) {
+		return len(e.text)
+	}
+
+	return pos
+}
@@ -0,0 +1,186 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package ui
+
+import (
+	"testing"
+
+	"github.com/go-gl/glfw/v3.2/glfw"
+
+	"github.com/haakenlabs/forge/internal/engine"
+)
+
+func TestTextEdit_Insert(t *testing.T) {
+	e := NewTextEdit("hllo")
+
+	e.SetCaret(1, false)
+	if !e.Insert("e") || e.Value() != "hello" || e.Caret() != 2 {
+		t.Error("Insert(e) expected hello with caret 2, got:", e.Value(), e.Caret())
+	}
+
+	e.MoveEnd(false)
+	e.Insert(" wörld\n")
+	if e.Value() != "hello wörld" || e.Len() != 11 {
+		t.Error("Insert() expected hello wörld without control characters, got:", e.Value())
+	}
+
+	e.SetMaxLength(8)
+	if e.Value() != "hello wö" || e.Caret() != 8 {
+		t.Error("SetMaxLength(8) expected hello wö with caret 8, got:", e.Value(), e.Caret())
+	}
+	if e.Insert("x") {
+		t.Error("Insert() expected no change at max length")
+	}
+
+	e.SetCaret(5, false)
+	e.SetCaret(8, true)
+	if !e.Insert("!!!!") || e.Value() != "hello!!!" {
+		t.Error("Insert() expected to replace the selection up to max length, got:", e.Value())
+	}
+}
+
+func TestTextEdit_Delete(t *testing.T) {
+	e := NewTextEdit("abcdef")
+
+	if !e.Backspace() || e.Value() != "abcde" {
+		t.Error("Backspace() expected abcde, got:", e.Value())
+	}
+
+	e.MoveHome(false)
+	if e.Backspace() {
+		t.Error("Backspace() expected no change at start")
+	}
+	if !e.Delete() || e.Value() != "bcde" || e.Caret() != 0 {
+		t.Error("Delete() expected bcde with caret 0, got:", e.Value(), e.Caret())
+	}
+
+	e.MoveRight(false)
+	e.MoveRight(true)
+	e.MoveRight(true)
+	if s := e.SelectedText(); s != "cd" {
+		t.Error("SelectedText() expected cd, got:", s)
+	}
+	if s := e.Cut(); s != "cd" || e.Value() != "be" || e.HasSelection() {
+		t.Error("Cut() expected cd leaving be, got:", s, e.Value())
+	}
+
+	e.MoveEnd(false)
+	if e.Delete() {
+		t.Error("Delete() expected no change at end")
+	}
+}
+
+func TestTextEdit_Move(t *testing.T) {
+	e := NewTextEdit("one  two three")
+
+	e.MoveWordLeft(false)
+	if e.Caret() != 9 {
+		t.Error("MoveWordLeft() expected 9, got:", e.Caret())
+	}
+	e.MoveWordLeft(true)
+	if start, end := e.Selection(); start != 5 || end != 9 {
+		t.Error("Selection() expected 5-9, got:", start, end)
+	}
+
+	e.MoveRight(false)
+	if e.Caret() != 9 || e.HasSelection() {
+		t.Error("MoveRight() expected to collapse to 9, got:", e.Caret())
+	}
+
+	e.MoveHome(false)
+	e.MoveWordRight(false)
+	if e.Caret() != 3 {
+		t.Error("MoveWordRight() expected 3, got:", e.Caret())
+	}
+
+	e.SelectAll()
+	e.MoveLeft(false)
+	if e.Caret() != 0 || e.HasSelection() {
+		t.Error("MoveLeft() expected to collapse to 0, got:", e.Caret())
+	}
+
+	e.SetCaret(-4, false)
+	e.SetCaret(100, true)
+	if s := e.SelectedText(); s != e.Value() {
+		t.Error("SetCaret() expected clamped selection of all, got:", s)
+	}
+}
+
+type testClipboard struct {
+	text string
+}
+
+func (c *testClipboard) ClipboardString() string     { return c.text }
+func (c *testClipboard) SetClipboardString(s string) { c.text = s }
+
+func TestTextbox_HandleTextEvent(t *testing.T) {
+	w := &Textbox{edit: NewTextEdit(""), mask: DefaultMask}
+	clip := &testClipboard{}
+
+	var changes []string
+	var submitted string
+	w.SetOnChangeFunc(func(s string) { changes = append(changes, s) })
+	w.SetOnSubmitFunc(func(s string) { submitted = s })
+
+	for _, r := range "hey" {
+		w.HandleTextEvent(engine.TextEvent{Char: r}, clip)
+	}
+	w.HandleTextEvent(engine.TextEvent{Key: glfw.KeyLeft, Mods: glfw.ModShift}, clip)
+	w.HandleTextEvent(engine.TextEvent{Key: glfw.KeyC, Mods: glfw.ModControl}, clip)
+
+	if clip.text != "y" {
+		t.Error("copy expected y, got:", clip.text)
+	}
+
+	w.HandleTextEvent(engine.TextEvent{Key: glfw.KeyEnd}, clip)
+	w.HandleTextEvent(engine.TextEvent{Key: glfw.KeyV, Mods: glfw.ModControl}, clip)
+	w.HandleTextEvent(engine.TextEvent{Key: glfw.KeyEnter}, clip)
+
+	if submitted != "heyy" {
+		t.Error("submit expected heyy, got:", submitted)
+	}
+	if len(changes) != 4 || changes[3] != "heyy" {
+		t.Error("changes expected 4 ending in heyy, got:", changes)
+	}
+
+	w.SetPassword(true)
+	w.HandleTextEvent(engine.TextEvent{Key: glfw.KeyA, Mods: glfw.ModControl}, clip)
+	w.HandleTextEvent(engine.TextEvent{Key: glfw.KeyX, Mods: glfw.ModControl}, clip)
+
+	if w.Value() != "heyy" || clip.text != "y" {
+		t.Error("password cut expected no change, got:", w.Value(), clip.text)
+	}
+	if d := w.DisplayValue(); d != "••••" {
+		t.Error("DisplayValue() expected ••••, got:", d)
+	}
+
+	w.HandleTextEvent(engine.TextEvent{Key: glfw.KeyBackspace}, clip)
+	if w.Value() != "" {
+		t.Error("Backspace expected to delete the selection, got:", w.Value())
+	}
+
+	w.Focus()
+	w.HandleTextEvent(engine.TextEvent{Key: glfw.KeyEscape}, clip)
+	if w.Focused() {
+		t.Error("Escape expected to blur")
+	}
+}
@@ -26,6 +26,11 @@ import "github.com/haakenlabs/forge/internal/engine"
 
 var _ Renderer = &Button{}
 
+// ClickEvent is published to the scene of a button when it is clicked.
+type ClickEvent struct {
+	Button *Button
+}
+
 type Button struct {
 	BaseComponent
 
@@ -98,6 +103,10 @@ func (w *Button) OnClick() {
 	if w.onPressedFunc != nil {
 		w.onPressedFunc()
 	}
+
+	if g := w.GameObject(); g != nil && g.Scene() != nil {
+		engine.Publish(engine.ScopeScene(g.Scene()), ClickEvent{Button: w})
+	}
 }
 
 func (w *Button) UIDraw() {
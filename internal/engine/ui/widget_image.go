@@ -71,14 +71,7 @@ func NewImage() *Image {
 }
 
 func ImageComponent(g *engine.GameObject) *Image {
-	c := g.Components()
-	for i := range c {
-		if ct, ok := c[i].(*Image); ok {
-			return ct
-		}
-	}
-
-	return nil
+	return engine.GetComponent[*Image](g)
 }
 
 func CreateImage(name string) *engine.GameObject {
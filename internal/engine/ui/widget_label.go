@@ -76,14 +76,7 @@ func (w *Label) FontSize(size int32) int32 {
 }
 
 func LabelComponent(g *engine.GameObject) *Label {
-	c := g.Components()
-	for i := range c {
-		if ct, ok := c[i].(*Label); ok {
-			return ct
-		}
-	}
-
-	return nil
+	return engine.GetComponent[*Label](g)
 }
 
 func CreateLabel(name string) *engine.GameObject {
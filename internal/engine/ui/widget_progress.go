@@ -33,6 +33,7 @@ type Progress struct {
 	tint            engine.Color
 
 	onChangeFunc func(float64)
+	valueFunc    func() float64
 
 	background  *Graphic
 	activeTrack *Graphic
@@ -43,6 +44,45 @@ func (w *Progress) UIDraw() {
 	w.activeTrack.Draw()
 }
 
+// SetValue sets the progress, clamped to the range [0, 1].
+func (w *Progress) SetValue(value float64) {
+	if value < 0 {
+		value = 0
+	} else if value > 1 {
+		value = 1
+	}
+
+	if value == w.progress {
+		return
+	}
+
+	w.progress = value
+
+	if w.onChangeFunc != nil {
+		w.onChangeFunc(value)
+	}
+}
+
+func (w *Progress) Value() float64 {
+	return w.progress
+}
+
+func (w *Progress) SetOnChangeFunc(fn func(float64)) {
+	w.onChangeFunc = fn
+}
+
+// SetValueFunc sets a function polled each frame for the progress, such as
+// the Progress method of an engine.AssetLoad.
+func (w *Progress) SetValueFunc(fn func() float64) {
+	w.valueFunc = fn
+}
+
+func (w *Progress) Update() {
+	if w.valueFunc != nil {
+		w.SetValue(w.valueFunc())
+	}
+}
+
 func NewProgress() *Progress {
 	w := &Progress{
 		progress: 0.0,
@@ -68,3 +108,7 @@ func CreateProgress(name string) *engine.GameObject {
 
 	return object
 }
+
+func ProgressComponent(g *engine.GameObject) *Progress {
+	return engine.GetComponent[*Progress](g)
+}
@@ -22,27 +22,269 @@ SOFTWARE.
 
 package ui
 
-import "github.com/haakenlabs/forge/internal/engine"
+import (
+	"strings"
+
+	"github.com/go-gl/glfw/v3.2/glfw"
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/haakenlabs/forge/internal/engine"
+)
+
+// DefaultMask is the rune which masks the characters of password textboxes.
+const DefaultMask = '•'
+
+// CaretWidth is the width of the caret of focused textboxes.
+const CaretWidth = 2
 
 type Textbox struct {
 	BaseComponent
 
-	value string
+	edit     *TextEdit
+	focused  bool
+	password bool
+	mask     rune
 
 	onChangeFunc func(string)
+	onSubmitFunc func(string)
 
 	background *Graphic
 	text       *Text
+	selection  *Graphic
+	caret      *Graphic
 }
 
+// UIDraw draws the textbox. Focused textboxes draw the selection behind the
+// text, and the caret in front of it.
 func (w *Textbox) UIDraw() {
 	w.background.Draw()
+
+	if w.focused && w.edit.HasSelection() {
+		w.selection.Draw()
+	}
+
 	w.text.Draw()
+
+	if w.focused {
+		w.caret.Draw()
+	}
+}
+
+func (w *Textbox) SetValue(value string) {
+	w.edit.SetValue(value)
+	w.refresh()
+}
+
+func (w *Textbox) Value() string {
+	return w.edit.Value()
+}
+
+// Edit returns the editing model of the textbox.
+func (w *Textbox) Edit() *TextEdit {
+	return w.edit
+}
+
+func (w *Textbox) SetMaxLength(n int) {
+	w.edit.SetMaxLength(n)
+	w.refresh()
+}
+
+func (w *Textbox) MaxLength() int {
+	return w.edit.MaxLength()
+}
+
+// SetPassword sets if the textbox masks its text. Password textboxes do not
+// copy or cut to the clipboard.
+func (w *Textbox) SetPassword(password bool) {
+	w.password = password
+	w.refresh()
+}
+
+func (w *Textbox) Password() bool {
+	return w.password
+}
+
+func (w *Textbox) SetMask(mask rune) {
+	w.mask = mask
+	w.refresh()
+}
+
+func (w *Textbox) Mask() rune {
+	return w.mask
+}
+
+// DisplayValue returns the text as displayed, masked for passwords.
+func (w *Textbox) DisplayValue() string {
+	if w.password {
+		return strings.Repeat(string(w.mask), w.edit.Len())
+	}
+
+	return w.edit.Value()
+}
+
+func (w *Textbox) SetOnChangeFunc(fn func(string)) {
+	w.onChangeFunc = fn
+}
+
+// SetOnSubmitFunc sets the function called when enter is pressed.
+func (w *Textbox) SetOnSubmitFunc(fn func(string)) {
+	w.onSubmitFunc = fn
+}
+
+// Focus makes the textbox receive text input.
+func (w *Textbox) Focus() {
+	w.focused = true
+	w.layoutCaret()
+}
+
+// Blur stops the textbox from receiving text input.
+func (w *Textbox) Blur() {
+	w.focused = false
+}
+
+func (w *Textbox) Focused() bool {
+	return w.focused
+}
+
+func (w *Textbox) OnClick() {
+	w.Focus()
+}
+
+func (w *Textbox) Update() {
+	if !w.focused {
+		return
+	}
+
+	in := engine.GetInput()
+	for _, e := range in.TextEvents() {
+		w.HandleTextEvent(e, in.Clipboard())
+	}
+}
+
+// HandleTextEvent applies a text event to the textbox, using the clipboard
+// for copy and paste.
+func (w *Textbox) HandleTextEvent(e engine.TextEvent, clipboard engine.Clipboard) {
+	defer w.layoutCaret()
+
+	if e.Char != 0 {
+		w.changed(w.edit.Insert(string(e.Char)))
+		return
+	}
+
+	extend := e.Mods&glfw.ModShift != 0
+	word := e.Mods&glfw.ModControl != 0
+
+	switch e.Key {
+	case glfw.KeyLeft:
+		if word {
+			w.edit.MoveWordLeft(extend)
+		} else {
+			w.edit.MoveLeft(extend)
+		}
+	case glfw.KeyRight:
+		if word {
+			w.edit.MoveWordRight(extend)
+		} else {
+			w.edit.MoveRight(extend)
+		}
+	case glfw.KeyHome:
+		w.edit.MoveHome(extend)
+	case glfw.KeyEnd:
+		w.edit.MoveEnd(extend)
+	case glfw.KeyBackspace:
+		w.changed(w.edit.Backspace())
+	case glfw.KeyDelete:
+		w.changed(w.edit.Delete())
+	case glfw.KeyEnter, glfw.KeyKPEnter:
+		if w.onSubmitFunc != nil {
+			w.onSubmitFunc(w.edit.Value())
+		}
+	case glfw.KeyEscape:
+		w.Blur()
+	}
+
+	if e.Mods&glfw.ModControl == 0 {
+		return
+	}
+
+	switch e.Key {
+	case glfw.KeyA:
+		w.edit.SelectAll()
+	case glfw.KeyC:
+		if !w.password && w.edit.HasSelection() {
+			clipboard.SetClipboardString(w.edit.SelectedText())
+		}
+	case glfw.KeyX:
+		if !w.password && w.edit.HasSelection() {
+			clipboard.SetClipboardString(w.edit.Cut())
+			w.changed(true)
+		}
+	case glfw.KeyV:
+		w.changed(w.edit.Insert(clipboard.ClipboardString()))
+	}
+}
+
+// changed refreshes the text and calls the change function if the value
+// changed.
+func (w *Textbox) changed(changed bool) {
+	if !changed {
+		return
+	}
+
+	w.refresh()
+
+	if w.onChangeFunc != nil {
+		w.onChangeFunc(w.edit.Value())
+	}
+}
+
+func (w *Textbox) refresh() {
+	if w.text != nil {
+		w.text.SetValue(w.DisplayValue())
+	}
+
+	w.layoutCaret()
+}
+
+// layoutCaret moves the caret and the selection highlight to their positions
+// in the displayed text.
+func (w *Textbox) layoutCaret() {
+	if w.text == nil || w.caret == nil || w.selection == nil {
+		return
+	}
+
+	height := w.RectTransform().Size().Y()
+	start, end := w.edit.Selection()
+	x0, x1 := w.textOffset(start), w.textOffset(end)
+
+	setGraphicRect(w.selection, mgl32.Vec2{x0, 0}, mgl32.Vec2{x1 - x0, height})
+	setGraphicRect(w.caret, mgl32.Vec2{w.textOffset(w.edit.Caret()), 0}, mgl32.Vec2{CaretWidth, height})
+}
+
+// textOffset returns the offset of the character at pos in the displayed
+// text.
+func (w *Textbox) textOffset(pos int) float32 {
+	display := []rune(w.DisplayValue())
+	if pos > len(display) {
+		pos = len(display)
+	}
+
+	return w.text.Width(string(display[:pos]))
+}
+
+// setGraphicRect moves and resizes the rect of a graphic, and refreshes it.
+func setGraphicRect(g *Graphic, position, size mgl32.Vec2) {
+	t := g.RectTransform()
+	t.SetPosition2D(position)
+	t.SetSize(size)
+
+	g.Refresh()
 }
 
 func NewTextbox() *Textbox {
 	w := &Textbox{
-		value: "Text",
+		edit: NewTextEdit("Text"),
+		mask: DefaultMask,
 	}
 
 	w.SetName("UITextbox")
@@ -63,5 +305,20 @@ func CreateTextbox(name string) *engine.GameObject {
 	object.AddComponent(textbox.background)
 	object.AddComponent(textbox.text)
 
+	// The selection and caret are drawn by the textbox in rects of their own.
+	selection := CreateGenericObject(name + ".selection")
+	textbox.selection = NewGraphic()
+	textbox.selection.SetColor(Styles.AltBackgroundColor)
+	selection.AddComponent(textbox.selection)
+	object.AddChild(selection)
+
+	caret := CreateGenericObject(name + ".caret")
+	textbox.caret = NewGraphic()
+	textbox.caret.SetColor(Styles.PrimaryTextColor)
+	caret.AddComponent(textbox.caret)
+	object.AddChild(caret)
+
+	textbox.refresh()
+
 	return object
 }
@@ -0,0 +1,317 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/haakenlabs/forge/internal/builtin"
+)
+
+// Mount priorities for the default file sources. Sources with a higher
+// priority are searched first.
+const (
+	MountPriorityBuiltin = 0
+	MountPriorityDefault = 100
+)
+
+const vfsBuiltinName = "<builtin>"
+
+// ErrVFSFileNotFound reports that no mounted file source has the file.
+type ErrVFSFileNotFound string
+
+func (e ErrVFSFileNotFound) Error() string {
+	return "vfs: file not found: " + string(e)
+}
+
+// ErrVFSMounted reports that a file source with the name is already mounted.
+type ErrVFSMounted string
+
+func (e ErrVFSMounted) Error() string {
+	return "vfs: already mounted: " + string(e)
+}
+
+// ErrVFSNotMounted reports that no file source with the name is mounted.
+type ErrVFSNotMounted string
+
+func (e ErrVFSNotMounted) Error() string {
+	return "vfs: not mounted: " + string(e)
+}
+
+// ErrVFSInvalidPath reports that a filename escapes the root of the virtual
+// filesystem.
+type ErrVFSInvalidPath string
+
+func (e ErrVFSInvalidPath) Error() string {
+	return "vfs: invalid path: " + string(e)
+}
+
+// FileSource is a read-only collection of files which can be mounted in the
+// virtual filesystem. Filenames are slash separated and relative to the root
+// of the source.
+type FileSource interface {
+	// Name returns the name of this source.
+	Name() string
+
+	// Has reports if the source contains the file.
+	Has(filename string) bool
+
+	// Read writes the contents of the file to w.
+	Read(filename string, w io.Writer) error
+}
+
+// DirSource is a FileSource backed by a directory on the local filesystem.
+type DirSource struct {
+	dir string
+}
+
+var _ FileSource = &DirSource{}
+
+// NewDirSource creates a new file source for the directory.
+func NewDirSource(dir string) *DirSource {
+	return &DirSource{
+		dir: dir,
+	}
+}
+
+// Name returns the directory of this source.
+func (s *DirSource) Name() string {
+	return s.dir
+}
+
+// Has reports if the directory contains the file.
+func (s *DirSource) Has(filename string) bool {
+	p, ok := s.path(filename)
+	if !ok {
+		return false
+	}
+
+	info, err := os.Stat(p)
+
+	return err == nil && info.Mode().IsRegular()
+}
+
+// Read writes the contents of the file to w. Filenames escaping the directory
+// are rejected.
+func (s *DirSource) Read(filename string, w io.Writer) error {
+	p, ok := s.path(filename)
+	if !ok {
+		return ErrVFSInvalidPath(filename)
+	}
+
+	f, err := os.Open(p)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(w, f)
+
+	return err
+}
+
+// path returns the path of the file on the local filesystem, or false if the
+// filename escapes the directory.
+func (s *DirSource) path(filename string) (string, bool) {
+	filename = cleanVFSPath(filename)
+	if !validVFSPath(filename) {
+		return "", false
+	}
+
+	return filepath.Join(s.dir, filepath.FromSlash(filename)), true
+}
+
+// BindataSource is a FileSource for the assets built in to the binary.
+type BindataSource struct{}
+
+var _ FileSource = &BindataSource{}
+
+// NewBindataSource creates a new file source for the builtin assets.
+func NewBindataSource() *BindataSource {
+	return &BindataSource{}
+}
+
+// Name returns the name of this source.
+func (s *BindataSource) Name() string {
+	return vfsBuiltinName
+}
+
+// Has reports if the file is built in.
+func (s *BindataSource) Has(filename string) bool {
+	_, err := builtin.AssetInfo(filename)
+
+	return err == nil
+}
+
+// Read writes the contents of the file to w.
+func (s *BindataSource) Read(filename string, w io.Writer) error {
+	data, err := builtin.Asset(filename)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(data)
+
+	return err
+}
+
+type vfsMount struct {
+	source   FileSource
+	priority int
+	order    int
+}
+
+// VFS is a layered virtual filesystem. Files are looked up in each mounted
+// source in order of priority, so a source can shadow the files of sources
+// with a lower priority. Sources of equal priority are searched in reverse
+// mount order.
+type VFS struct {
+	mounts []vfsMount
+	order  int
+	mu     *sync.RWMutex
+}
+
+// NewVFS creates a new empty virtual filesystem.
+func NewVFS() *VFS {
+	return &VFS{
+		mu: &sync.RWMutex{},
+	}
+}
+
+// Mount adds a file source with the given priority.
+func (v *VFS) Mount(source FileSource, priority int) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	for i := range v.mounts {
+		if v.mounts[i].source.Name() == source.Name() {
+			return ErrVFSMounted(source.Name())
+		}
+	}
+
+	v.order++
+	v.mounts = append(v.mounts, vfsMount{
+		source:   source,
+		priority: priority,
+		order:    v.order,
+	})
+
+	sort.Slice(v.mounts, func(i, j int) bool {
+		if v.mounts[i].priority != v.mounts[j].priority {
+			return v.mounts[i].priority > v.mounts[j].priority
+		}
+
+		return v.mounts[i].order > v.mounts[j].order
+	})
+
+	return nil
+}
+
+// Unmount removes the file source with the given name and returns it.
+func (v *VFS) Unmount(name string) (FileSource, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	for i := range v.mounts {
+		if v.mounts[i].source.Name() == name {
+			source := v.mounts[i].source
+			v.mounts = append(v.mounts[:i], v.mounts[i+1:]...)
+
+			return source, nil
+		}
+	}
+
+	return nil, ErrVFSNotMounted(name)
+}
+
+// Source returns the mounted source with the given name.
+func (v *VFS) Source(name string) (FileSource, bool) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	for i := range v.mounts {
+		if v.mounts[i].source.Name() == name {
+			return v.mounts[i].source, true
+		}
+	}
+
+	return nil, false
+}
+
+// Mounts returns the names of the mounted sources in search order.
+func (v *VFS) Mounts() []string {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	names := make([]string, len(v.mounts))
+	for i := range v.mounts {
+		names[i] = v.mounts[i].source.Name()
+	}
+
+	return names
+}
+
+// Resolve returns the source with the highest priority which has the file.
+func (v *VFS) Resolve(filename string) (FileSource, error) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	filename = cleanVFSPath(filename)
+	if !validVFSPath(filename) {
+		return nil, ErrVFSInvalidPath(filename)
+	}
+
+	for i := range v.mounts {
+		if v.mounts[i].source.Has(filename) {
+			return v.mounts[i].source, nil
+		}
+	}
+
+	return nil, ErrVFSFileNotFound(filename)
+}
+
+// Read writes the contents of the file from the source with the highest
+// priority which has it to w.
+func (v *VFS) Read(filename string, w io.Writer) error {
+	source, err := v.Resolve(filename)
+	if err != nil {
+		return err
+	}
+
+	return source.Read(cleanVFSPath(filename), w)
+}
+
+func cleanVFSPath(filename string) string {
+	return strings.TrimPrefix(path.Clean(filepath.ToSlash(filename)), "/")
+}
+
+// validVFSPath reports if the cleaned filename stays within the root.
+func validVFSPath(filename string) bool {
+	return filename != ".." && !strings.HasPrefix(filename, "../")
+}
@@ -0,0 +1,267 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeVFSTestDir(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "vfs")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, data := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(p, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+func readVFS(t *testing.T, v *VFS, filename string) string {
+	buf := &bytes.Buffer{}
+	if err := v.Read(filename, buf); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.String()
+}
+
+func TestVFS_Priority(t *testing.T) {
+	base := writeVFSTestDir(t, map[string]string{"shaders/a.shader": "base", "only-base.txt": "base"})
+	defer os.RemoveAll(base)
+	mod := writeVFSTestDir(t, map[string]string{"shaders/a.shader": "mod"})
+	defer os.RemoveAll(mod)
+	patch := writeVFSTestDir(t, map[string]string{"shaders/a.shader": "patch"})
+	defer os.RemoveAll(patch)
+
+	v := NewVFS()
+	if err := v.Mount(NewDirSource(mod), 10); err != nil {
+		t.Fatal(err)
+	}
+	if err := v.Mount(NewDirSource(base), 0); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := readVFS(t, v, "shaders/a.shader"); got != "mod" {
+		t.Errorf("expected mod, got: %s", got)
+	}
+	if got := readVFS(t, v, "./shaders/../only-base.txt"); got != "base" {
+		t.Errorf("expected base, got: %s", got)
+	}
+
+	// Sources of equal priority are searched in reverse mount order.
+	if err := v.Mount(NewDirSource(patch), 10); err != nil {
+		t.Fatal(err)
+	}
+	if got := readVFS(t, v, "shaders/a.shader"); got != "patch" {
+		t.Errorf("expected patch, got: %s", got)
+	}
+
+	if err := v.Mount(NewDirSource(patch), 20); err == nil {
+		t.Error("expected error for duplicate mount")
+	}
+
+	if _, err := v.Unmount(patch); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := v.Unmount(mod); err != nil {
+		t.Fatal(err)
+	}
+	if got := readVFS(t, v, "shaders/a.shader"); got != "base" {
+		t.Errorf("expected base, got: %s", got)
+	}
+
+	if err := v.Read("missing.txt", ioutil.Discard); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
+
+func TestAsset_ReadResourceVFS(t *testing.T) {
+	a := newHeadlessApp(t)
+	defer a.Teardown()
+
+	dir := writeVFSTestDir(t, map[string]string{"data/value.txt": "mounted"})
+	defer os.RemoveAll(dir)
+
+	if err := GetAsset().MountDir(dir, MountPriorityDefault); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewResource("data/value.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Type() != ResourceVFS {
+		t.Errorf("expected ResourceVFS, got: %d", r.Type())
+	}
+	if err := GetAsset().ReadResource(r); err != nil {
+		t.Fatal(err)
+	}
+	if string(r.Bytes()) != "mounted" {
+		t.Errorf("expected mounted, got: %s", r.Bytes())
+	}
+
+	// Files not found in any source are read from the working directory.
+	r, err = NewResource("vfs_test.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := GetAsset().ReadResource(r); err != nil {
+		t.Fatal(err)
+	}
+	if r.Type() != ResourceFile || !bytes.HasPrefix(r.Bytes(), []byte("/*")) {
+		t.Errorf("expected vfs_test.go read as ResourceFile, got: %d", r.Type())
+	}
+
+	r, err = NewResource("missing.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := GetAsset().ReadResource(r); err == nil {
+		t.Error("expected error for missing file")
+	}
+
+	// Mounted sources shadow the working directory.
+	shadow := writeVFSTestDir(t, map[string]string{"vfs_test.go": "mounted"})
+	defer os.RemoveAll(shadow)
+
+	if err := GetAsset().MountDir(shadow, MountPriorityDefault); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err = NewResource("vfs_test.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := GetAsset().ReadResource(r); err != nil {
+		t.Fatal(err)
+	}
+	if string(r.Bytes()) != "mounted" {
+		t.Errorf("expected mounted, got: %.20s", r.Bytes())
+	}
+}
+
+func TestDirSource_Escape(t *testing.T) {
+	root := writeVFSTestDir(t, map[string]string{"data/inside.txt": "inside", "outside.txt": "outside"})
+	defer os.RemoveAll(root)
+
+	s := NewDirSource(filepath.Join(root, "data"))
+	if !s.Has("inside.txt") || !s.Has("./sub/../inside.txt") {
+		t.Error("expected inside.txt to be found")
+	}
+
+	for _, name := range []string{"../outside.txt", "sub/../../outside.txt", ".."} {
+		if s.Has(name) {
+			t.Errorf("expected %s to be rejected by Has", name)
+		}
+		if err := s.Read(name, ioutil.Discard); err == nil {
+			t.Errorf("expected %s to be rejected by Read", name)
+		}
+	}
+
+	v := NewVFS()
+	if err := v.Mount(s, MountPriorityDefault); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := v.Resolve("../outside.txt"); err == nil {
+		t.Error("expected error resolving path outside of the root")
+	}
+}
+
+func TestAsset_MountPackageFile(t *testing.T) {
+	a := newHeadlessApp(t)
+	defer a.Teardown()
+
+	dir, err := ioutil.TempDir("", "vfs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "level.pkg")
+	f, err := os.Create(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	w, err := zw.Create("data/value.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("packaged")); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	asset := GetAsset()
+	if err := asset.MountPackageFile(filename); err != nil {
+		t.Fatal(err)
+	}
+	if err := asset.MountPackageFile(filename); err == nil {
+		t.Error("expected error for duplicate package mount")
+	}
+
+	// Packages are mounted in the virtual filesystem.
+	if source, err := asset.VFS().Resolve("data/value.txt"); err != nil || source.Name() != "level" {
+		t.Errorf("expected data/value.txt to resolve to level, got: %v %v", source, err)
+	}
+
+	for _, name := range []string{"data/value.txt", "level:data/value.txt"} {
+		r, err := NewResource(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := asset.ReadResource(r); err != nil {
+			t.Fatal(err)
+		}
+		if string(r.Bytes()) != "packaged" {
+			t.Errorf("expected packaged for %s, got: %s", name, r.Bytes())
+		}
+	}
+
+	if err := asset.UnmountPackage("level"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := asset.VFS().Source("level"); ok {
+		t.Error("expected level to be unmounted from the virtual filesystem")
+	}
+	if err := asset.UnmountPackage("level"); err == nil {
+		t.Error("expected error unmounting package twice")
+	}
+}
@@ -35,6 +35,7 @@ import (
 )
 
 var _ System = &Window{}
+var _ Clipboard = &Window{}
 
 const SysNameWindow = "window"
 
@@ -70,6 +71,11 @@ type EventJoy struct {
 	event    int
 }
 
+// WindowResizeEvent is queued as a global event when the window is resized.
+type WindowResizeEvent struct {
+	Size math.IVec2
+}
+
 type DisplayProperties struct {
 	Resolution math.IVec2
 	Mode       DisplayMode
@@ -91,6 +97,7 @@ type Window struct {
 	joystickEvents    []EventJoy
 	aspectRatio       float32
 	title             string
+	clipboard         string
 	vsync             bool
 	focus             bool
 	cursorEnter       bool
@@ -99,11 +106,16 @@ type Window struct {
 	windowResized     bool
 	shouldClose       bool
 	hasEvents         bool
+	null              bool
 }
 
 func (w *Window) Setup() (err error) {
 	var monitor *glfw.Monitor
 
+	if w.null {
+		return w.setupNull()
+	}
+
 	if err := glfw.Init(); err != nil {
 		return err
 	}
@@ -198,8 +210,22 @@ func (w *Window) setupGL() error {
 	return nil
 }
 
+// setupNull sets up a window which has no GLFW window or GL context.
+func (w *Window) setupNull() error {
+	w.resolution = math.ToIVec2(viper.Get("graphics.resolution"))
+	w.SetSize(w.resolution)
+
+	logrus.Debug("[Window] Null window ready")
+
+	return nil
+}
+
 // Teardown tears down the System.
 func (w *Window) Teardown() {
+	if w.null {
+		return
+	}
+
 	glfw.Terminate()
 }
 
@@ -209,6 +235,11 @@ func (w *Window) Name() string {
 }
 
 func (w *Window) EnableVsync(enable bool) {
+	if w.null {
+		w.vsync = enable
+		return
+	}
+
 	if enable {
 		glfw.SwapInterval(1)
 	} else {
@@ -223,6 +254,10 @@ func (w *Window) Vsync() bool {
 }
 
 func (w *Window) CenterWindow() {
+	if w.null {
+		return
+	}
+
 	monitor := w.window.GetMonitor()
 	if monitor == nil {
 		monitor = glfw.GetPrimaryMonitor()
@@ -238,7 +273,9 @@ func (w *Window) CenterWindow() {
 func (w *Window) SetSize(size math.IVec2) {
 	w.resolution = size
 	w.aspectRatio = getRatio(w.resolution)
-	gl.Viewport(0, 0, int32(size.X()), int32(size.Y()))
+	if !w.null {
+		gl.Viewport(0, 0, int32(size.X()), int32(size.Y()))
+	}
 	w.ortho = mgl32.Ortho2D(0, float32(w.resolution.X()), float32(w.resolution.Y()), 0)
 }
 
@@ -253,11 +290,19 @@ func (w *Window) Resolution() math.IVec2 {
 }
 
 func (w *Window) ClearBuffers() {
+	if w.null {
+		return
+	}
+
 	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
 }
 
 // SwapBuffers : Swap front and rear rendering buffers.
 func (w *Window) SwapBuffers() {
+	if w.null {
+		return
+	}
+
 	w.window.SwapBuffers()
 }
 
@@ -273,6 +318,11 @@ func (w *Window) SetDisplayMode(mode DisplayMode) {
 	var monitor *glfw.Monitor
 	var refresh int
 
+	if w.null {
+		w.displayMode = mode
+		return
+	}
+
 	posX, posY := w.window.GetPos()
 	resX := int(w.resolution.X())
 	resY := int(w.resolution.Y())
@@ -307,6 +357,10 @@ func (w *Window) SetDisplayMode(mode DisplayMode) {
 }
 
 func (w *Window) GetVideoModes() {
+	if w.null {
+		return
+	}
+
 	monitors := glfw.GetMonitors()
 	modes := []*glfw.VidMode{}
 
@@ -403,9 +457,42 @@ func (w *Window) WindowResized() bool {
 	return w.windowResized
 }
 
+// ClipboardString returns the text on the system clipboard. A null window
+// has a clipboard of its own.
+func (w *Window) ClipboardString() string {
+	if w.null {
+		return w.clipboard
+	}
+
+	s, err := w.window.GetClipboardString()
+	if err != nil {
+		return ""
+	}
+
+	return s
+}
+
+// SetClipboardString puts text on the system clipboard.
+func (w *Window) SetClipboardString(s string) {
+	if w.null {
+		w.clipboard = s
+		return
+	}
+
+	w.window.SetClipboardString(s)
+}
+
 func (w *Window) HandleEvents() {
 	w.clearEvents()
-	glfw.PollEvents()
+
+	if !w.null {
+		glfw.PollEvents()
+	}
+}
+
+// Null reports if this is a null window, which has no GLFW window or GL context.
+func (w *Window) Null() bool {
+	return w.null
 }
 
 func (w *Window) HasEvents() bool {
@@ -439,6 +526,10 @@ func (w *Window) joystickEvent(joy int, event int) {
 
 func (w *Window) onChar(_ *glfw.Window, char rune) {
 	w.hasEvents = true
+
+	if in := input(); in != nil {
+		in.InjectChar(char)
+	}
 }
 
 func (w *Window) onCursorEnter(_ *glfw.Window, entered bool) {
@@ -451,6 +542,10 @@ func (w *Window) onCursorMove(_ *glfw.Window, xPos float64, yPos float64) {
 	w.cursorPosition[0] = float32(xPos)
 	w.cursorPosition[1] = float32(yPos)
 	w.cursorMoved = true
+
+	if in := input(); in != nil {
+		in.InjectCursor(float32(xPos), float32(yPos))
+	}
 }
 
 func (w *Window) onDrop(_ *glfw.Window, names []string) {
@@ -466,11 +561,19 @@ func (w *Window) onJoystick(joy int, event int) {
 func (w *Window) onKey(_ *glfw.Window, key glfw.Key, scancode int, action glfw.Action, mods glfw.ModifierKey) {
 	w.hasEvents = true
 	w.keyEvent(key, scancode, action, mods)
+
+	if in := input(); in != nil {
+		in.InjectKey(key, action)
+	}
 }
 
 func (w *Window) onMouseButton(_ *glfw.Window, button glfw.MouseButton, action glfw.Action, mod glfw.ModifierKey) {
 	w.hasEvents = true
 	w.mouseButtonEvent(button, action, mod)
+
+	if in := input(); in != nil {
+		in.InjectMouseButton(button, action)
+	}
 }
 
 func (w *Window) onScroll(_ *glfw.Window, xOff float64, yOff float64) {
@@ -478,6 +581,10 @@ func (w *Window) onScroll(_ *glfw.Window, xOff float64, yOff float64) {
 	w.scrollAxis[0] = xOff
 	w.scrollAxis[1] = yOff
 	w.scrollMoved = true
+
+	if in := input(); in != nil {
+		in.InjectScroll(float32(xOff), float32(yOff))
+	}
 }
 
 func (w *Window) onClose(_ *glfw.Window) {
@@ -490,6 +597,10 @@ func (w *Window) onWindowResize(_ *glfw.Window, width int, height int) {
 		w.hasEvents = true
 		w.SetSize(math.IVec2{int32(width), int32(height)})
 		w.windowResized = true
+
+		if e := events(); e != nil {
+			e.Queue(ScopeGlobal(), WindowResizeEvent{Size: w.Resolution()})
+		}
 	}
 }
 
@@ -504,6 +615,15 @@ func NewWindow() *Window {
 	}
 }
 
+// NewNullWindow creates a new window system which never opens a window or
+// creates a GL context. It is used by headless apps.
+func NewNullWindow() *Window {
+	w := NewWindow()
+	w.null = true
+
+	return w
+}
+
 func GetRecommendedVideoMode(monitor *glfw.Monitor) *glfw.VidMode {
 	modes := monitor.GetVideoModes()
 
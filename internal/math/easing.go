@@ -24,7 +24,10 @@ SOFTWARE.
 
 package math
 
-import "math"
+import (
+	"math"
+	"strings"
+)
 
 /* Lerp */
 
@@ -242,3 +245,41 @@ func EaseOutInExp(t float64) float64 {
 
 	return EaseInExp(2.0*t-1.0)/2.0 + 0.5
 }
+
+/* Lookup */
+
+var easings = map[string]func(float64) float64{
+	"None":       EaseNone,
+	"InQuad":     EaseInQuad,
+	"OutQuad":    EaseOutQuad,
+	"InOutQuad":  EaseInOutQuad,
+	"OutInQuad":  EaseOutInQuad,
+	"InCubic":    EaseInCubic,
+	"OutCubic":   EaseOutCubic,
+	"InOutCubic": EaseInOutCubic,
+	"OutInCubic": EaseOutInCubic,
+	"InQuart":    EaseInQuart,
+	"OutQuart":   EaseOutQuart,
+	"InOutQuart": EaseInOutQuart,
+	"OutInQuart": EaseOutInQuart,
+	"InQuint":    EaseInQuint,
+	"OutQuint":   EaseOutQuint,
+	"InOutQuint": EaseInOutQuint,
+	"OutInQuint": EaseOutInQuint,
+	"InSine":     EaseInSine,
+	"OutSine":    EaseOutSine,
+	"InOutSine":  EaseInOutSine,
+	"OutInSine":  EaseOutInSine,
+	"InExp":      EaseInExp,
+	"OutExp":     EaseOutExp,
+	"InOutExp":   EaseInOutExp,
+	"OutInExp":   EaseOutInExp,
+}
+
+// Easing returns the easing function with the name, such as "InOutQuad" or
+// "EaseInOutQuad".
+func Easing(name string) (func(float64) float64, bool) {
+	fn, ok := easings[strings.TrimPrefix(name, "Ease")]
+
+	return fn, ok
+}
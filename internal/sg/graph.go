@@ -33,9 +33,13 @@ type SceneGraphListener interface {
 	OnSceneGraphUpdate()
 }
 
+// Graph is a tree of vertices. Each vertex keeps its parent, and vertices are
+// indexed by the ID of their object, so parent and ID lookups are O(1).
 type Graph struct {
 	vertexList     map[VertexDescriptor]*Vertex
+	ids            map[uint32]VertexDescriptor
 	nextDescriptor VertexDescriptor
+	stack          []VertexDescriptor
 	mutex          *sync.Mutex
 }
 
@@ -44,12 +48,18 @@ type Graph struct {
 func NewGraph() *Graph {
 	g := &Graph{
 		vertexList: make(map[VertexDescriptor]*Vertex),
+		ids:        make(map[uint32]VertexDescriptor),
 		mutex:      &sync.Mutex{},
 	}
 
 	return g
 }
 
+// Len returns the number of vertices in the graph.
+func (g *Graph) Len() int {
+	return len(g.vertexList)
+}
+
 // Vertex Operations
 
 func (g *Graph) VertexExistsWithDescriptor(vert VertexDescriptor) bool {
@@ -59,37 +69,31 @@ func (g *Graph) VertexExistsWithDescriptor(vert VertexDescriptor) bool {
 }
 
 func (g *Graph) VertexExistsWithId(id uint32) bool {
-	for idx := range g.vertexList {
-		if g.vertexList[idx].data.ID() == id {
-			return true
-		}
-	}
+	_, ok := g.ids[id]
 
-	return false
+	return ok
 }
 
+// RemoveVertex removes the vertex and its descendants from the graph.
 func (g *Graph) RemoveVertex(u VertexDescriptor) error {
-	if !g.VertexExistsWithDescriptor(u) {
+	vertex, ok := g.vertexList[u]
+	if !ok {
 		return fmt.Errorf("remove vertex: descriptor %d does not exist", u)
 	}
 
 	// If this vertex has a parent, remove the reference.
-	parent, err := g.Parent(u)
-	if err == nil {
-		g.vertexList[parent].edges = removeVertexDescriptorElement(g.vertexList[parent].edges, u)
+	if parent, ok := g.vertexList[vertex.parent]; ok {
+		parent.edges = removeVertexDescriptorElement(parent.edges, u)
 	}
 
-	// Get list of descendant descriptors.
-	descendants := g.DepthFirstSearch(u, true)
-
-	// Remove descendants references
-	for idx := range descendants {
-		delete(g.vertexList, descendants[idx])
+	// Remove this vertex and its descendants.
+	for _, v := range g.DepthFirstSearch(u, true) {
+		if id := g.vertexList[v].data.ID(); g.ids[id] == v {
+			delete(g.ids, id)
+		}
+		delete(g.vertexList, v)
 	}
 
-	// Remove this vertex
-	delete(g.vertexList, u)
-
 	return nil
 }
 
@@ -99,10 +103,10 @@ func (g *Graph) MoveVertex(vert, parent VertexDescriptor) error {
 	}
 
 	if !g.VertexExistsWithDescriptor(parent) {
-		return fmt.Errorf("move vertex: parent descriptor %d does not exist", vert)
+		return fmt.Errorf("move vertex: parent descriptor %d does not exist", parent)
 	}
 
-	if !g.DescendantOf(parent, vert) {
+	if parent == vert || g.DescendantOf(parent, vert) {
 		return fmt.Errorf("move vertex: parent descriptor %d is a descendant of %d", parent, vert)
 	}
 
@@ -112,18 +116,12 @@ func (g *Graph) MoveVertex(vert, parent VertexDescriptor) error {
 	}
 
 	// Remove existing edge.
-	// TODO: Check this for correctness.
-	for idx := range g.vertexList[oldParent].edges {
-		if g.vertexList[VertexDescriptor(idx)].descriptor == vert {
-			g.vertexList[oldParent].edges = removeVertexDescriptorElement(g.vertexList[oldParent].edges, vert)
-			break
-		}
+	if err := g.RemoveEdge(Edge{oldParent, vert}); err != nil {
+		return err
 	}
 
 	// Add new edge.
-	g.AddEdge(parent, vert)
-
-	return nil
+	return g.AddEdge(parent, vert)
 }
 
 func (g *Graph) AddVertex(object VertexNode) (VertexDescriptor, error) {
@@ -138,6 +136,7 @@ func (g *Graph) AddVertex(object VertexNode) (VertexDescriptor, error) {
 	}
 
 	g.vertexList[v.descriptor] = v
+	g.ids[object.ID()] = v.descriptor
 
 	return v.descriptor, nil
 }
@@ -151,10 +150,8 @@ func (g *Graph) GetVertexByObject(object VertexNode) (VertexDescriptor, error) {
 }
 
 func (g *Graph) GetVertexById(id uint32) (VertexDescriptor, error) {
-	for idx := range g.vertexList {
-		if g.vertexList[idx].data.ID() == id {
-			return g.vertexList[idx].descriptor, nil
-		}
+	if v, ok := g.ids[id]; ok {
+		return v, nil
 	}
 
 	return 0, fmt.Errorf("get vertex: no such vertex with id: %d", id)
@@ -179,8 +176,8 @@ func (g *Graph) NextDescriptor() (VertexDescriptor, error) {
 	id := g.nextDescriptor + 1
 	_, ok := g.vertexList[id]
 
-	for ok {
-		id = g.nextDescriptor + 1
+	for ok || id == 0 {
+		id++
 		_, ok = g.vertexList[id]
 	}
 
@@ -191,12 +188,26 @@ func (g *Graph) NextDescriptor() (VertexDescriptor, error) {
 
 // Edge Operations
 
+// AddEdge adds v as the last child of u. A vertex can only have one parent.
 func (g *Graph) AddEdge(u, v VertexDescriptor) error {
-	if g.DescendantOf(u, v) {
+	parent, ok := g.vertexList[u]
+	if !ok {
+		return fmt.Errorf("add edge: descriptor %d does not exist", u)
+	}
+	child, ok := g.vertexList[v]
+	if !ok {
+		return fmt.Errorf("add edge: descriptor %d does not exist", v)
+	}
+
+	if u == v || g.DescendantOf(u, v) {
 		return fmt.Errorf("add edge: %d is a descendant of %d", u, v)
 	}
+	if child.parent != 0 {
+		return fmt.Errorf("add edge: %d already has parent %d", v, child.parent)
+	}
 
-	g.vertexList[u].edges = append(g.vertexList[u].edges, v)
+	parent.edges = append(parent.edges, v)
+	child.parent = u
 
 	return nil
 }
@@ -206,7 +217,11 @@ func (g *Graph) EdgeExists(e Edge) bool {
 }
 
 func (g *Graph) EdgeExistsUV(u, v VertexDescriptor) bool {
-	return g.vertexDescriptorInOutEdgeList(u, v)
+	if vertex, ok := g.vertexList[v]; ok {
+		return u != 0 && vertex.parent == u
+	}
+
+	return false
 }
 
 func (g *Graph) RemoveEdge(edge Edge) error {
@@ -214,58 +229,45 @@ func (g *Graph) RemoveEdge(edge Edge) error {
 		return fmt.Errorf("remove edge: descriptor %d not found in outEdgeList", edge.U())
 	}
 
-	for idx := range g.vertexList[edge.U()].edges {
-		if g.vertexList[edge.U()].edges[idx] == edge.V() {
-			g.vertexList[edge.U()].edges = deleteVertexDescriptorElement(g.vertexList[edge.U()].edges, idx)
-			return nil
-		}
+	if !g.EdgeExists(edge) {
+		return fmt.Errorf("remove edge: descriptor %v not found in outEdgeList[%d]", edge.V(), edge.U())
 	}
 
-	return fmt.Errorf("remove edge: descriptor %v not found in outEdgeList[%d]", edge.V(), edge.U())
+	g.vertexList[edge.U()].edges = removeVertexDescriptorElement(g.vertexList[edge.U()].edges, edge.V())
+	g.vertexList[edge.V()].parent = 0
+
+	return nil
 }
 
 // Utility Functions
 
+// ParentOf reports if parent is the parent of descendant.
 func (g *Graph) ParentOf(parent, descendant VertexDescriptor) bool {
-	if g.VertexExistsWithDescriptor(parent) {
-		return false
-	}
-	if g.VertexExistsWithDescriptor(descendant) {
-		return false
-	}
-
-	return g.vertexDescriptorInOutEdgeList(parent, descendant)
+	return g.EdgeExistsUV(parent, descendant)
 }
 
+// DescendantOf reports if descendant is below parent in the graph.
 func (g *Graph) DescendantOf(descendant, parent VertexDescriptor) bool {
-	descendants := g.DepthFirstSearch(parent, true)
+	if !g.VertexExistsWithDescriptor(parent) {
+		return false
+	}
 
-	for idx := range descendants {
-		if descendants[idx] == descendant {
+	vertex, ok := g.vertexList[descendant]
+	for ok && vertex.parent != 0 {
+		if vertex.parent == parent {
 			return true
 		}
+
+		vertex, ok = g.vertexList[vertex.parent]
 	}
 
 	return false
 }
 
 func (g *Graph) Parent(vertex VertexDescriptor) (VertexDescriptor, error) {
-	if vertex != 0 {
-		for edge := range g.vertexList {
-			if edge == vertex {
-				continue
-			}
-
-			if g.vertexDescriptorInOutEdgeList(edge, vertex) {
-				return edge, nil
-			}
-		}
-	}
-
 	v := g.getParent(vertex)
 	if v == 0 {
 		return 0, fmt.Errorf("parent: vertex %d has no parent", vertex)
-
 	}
 
 	return v, nil
@@ -273,58 +275,88 @@ func (g *Graph) Parent(vertex VertexDescriptor) (VertexDescriptor, error) {
 
 // Search Functions
 
+// DepthFirstSearch returns u and its descendants in pre-order. Unless
+// includeDisabled is set, inactive vertices and their descendants are skipped.
 func (g *Graph) DepthFirstSearch(u VertexDescriptor, includeDisabled bool) []VertexDescriptor {
-	nodeList := make([]VertexDescriptor, 0)
+	return g.AppendDepthFirstSearch(make([]VertexDescriptor, 0), u, includeDisabled)
+}
 
+// AppendDepthFirstSearch is like DepthFirstSearch, but appends the result to
+// nodeList, so a buffer can be reused between searches.
+func (g *Graph) AppendDepthFirstSearch(nodeList []VertexDescriptor, u VertexDescriptor, includeDisabled bool) []VertexDescriptor {
 	if !g.VertexExistsWithDescriptor(u) {
 		return nodeList
 	}
 
-	if !includeDisabled {
-		if !g.GetObjectAtVertex(u).Active() {
-			return nodeList
+	stack := append(g.takeStack(), u)
+
+	for len(stack) > 0 {
+		v := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		vertex := g.vertexList[v]
+		if !includeDisabled && !vertex.data.Active() {
+			continue
 		}
-	}
 
-	nodeList = append(nodeList, u)
+		nodeList = append(nodeList, v)
 
-	for idx := range g.vertexList[u].edges {
-		newList := g.DepthFirstSearch(g.vertexList[u].edges[idx], includeDisabled)
-		nodeList = append(nodeList, newList...)
+		// Push children in reverse, so they are visited in order.
+		for i := len(vertex.edges) - 1; i >= 0; i-- {
+			stack = append(stack, vertex.edges[i])
+		}
 	}
 
+	g.stack = stack
+
 	return nodeList
 }
 
+// BreadthFirstSearch returns u, followed by the children of each vertex
+// expanded in pre-order. Unless includeDisabled is set, the children of
+// inactive vertices are skipped, though inactive vertices are listed.
 func (g *Graph) BreadthFirstSearch(u VertexDescriptor, includeDisabled bool) []VertexDescriptor {
-	nodeList := []VertexDescriptor{u}
-
-	return append(nodeList, g.bFS(u, includeDisabled)...)
+	return g.AppendBreadthFirstSearch(make([]VertexDescriptor, 0), u, includeDisabled)
 }
 
-func (g *Graph) bFS(u VertexDescriptor, includeDisabled bool) []VertexDescriptor {
-	nodeList := []VertexDescriptor{}
+// AppendBreadthFirstSearch is like BreadthFirstSearch, but appends the result
+// to nodeList, so a buffer can be reused between searches.
+func (g *Graph) AppendBreadthFirstSearch(nodeList []VertexDescriptor, u VertexDescriptor, includeDisabled bool) []VertexDescriptor {
+	nodeList = append(nodeList, u)
 
-	if !g.VertexExistsWithDescriptor(u) {
-		return nodeList
-	}
+	stack := append(g.takeStack(), u)
+
+	for len(stack) > 0 {
+		v := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
 
-	if !includeDisabled {
-		if !g.GetObjectAtVertex(u).Active() {
-			return nodeList
+		vertex, ok := g.vertexList[v]
+		if !ok || (!includeDisabled && !vertex.data.Active()) {
+			continue
 		}
-	}
 
-	nodeList = append(nodeList, g.vertexList[u].edges...)
+		nodeList = append(nodeList, vertex.edges...)
 
-	for idx := range g.vertexList[u].edges {
-		newList := g.bFS(g.vertexList[u].edges[idx], includeDisabled)
-		nodeList = append(nodeList, newList...)
+		for i := len(vertex.edges) - 1; i >= 0; i-- {
+			stack = append(stack, vertex.edges[i])
+		}
 	}
 
+	g.stack = stack
+
 	return nodeList
 }
 
+// takeStack returns the search stack buffer, emptied. The buffer is detached
+// from the graph until the search returns it, so a search started while
+// another is running, such as from Active, does not overwrite its stack.
+func (g *Graph) takeStack() []VertexDescriptor {
+	stack := g.stack[:0]
+	g.stack = nil
+
+	return stack
+}
+
 func (g *Graph) ChildrenOf(u VertexDescriptor) []VertexDescriptor {
 	if _, ok := g.vertexList[u]; !ok {
 		return make([]VertexDescriptor, 0)
@@ -350,42 +382,12 @@ func deleteVertexDescriptorElement(s []VertexDescriptor, idx int) []VertexDescri
 		return s
 	}
 
-	if idx != len(s)-1 {
-		s[idx] = s[len(s)-1]
-	}
-
-	return s[:len(s)-1]
-}
-
-func (g *Graph) vertexDescriptorInOutEdgeList(u, v VertexDescriptor) bool {
-	if _, ok := g.vertexList[u]; !ok {
-		return false
-	}
-
-	for idx := range g.vertexList[u].edges {
-		if g.vertexList[u].edges[idx] == v {
-			return true
-		}
-	}
-
-	return false
+	return append(s[:idx], s[idx+1:]...)
 }
 
 func (g *Graph) getParent(u VertexDescriptor) VertexDescriptor {
-	if _, ok := g.vertexList[u]; !ok {
-		return 0
-	}
-
-	for i := range g.vertexList {
-		if i == u {
-			continue
-		}
-
-		for j := range g.vertexList[i].edges {
-			if g.vertexList[i].edges[j] == u {
-				return g.vertexList[i].descriptor
-			}
-		}
+	if vertex, ok := g.vertexList[u]; ok {
+		return vertex.parent
 	}
 
 	return 0
@@ -241,6 +241,126 @@ func TestGraph_AddEdge(t *testing.T) {
 	}
 }
 
+func TestGraph_MoveVertex(t *testing.T) {
+	g := NewGraph()
+
+	// 1 -> {2, 3}, 2 -> 4
+	var d [5]VertexDescriptor
+	for i := 1; i <= 4; i++ {
+		var err error
+		if d[i], err = g.AddVertex(newObject(uint32(i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, e := range []Edge{{d[1], d[2]}, {d[1], d[3]}, {d[2], d[4]}} {
+		if err := g.AddEdge(e.U(), e.V()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Moving a vertex under itself or a descendant must fail.
+	if err := g.MoveVertex(d[2], d[4]); err == nil {
+		t.Error("g.MoveVertex(2, 4) expected error")
+	}
+	if err := g.MoveVertex(d[2], d[2]); err == nil {
+		t.Error("g.MoveVertex(2, 2) expected error")
+	}
+
+	if err := g.MoveVertex(d[4], d[3]); err != nil {
+		t.Fatal(err)
+	}
+
+	if g.EdgeExistsUV(d[2], d[4]) {
+		t.Error("edge (2, 4) expected removed")
+	}
+	if !g.EdgeExistsUV(d[3], d[4]) {
+		t.Error("edge (3, 4) expected")
+	}
+	if p, err := g.Parent(d[4]); err != nil || p != d[3] {
+		t.Errorf("g.Parent(4) expected %d, got: %d (%v)", d[3], p, err)
+	}
+
+	// Moving keeps the order of the remaining children.
+	if err := g.MoveVertex(d[2], d[3]); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []VertexDescriptor{d[1], d[3], d[4], d[2]}
+	if dfs := g.DepthFirstSearch(d[1], true); !reflect.DeepEqual(dfs, expected) {
+		t.Errorf("g.DepthFirstSearch(1) expected %v, got: %v", expected, dfs)
+	}
+}
+
+func TestGraph_Index(t *testing.T) {
+	g := NewGraph()
+	root := buildTree(g, 21)
+
+	// Vertex 6 is the first child of vertex 2, whose children are 6 to 9.
+	v6, err := g.GetVertexById(6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v2, _ := g.GetVertexById(2)
+
+	if p, err := g.Parent(v6); err != nil || p != v2 {
+		t.Errorf("g.Parent(6) expected %d, got: %d (%v)", v2, p, err)
+	}
+	if !g.ParentOf(v2, v6) {
+		t.Error("g.ParentOf(2, 6) expected true")
+	}
+	if !g.DescendantOf(v6, root) || g.DescendantOf(root, v6) {
+		t.Error("6 expected to be a descendant of the root only")
+	}
+	if _, err := g.Parent(root); err == nil {
+		t.Error("g.Parent(root) expected error")
+	}
+
+	// Removing a vertex removes its subtree from the index.
+	if err := g.RemoveVertex(v2); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []uint32{2, 6, 9} {
+		if g.VertexExistsWithId(id) {
+			t.Errorf("g.VertexExistsWithId(%d) expected false", id)
+		}
+	}
+	if !g.VertexExistsWithId(21) {
+		t.Error("g.VertexExistsWithId(21) expected true")
+	}
+	if c := g.Len(); c != 16 {
+		t.Error("g.Len() expected 16, got:", c)
+	}
+}
+
+func TestGraph_SearchDisabled(t *testing.T) {
+	g := NewGraph()
+	root := buildTree(g, 7)
+
+	// Disabling vertex 2 skips it and its children 6 and 7.
+	v2, _ := g.GetVertexById(2)
+	g.GetObjectAtVertex(v2).(*Object).active = false
+
+	expected := []VertexDescriptor{1, 3, 4, 5}
+	if dfs := g.DepthFirstSearch(root, false); !reflect.DeepEqual(dfs, expected) {
+		t.Errorf("g.DepthFirstSearch() expected %v, got: %v", expected, dfs)
+	}
+
+	// Breadth-first searches list inactive vertices, but not their children.
+	expected = []VertexDescriptor{1, 2, 3, 4, 5}
+	if bfs := g.BreadthFirstSearch(root, false); !reflect.DeepEqual(bfs, expected) {
+		t.Errorf("g.BreadthFirstSearch() expected %v, got: %v", expected, bfs)
+	}
+	expected = []VertexDescriptor{2}
+	if bfs := g.BreadthFirstSearch(v2, false); !reflect.DeepEqual(bfs, expected) {
+		t.Errorf("g.BreadthFirstSearch(2) expected %v, got: %v", expected, bfs)
+	}
+
+	expected = []VertexDescriptor{1, 2, 6, 7, 3, 4, 5}
+	if dfs := g.DepthFirstSearch(root, true); !reflect.DeepEqual(dfs, expected) {
+		t.Errorf("g.DepthFirstSearch() expected %v, got: %v", expected, dfs)
+	}
+}
+
 func TestGraph_DepthFirstSearch(t *testing.T) {
 	g := NewGraph()
 
@@ -374,3 +494,155 @@ func TestGraph_BreadthFirstSearch(t *testing.T) {
 		t.Error("DepthFirstSearch result does not equal expected result")
 	}
 }
+
+// searchObject is an object which runs a search of its graph when its state
+// is checked, like a listener which searches during an update.
+type searchObject struct {
+	Object
+
+	graph  *Graph
+	vertex VertexDescriptor
+	found  []VertexDescriptor
+}
+
+func (o *searchObject) Active() bool {
+	if o.found == nil {
+		o.found = o.graph.DepthFirstSearch(o.vertex, true)
+	}
+
+	return true
+}
+
+func TestGraph_NestedSearch(t *testing.T) {
+	g := NewGraph()
+	root := buildTree(g, 7)
+
+	v2, _ := g.GetVertexById(2)
+	v3, _ := g.GetVertexById(3)
+
+	// The first search allocates the stack buffer, which later searches reuse.
+	g.DepthFirstSearch(root, false)
+
+	// Vertex 8 searches the subtree of vertex 2 while a search visits it.
+	o := &searchObject{Object: Object{active: true, instanceId: 8}, graph: g, vertex: v2}
+	v8, err := g.GetVertexByObject(o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.AddEdge(v3, v8); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []VertexDescriptor{1, 2, 6, 7, 3, 8, 4, 5}
+	if dfs := g.DepthFirstSearch(root, false); !reflect.DeepEqual(dfs, expected) {
+		t.Errorf("g.DepthFirstSearch() expected %v, got: %v", expected, dfs)
+	}
+	if expected := []VertexDescriptor{2, 6, 7}; !reflect.DeepEqual(o.found, expected) {
+		t.Errorf("nested search expected %v, got: %v", expected, o.found)
+	}
+}
+
+// buildTree adds n vertices to the graph as a tree where each vertex has up to
+// four children, and returns the root.
+func buildTree(g *Graph, n int) VertexDescriptor {
+	descriptors := make([]VertexDescriptor, n)
+
+	for i := 0; i < n; i++ {
+		v, err := g.GetVertexByObject(newObject(uint32(i + 1)))
+		if err != nil {
+			panic(err)
+		}
+		descriptors[i] = v
+
+		if i > 0 {
+			parent, err := g.GetVertexById(uint32((i-1)/4 + 1))
+			if err != nil {
+				panic(err)
+			}
+			if err := g.AddEdge(parent, v); err != nil {
+				panic(err)
+			}
+		}
+	}
+
+	return descriptors[0]
+}
+
+// treeSizes are the graph sizes benchmarked. The reported ns/vertex stays
+// constant across sizes when an operation is linear in the size of the graph.
+var treeSizes = []int{1000, 10000, 100000}
+
+func reportPerVertex(b *testing.B, n int) {
+	b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*n), "ns/vertex")
+}
+
+func BenchmarkGraph_Build(b *testing.B) {
+	for _, n := range treeSizes {
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				buildTree(NewGraph(), n)
+			}
+
+			reportPerVertex(b, n)
+		})
+	}
+}
+
+func BenchmarkGraph_DepthFirstSearch(b *testing.B) {
+	for _, n := range treeSizes {
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			g := NewGraph()
+			root := buildTree(g, n)
+
+			var buf []VertexDescriptor
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				buf = g.AppendDepthFirstSearch(buf[:0], root, false)
+			}
+
+			reportPerVertex(b, n)
+		})
+	}
+}
+
+func BenchmarkGraph_BreadthFirstSearch(b *testing.B) {
+	for _, n := range treeSizes {
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			g := NewGraph()
+			root := buildTree(g, n)
+
+			var buf []VertexDescriptor
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				buf = g.AppendBreadthFirstSearch(buf[:0], root, false)
+			}
+
+			reportPerVertex(b, n)
+		})
+	}
+}
+
+// BenchmarkGraph_Ancestors walks from every vertex to the root, as a scene
+// update does when recomputing transforms.
+func BenchmarkGraph_Ancestors(b *testing.B) {
+	for _, n := range treeSizes {
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			g := NewGraph()
+			root := buildTree(g, n)
+			dfs := g.DepthFirstSearch(root, true)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				for _, v := range dfs {
+					if _, err := g.Parent(v); err != nil && v != root {
+						b.Fatal(err)
+					}
+				}
+			}
+
+			reportPerVertex(b, n)
+		})
+	}
+}
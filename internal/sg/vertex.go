@@ -28,6 +28,7 @@ type Vertex struct {
 	edges      []VertexDescriptor
 	data       VertexNode
 	descriptor VertexDescriptor
+	parent     VertexDescriptor // parent is zero if the vertex has no parent.
 }
 
 type VertexNode interface {